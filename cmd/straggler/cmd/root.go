@@ -49,6 +49,16 @@ func init() {
 }
 
 func SetupTelemetryAndLogging() logr.Logger {
+	logger := SetupLogging()
+	setupPProf(logger, PProfListenAddress)
+	setupMetrics(logger, MetricsListenAddress)
+
+	buildInfo.WithLabelValues(version.Build).Set(1)
+
+	return logger
+}
+
+func SetupLogging() logr.Logger {
 	var zlogConfig zap.Config
 	if ProductionStyleLogging {
 		zlogConfig = zap.NewProductionConfig()
@@ -59,13 +69,7 @@ func SetupTelemetryAndLogging() logr.Logger {
 	// zlog's log levels are -1*(logr log levels). Ref: https://pkg.go.dev/github.com/go-logr/zapr#hdr-Implementation_Details
 	zlogConfig.Level = zap.NewAtomicLevelAt(zapcore.Level(LogVerbosity * -1))
 	zlog, _ := zlogConfig.Build()
-	logger := zapr.NewLogger(zlog)
-	setupPProf(logger, PProfListenAddress)
-	setupMetrics(logger, MetricsListenAddress)
-
-	buildInfo.WithLabelValues(version.Build).Set(1)
-
-	return logger
+	return zapr.NewLogger(zlog)
 }
 
 func setupPProf(logger logr.Logger, pprofListenAddress string) {
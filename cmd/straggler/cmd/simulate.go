@@ -0,0 +1,39 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package cmd
+
+import (
+	"os"
+	"straggler/pkg/cmd"
+
+	"github.com/spf13/cobra"
+)
+
+var simulateCMD = &cobra.Command{
+	Use:   "simulate",
+	Short: "simulate staggering of a synthetic workload against policies offline",
+	Run:   runSimulate,
+}
+
+var (
+	simulateOptions = cmd.NewSimulateOptions()
+)
+
+func init() {
+	EnrichCommand(simulateCMD, &simulateOptions)
+	RootCMD.AddCommand(simulateCMD)
+}
+
+func runSimulate(command *cobra.Command, args []string) {
+	logger := SetupLogging()
+
+	result, err := cmd.Simulate(simulateOptions, logger)
+	if err != nil {
+		logger.Info("failed to simulate", "error", err)
+		os.Exit(1)
+	}
+	if err := result.Print(os.Stdout); err != nil {
+		logger.Info("failed to print result", "error", err)
+		os.Exit(1)
+	}
+}
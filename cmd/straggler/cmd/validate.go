@@ -0,0 +1,47 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"straggler/pkg/cmd"
+
+	"github.com/spf13/cobra"
+)
+
+var validateCMD = &cobra.Command{
+	Use:   "validate [policies file]...",
+	Short: "validate staggering policies files",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   runValidate,
+}
+
+func init() {
+	EnrichCommand(validateCMD, nil)
+	RootCMD.AddCommand(validateCMD)
+}
+
+func runValidate(command *cobra.Command, args []string) {
+	logger := SetupLogging()
+
+	valid := true
+	for _, path := range args {
+		errs, err := cmd.ValidateConfigFile(path, logger.V(1))
+		if err != nil {
+			fmt.Printf("%s: failed to load: %v\n", path, err)
+			valid = false
+			continue
+		}
+		for _, err := range errs {
+			fmt.Printf("%s: %v\n", path, err)
+		}
+		if len(errs) > 0 {
+			valid = false
+		}
+	}
+
+	if !valid {
+		os.Exit(1)
+	}
+}
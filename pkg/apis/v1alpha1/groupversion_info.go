@@ -0,0 +1,25 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+// Package v1alpha1 contains API types for straggler staggering policies.
+// +kubebuilder:object:generate=true
+// +groupName=straggler.technicianted
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+//go:generate controller-gen object crd:allowDangerousTypes=true paths=./... output:crd:dir=../../../helm/straggler/crds
+
+var (
+	// GroupVersion is group version used to register these objects.
+	GroupVersion = schema.GroupVersion{Group: "straggler.technicianted", Version: "v1alpha1"}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme.
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
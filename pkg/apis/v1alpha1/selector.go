@@ -0,0 +1,62 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LabelSelector accepts either a map of labels to match, or a full label
+// selector with matchLabels and matchExpressions.
+// +kubebuilder:validation:Type=object
+// +kubebuilder:pruning:PreserveUnknownFields
+type LabelSelector struct {
+	metav1.LabelSelector `json:",inline"`
+}
+
+// Create a label selector that matches set.
+func LabelSelectorFromSet(set map[string]string) *LabelSelector {
+	return &LabelSelector{
+		LabelSelector: metav1.LabelSelector{
+			MatchLabels: set,
+		},
+	}
+}
+
+func (s *LabelSelector) UnmarshalJSON(data []byte) error {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("label selector must be an object: %v", err)
+	}
+
+	full := len(fields) > 0
+	for key := range fields {
+		if key != "matchLabels" && key != "matchExpressions" {
+			full = false
+		}
+	}
+	if full {
+		return json.Unmarshal(data, &s.LabelSelector)
+	}
+
+	set := make(map[string]string)
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("label selector must be a map of labels, or have matchLabels and matchExpressions: %v", err)
+	}
+	s.LabelSelector = metav1.LabelSelector{
+		MatchLabels: set,
+	}
+
+	return nil
+}
+
+// Get the underlying label selector, or nil if s is nil.
+func (s *LabelSelector) AsLabelSelector() *metav1.LabelSelector {
+	if s == nil {
+		return nil
+	}
+	return &s.LabelSelector
+}
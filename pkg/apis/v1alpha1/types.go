@@ -0,0 +1,268 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// Condition type set on policies to indicate if they were applied.
+	PolicyConditionAccepted = "Accepted"
+
+	// Reasons for the accepted condition.
+	PolicyReasonAccepted = "Accepted"
+	PolicyReasonInvalid  = "Invalid"
+)
+
+type ExponentialPacer struct {
+	// Minimum number of pods to initially allow.
+	MinInitial *int `json:"minInitial,omitempty"`
+	// Maximum number of staggered pods after which it's disabled.
+	MaxStagger *int `json:"maxStagger,omitempty"`
+	// Exponential staggering multiplier.
+	Multiplier *float64 `json:"multiplier,omitempty"`
+}
+
+type LinearPacer struct {
+	// Maximum number of staggered pods after which it's disabled.
+	MaxStagger *int `json:"maxStagger,omitempty"`
+	// Number of pods to add at each step.
+	Step *int `json:"step,omitempty"`
+}
+
+type RatePacer struct {
+	// Number of pods allowed to start per period.
+	Rate *int `json:"rate,omitempty"`
+	// Period over which rate applies. Default 1m.
+	Period *metav1.Duration `json:"period,omitempty"`
+	// Maximum number of pods allowed to start at once. Default 1.
+	Burst *int `json:"burst,omitempty"`
+}
+
+type ConcurrencyPacer struct {
+	// Maximum number of pods starting at any time.
+	MaxStarting *int `json:"maxStarting,omitempty"`
+}
+
+type AdaptivePacer struct {
+	// Startup latency under which more pods are allowed to start at once.
+	TargetLatency *metav1.Duration `json:"targetLatency,omitempty"`
+	// Initial number of pods allowed to be starting at once. Default 1.
+	InitialWindow *int `json:"initialWindow,omitempty"`
+	// Minimum number of pods allowed to be starting at once. Default 1.
+	MinWindow *int `json:"minWindow,omitempty"`
+	// Maximum number of pods allowed to be starting at once.
+	MaxWindow *int `json:"maxWindow,omitempty"`
+	// Number of pods added to the window for each pod started within
+	// target latency. Default 1.
+	Increase *float64 `json:"increase,omitempty"`
+	// Multiplier applied to the window when startup latency exceeds target.
+	// Default 0.5.
+	DecreaseFactor *float64 `json:"decreaseFactor,omitempty"`
+}
+
+// Pacers that can be used within schedule windows. If more than one pacer is
+// set then pods are allowed only if allowed by all of them.
+type BasePacer struct {
+	Exponential *ExponentialPacer `json:"exponential,omitempty"`
+	Linear      *LinearPacer      `json:"linear,omitempty"`
+	Rate        *RatePacer        `json:"rate,omitempty"`
+	Concurrency *ConcurrencyPacer `json:"concurrency,omitempty"`
+	Adaptive    *AdaptivePacer    `json:"adaptive,omitempty"`
+}
+
+type ScheduleWindow struct {
+	// Days of week this window applies to, for example Mon or Monday.
+	// Empty for all days.
+	Days []string `json:"days,omitempty"`
+	// Start time of day in HH:MM format.
+	Start string `json:"start"`
+	// End time of day in HH:MM format. If not after start then the window
+	// ends on the next day.
+	End string `json:"end"`
+	// Pacer used during this window.
+	Pacer BasePacer `json:"pacer"`
+}
+
+type SchedulePacer struct {
+	// IANA time zone name of windows. Default UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+	// Time windows, first matching window is used.
+	Windows []ScheduleWindow `json:"windows"`
+	// Pacer used outside of all windows. Default no pacing by schedule.
+	Default *BasePacer `json:"default,omitempty"`
+}
+
+// Pacer configuration. If more than one pacer is set then pods are allowed
+// only if allowed by all of them.
+type Pacer struct {
+	BasePacer `json:",inline"`
+	// Select pacers based on time windows. Other pacers set along with
+	// schedule apply both in and out of windows.
+	Schedule *SchedulePacer `json:"schedule,omitempty"`
+}
+
+// Pause releasing pods of a group when released pods are failing, such as
+// crash looping or failing to pull images.
+type CircuitBreaker struct {
+	// Number of failing pods at which a group is paused.
+	FailureThreshold *int `json:"failureThreshold,omitempty"`
+	// Ratio of failing to released pods at which a group is paused.
+	FailureRatio *float64 `json:"failureRatio,omitempty"`
+	// Duration after which a paused group is resumed. Default none, in which
+	// case a group is resumed by setting v1.straggler.technicianted/resume
+	// annotation on any of its pods to a new value.
+	Cooldown *metav1.Duration `json:"cooldown,omitempty"`
+}
+
+// Definition of a ready pod for pacing. Default Ready condition.
+type Readiness struct {
+	// Pod condition type that must be true instead of Ready, such as a
+	// readiness gate.
+	ConditionType string `json:"conditionType,omitempty"`
+	// Consider pods ready once all their containers are running instead of
+	// a condition.
+	ContainersRunning bool `json:"containersRunning,omitempty"`
+	// Minimum number of seconds pods must be ready for.
+	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
+	// Consider succeeded pods ready, such as completed Job pods.
+	Succeeded bool `json:"succeeded,omitempty"`
+}
+
+// Order in which blocked pods are released.
+type Ordering struct {
+	// Ordering strategy. fifo releases earlier pods first, priority releases
+	// pods with higher priority first. Pod priority is taken from the
+	// v1.straggler.technicianted/priority annotation or its spec priority
+	// otherwise. Default fifo.
+	// +kubebuilder:validation:Enum=fifo;priority
+	Strategy string `json:"strategy,omitempty"`
+	// Weights added to priorities of pods by namespace.
+	NamespaceWeights map[string]int32 `json:"namespaceWeights,omitempty"`
+	// Share released pods fairly across namespaces or owners. Default none.
+	Fairness *Fairness `json:"fairness,omitempty"`
+	// Release pods of StatefulSets at or above their rolling update
+	// partition first, in descending ordinal order, then the rest. Pods of
+	// the same StatefulSet are always released in ordinal order.
+	RespectStatefulSetPartition bool `json:"respectStatefulSetPartition,omitempty"`
+}
+
+// Fair share of released pods in a group. Pods sharing the same key retain
+// their ordering.
+type Fairness struct {
+	// Fairness mode. round-robin takes pods from each key in turns, drf takes
+	// pods from the key with the lowest weighted share of ready and starting
+	// pods.
+	// +kubebuilder:validation:Enum=round-robin;drf
+	Mode string `json:"mode"`
+	// Fairness key. Default namespace.
+	// +kubebuilder:validation:Enum=namespace;owner
+	By string `json:"by,omitempty"`
+	// Weights of keys for drf. Keys are namespaces, or namespace/kind/name
+	// of owners. Default 1.
+	Weights map[string]int32 `json:"weights,omitempty"`
+}
+
+// StaggeringPolicySpec defines matching pods, a grouping key and a pacer.
+type StaggeringPolicySpec struct {
+	// Selector of pods to apply this staggering policy. Either a map of
+	// labels or a label selector with matchLabels and matchExpressions.
+	LabelSelector *LabelSelector `json:"labelSelector,omitempty"`
+	// Selector of pods to bypass staggering.
+	BypassLabelSelector *LabelSelector `json:"bypassLabelSelector,omitempty"`
+	// Selector of namespaces, by their labels, to apply this staggering
+	// policy.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Namespaces to apply this staggering policy. Default all.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Namespaces to exclude from this staggering policy.
+	ExcludedNamespaces []string `json:"excludedNamespaces,omitempty"`
+	// Jsonpath expression evaluated against pods to get grouping keys. Each
+	// result is a separate key.
+	GroupingExpression string `json:"groupingExpression,omitempty"`
+	// CEL expression evaluated against pods, as variable pod, to get grouping
+	// keys. It must return a string or a list of strings. Exactly one of
+	// groupingExpression or groupingCELExpression must be set.
+	GroupingCELExpression string `json:"groupingCELExpression,omitempty"`
+	// Maximum time to keep a pod in blocked state. Default none.
+	MaxBlockedDuration metav1.Duration `json:"maxBlockedDuration,omitempty"`
+	// Pacer used to pace pods in each group.
+	Pacer Pacer `json:"pacer"`
+	// Order in which blocked pods are released by the pacer.
+	Ordering *Ordering `json:"ordering,omitempty"`
+	// Pause groups with failing pods.
+	CircuitBreaker *CircuitBreaker `json:"circuitBreaker,omitempty"`
+	// Definition of ready pods for pacing.
+	Readiness *Readiness `json:"readiness,omitempty"`
+	// Strategy used to release blocked pods. Default selected by the
+	// configured blocker.
+	// +kubebuilder:validation:Enum=evict;delete;patch-remove-gate;patch-annotation
+	Unblocker string `json:"unblocker,omitempty"`
+}
+
+type StaggeringPolicyStatus struct {
+	// Generation of the policy that was last processed.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions of the policy.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// StaggeringPolicy is a namespaced policy that only applies to pods
+// in its own namespace.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=sp
+// +kubebuilder:printcolumn:name="Expression",type=string,JSONPath=`.spec.groupingExpression`
+// +kubebuilder:printcolumn:name="Accepted",type=string,JSONPath=`.status.conditions[?(@.type=="Accepted")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type StaggeringPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StaggeringPolicySpec   `json:"spec,omitempty"`
+	Status StaggeringPolicyStatus `json:"status,omitempty"`
+}
+
+// StaggeringPolicyList contains a list of StaggeringPolicy.
+// +kubebuilder:object:root=true
+type StaggeringPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StaggeringPolicy `json:"items"`
+}
+
+// ClusterStaggeringPolicy is a cluster scoped policy that applies to pods
+// in all namespaces.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=csp
+// +kubebuilder:printcolumn:name="Expression",type=string,JSONPath=`.spec.groupingExpression`
+// +kubebuilder:printcolumn:name="Accepted",type=string,JSONPath=`.status.conditions[?(@.type=="Accepted")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type ClusterStaggeringPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StaggeringPolicySpec   `json:"spec,omitempty"`
+	Status StaggeringPolicyStatus `json:"status,omitempty"`
+}
+
+// ClusterStaggeringPolicyList contains a list of ClusterStaggeringPolicy.
+// +kubebuilder:object:root=true
+type ClusterStaggeringPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterStaggeringPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&StaggeringPolicy{},
+		&StaggeringPolicyList{},
+		&ClusterStaggeringPolicy{},
+		&ClusterStaggeringPolicyList{},
+	)
+}
@@ -0,0 +1,8 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package blocker
+
+var (
+	// Label of pods that are blocked until released.
+	DefaultStaggeredPodLabel = "v1.straggler.technicianted/staggered"
+)
@@ -41,17 +41,17 @@ func (m *MockPodBlocker) EXPECT() *MockPodBlockerMockRecorder {
 }
 
 // Block mocks base method.
-func (m *MockPodBlocker) Block(podSpec *v1.PodSpec, logger logr.Logger) error {
+func (m *MockPodBlocker) Block(pod *v1.Pod, logger logr.Logger) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Block", podSpec, logger)
+	ret := m.ctrl.Call(m, "Block", pod, logger)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Block indicates an expected call of Block.
-func (mr *MockPodBlockerMockRecorder) Block(podSpec, logger any) *gomock.Call {
+func (mr *MockPodBlockerMockRecorder) Block(pod, logger any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Block", reflect.TypeOf((*MockPodBlocker)(nil).Block), podSpec, logger)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Block", reflect.TypeOf((*MockPodBlocker)(nil).Block), pod, logger)
 }
 
 // IsBlocked mocks base method.
@@ -68,16 +68,30 @@ func (mr *MockPodBlockerMockRecorder) IsBlocked(podSpec any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBlocked", reflect.TypeOf((*MockPodBlocker)(nil).IsBlocked), podSpec)
 }
 
+// Preserve mocks base method.
+func (m *MockPodBlocker) Preserve(podSpec, oldPodSpec *v1.PodSpec, logger logr.Logger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preserve", podSpec, oldPodSpec, logger)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Preserve indicates an expected call of Preserve.
+func (mr *MockPodBlockerMockRecorder) Preserve(podSpec, oldPodSpec, logger any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preserve", reflect.TypeOf((*MockPodBlocker)(nil).Preserve), podSpec, oldPodSpec, logger)
+}
+
 // Unblock mocks base method.
-func (m *MockPodBlocker) Unblock(podSpec *v1.PodSpec, logger logr.Logger) error {
+func (m *MockPodBlocker) Unblock(pod *v1.Pod, logger logr.Logger) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Unblock", podSpec, logger)
+	ret := m.ctrl.Call(m, "Unblock", pod, logger)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Unblock indicates an expected call of Unblock.
-func (mr *MockPodBlockerMockRecorder) Unblock(podSpec, logger any) *gomock.Call {
+func (mr *MockPodBlockerMockRecorder) Unblock(pod, logger any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unblock", reflect.TypeOf((*MockPodBlocker)(nil).Unblock), podSpec, logger)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unblock", reflect.TypeOf((*MockPodBlocker)(nil).Unblock), pod, logger)
 }
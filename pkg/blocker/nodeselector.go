@@ -30,7 +30,11 @@ func NewNodeSelectorPodBlocker() *NodeSelectorPodBlocker {
 	}
 }
 
-func (b *NodeSelectorPodBlocker) Block(podSpec *corev1.PodSpec, logger logr.Logger) error {
+func (b *NodeSelectorPodBlocker) Block(pod *corev1.Pod, logger logr.Logger) error {
+	return b.block(&pod.Spec)
+}
+
+func (b *NodeSelectorPodBlocker) block(podSpec *corev1.PodSpec) error {
 	if podSpec.NodeSelector == nil {
 		podSpec.NodeSelector = make(map[string]string)
 	}
@@ -39,7 +43,8 @@ func (b *NodeSelectorPodBlocker) Block(podSpec *corev1.PodSpec, logger logr.Logg
 	return nil
 }
 
-func (b *NodeSelectorPodBlocker) Unblock(podSpec *corev1.PodSpec, logger logr.Logger) error {
+func (b *NodeSelectorPodBlocker) Unblock(pod *corev1.Pod, logger logr.Logger) error {
+	podSpec := &pod.Spec
 	if podSpec.NodeSelector == nil {
 		return nil
 	}
@@ -48,6 +53,14 @@ func (b *NodeSelectorPodBlocker) Unblock(podSpec *corev1.PodSpec, logger logr.Lo
 	return nil
 }
 
+func (b *NodeSelectorPodBlocker) Preserve(podSpec *corev1.PodSpec, oldPodSpec *corev1.PodSpec, logger logr.Logger) error {
+	if !b.IsBlocked(oldPodSpec) {
+		return nil
+	}
+
+	return b.block(podSpec)
+}
+
 func (b *NodeSelectorPodBlocker) IsBlocked(podSpec *corev1.PodSpec) bool {
 	if podSpec.NodeSelector == nil {
 		return false
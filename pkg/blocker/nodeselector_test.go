@@ -18,20 +18,20 @@ func TestNodeSelectorPodBlockerSimple(t *testing.T) {
 	pod := corev1.Pod{}
 
 	b := NewNodeSelectorPodBlocker()
-	err := b.Block(&pod.Spec, logger)
+	err := b.Block(&pod, logger)
 	require.NoError(t, err)
 	v, ok := pod.Spec.NodeSelector[DefaultNodeSelectorBlockerLabelName]
 	require.True(t, ok)
 	require.Equal(t, DefaultNodeSelectorBlockerLabelValue, v)
 
-	err = b.Block(&pod.Spec, logger)
+	err = b.Block(&pod, logger)
 	require.NoError(t, err)
 
-	err = b.Unblock(&pod.Spec, logger)
+	err = b.Unblock(&pod, logger)
 	require.NoError(t, err)
 	_, ok = pod.Spec.NodeSelector[DefaultNodeSelectorBlockerLabelName]
 	require.False(t, ok)
 
-	err = b.Unblock(&pod.Spec, logger)
+	err = b.Unblock(&pod, logger)
 	require.NoError(t, err)
 }
@@ -0,0 +1,197 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package blocker
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// Annotation with gzipped and base64 encoded json of the pod spec
+	// before it was blocked.
+	DefaultOriginalSpecAnnotation = "v1.straggler.technicianted/originalSpec"
+	// Annotation with the name of a config map in the pod namespace that
+	// keeps the original spec if it is too large for the pod annotations.
+	DefaultOriginalSpecConfigMapAnnotation = "v1.straggler.technicianted/originalSpecConfigMap"
+	// Label of config maps that keep original specs.
+	DefaultOriginalSpecLabel = "v1.straggler.technicianted/originalSpec"
+	// Prefix of generated names of config maps that keep original specs.
+	DefaultOriginalSpecConfigMapPrefix = "straggler-spec-"
+	// Maximum size of the encoded original spec in pod annotations. Larger
+	// specs are kept in config maps to stay well within the total
+	// annotations size limit.
+	MaxOriginalSpecSize = 128 * 1024
+	// Timeout of requests to keep and get original specs in config maps.
+	DefaultOriginalSpecTimeout = 10 * time.Second
+)
+
+// Returned when an encoded pod spec exceeds MaxOriginalSpecSize.
+var ErrOriginalSpecTooLarge = errors.New("original pod spec too large")
+
+// Key of the encoded spec in config maps that keep original specs.
+const originalSpecKey = "spec"
+
+// Record podSpec in meta annotations to be restored later using
+// GetOriginalSpec. ErrOriginalSpecTooLarge is returned if it does not fit.
+func SetOriginalSpec(meta *metav1.ObjectMeta, podSpec *corev1.PodSpec) error {
+	encoded, err := encodeSpec(podSpec)
+	if err != nil {
+		return err
+	}
+	if len(encoded) > MaxOriginalSpecSize {
+		return fmt.Errorf("%w: encoded size %d exceeds maximum %d", ErrOriginalSpecTooLarge, len(encoded), MaxOriginalSpecSize)
+	}
+
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[DefaultOriginalSpecAnnotation] = encoded
+
+	return nil
+}
+
+// Get the pod spec recorded in meta annotations. nil is returned if none
+// was recorded.
+func GetOriginalSpec(meta metav1.ObjectMeta) (*corev1.PodSpec, error) {
+	encoded, ok := meta.Annotations[DefaultOriginalSpecAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	return decodeSpec(encoded)
+}
+
+// Keep podSpec of pod in a new config map and record its name in pod
+// annotations. The config map is owned by the pod once it is adopted by
+// AdoptOriginalSpecConfigMap.
+func SetOriginalSpecConfigMap(ctx context.Context, client client.Client, pod *corev1.Pod, podSpec *corev1.PodSpec) error {
+	encoded, err := encodeSpec(podSpec)
+	if err != nil {
+		return err
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: DefaultOriginalSpecConfigMapPrefix,
+			Namespace:    pod.Namespace,
+			Labels: map[string]string{
+				DefaultOriginalSpecLabel: "1",
+			},
+		},
+		Data: map[string]string{
+			originalSpecKey: encoded,
+		},
+	}
+	if err := client.Create(ctx, configMap); err != nil {
+		return fmt.Errorf("failed to create original spec config map: %v", err)
+	}
+
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[DefaultOriginalSpecConfigMapAnnotation] = configMap.Name
+
+	return nil
+}
+
+// Get the pod spec kept in the config map recorded in pod annotations. nil
+// is returned if none was recorded.
+func GetOriginalSpecConfigMap(ctx context.Context, client client.Client, pod *corev1.Pod) (*corev1.PodSpec, error) {
+	name, ok := pod.Annotations[DefaultOriginalSpecConfigMapAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := client.Get(ctx, apitypes.NamespacedName{Namespace: pod.Namespace, Name: name}, configMap); err != nil {
+		return nil, fmt.Errorf("failed to get original spec config map: %v", err)
+	}
+	encoded, ok := configMap.Data[originalSpecKey]
+	if !ok {
+		return nil, fmt.Errorf("config map %s has no original spec", name)
+	}
+
+	return decodeSpec(encoded)
+}
+
+// Make the config map that keeps the original spec of pod, if any, owned by
+// pod such that it is removed along with it. Pods have no UID when blocked
+// so this can only be done once they are created.
+func AdoptOriginalSpecConfigMap(ctx context.Context, c client.Client, pod *corev1.Pod) error {
+	name, ok := pod.Annotations[DefaultOriginalSpecConfigMapAnnotation]
+	if !ok || len(pod.UID) == 0 {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"ownerReferences": []metav1.OwnerReference{{
+				APIVersion: "v1",
+				Kind:       "Pod",
+				Name:       pod.Name,
+				UID:        pod.UID,
+			}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: pod.Namespace,
+			Name:      name,
+		},
+	}
+	if err := c.Patch(ctx, configMap, client.RawPatch(apitypes.MergePatchType, patch)); err != nil {
+		return fmt.Errorf("failed to adopt original spec config map: %v", err)
+	}
+
+	return nil
+}
+
+func encodeSpec(podSpec *corev1.PodSpec) (string, error) {
+	raw, err := json.Marshal(podSpec)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal pod spec: %v", err)
+	}
+	buffer := bytes.Buffer{}
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(raw); err != nil {
+		return "", fmt.Errorf("failed to compress pod spec: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress pod spec: %v", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(buffer.Bytes()), nil
+}
+
+func decodeSpec(encoded string) (*corev1.PodSpec, error) {
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode original pod spec: %v", err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress original pod spec: %v", err)
+	}
+	raw, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress original pod spec: %v", err)
+	}
+	podSpec := corev1.PodSpec{}
+	if err := json.Unmarshal(raw, &podSpec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal original pod spec: %v", err)
+	}
+
+	return &podSpec, nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package blocker
+
+import (
+	"straggler/pkg/blocker/types"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	DefaultSchedulingGateName = "straggler"
+)
+
+var (
+	_ types.PodBlocker = &SchedulingGatesPodBlocker{}
+)
+
+// An implementation of a pod blocker using pod scheduling gates. Pods
+// with scheduling gates are kept in SchedulingGated state until all gates
+// are removed. Since gates can be removed from existing pods, blocked
+// pods can be released in place without being recreated.
+type SchedulingGatesPodBlocker struct {
+	gateName string
+}
+
+func NewSchedulingGatesPodBlocker() *SchedulingGatesPodBlocker {
+	return &SchedulingGatesPodBlocker{
+		gateName: DefaultSchedulingGateName,
+	}
+}
+
+func (b *SchedulingGatesPodBlocker) Block(pod *corev1.Pod, logger logr.Logger) error {
+	return b.block(&pod.Spec)
+}
+
+func (b *SchedulingGatesPodBlocker) block(podSpec *corev1.PodSpec) error {
+	if b.IsBlocked(podSpec) {
+		return nil
+	}
+	podSpec.SchedulingGates = append(podSpec.SchedulingGates, corev1.PodSchedulingGate{
+		Name: b.gateName,
+	})
+
+	return nil
+}
+
+func (b *SchedulingGatesPodBlocker) Unblock(pod *corev1.Pod, logger logr.Logger) error {
+	podSpec := &pod.Spec
+	gates := make([]corev1.PodSchedulingGate, 0)
+	for _, gate := range podSpec.SchedulingGates {
+		if gate.Name != b.gateName {
+			gates = append(gates, gate)
+		}
+	}
+	if len(gates) == 0 {
+		gates = nil
+	}
+	podSpec.SchedulingGates = gates
+
+	return nil
+}
+
+func (b *SchedulingGatesPodBlocker) Preserve(podSpec *corev1.PodSpec, oldPodSpec *corev1.PodSpec, logger logr.Logger) error {
+	if !b.IsBlocked(oldPodSpec) {
+		return nil
+	}
+
+	return b.block(podSpec)
+}
+
+func (b *SchedulingGatesPodBlocker) IsBlocked(podSpec *corev1.PodSpec) bool {
+	for _, gate := range podSpec.SchedulingGates {
+		if gate.Name == b.gateName {
+			return true
+		}
+	}
+
+	return false
+}
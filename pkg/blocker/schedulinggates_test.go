@@ -0,0 +1,68 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package blocker
+
+import (
+	"testing"
+
+	"github.com/go-logr/zapr"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSchedulingGatesPodBlockerSimple(t *testing.T) {
+	zlog, _ := zap.NewDevelopment()
+	logger := zapr.NewLogger(zlog)
+
+	pod := corev1.Pod{
+		Spec: corev1.PodSpec{
+			SchedulingGates: []corev1.PodSchedulingGate{
+				{Name: "other"},
+			},
+		},
+	}
+
+	b := NewSchedulingGatesPodBlocker()
+	require.False(t, b.IsBlocked(&pod.Spec))
+	err := b.Block(&pod, logger)
+	require.NoError(t, err)
+	require.True(t, b.IsBlocked(&pod.Spec))
+	require.Len(t, pod.Spec.SchedulingGates, 2)
+
+	// blocking is idempotent
+	err = b.Block(&pod, logger)
+	require.NoError(t, err)
+	require.Len(t, pod.Spec.SchedulingGates, 2)
+
+	// only own gate is removed
+	err = b.Unblock(&pod, logger)
+	require.NoError(t, err)
+	require.False(t, b.IsBlocked(&pod.Spec))
+	require.Equal(t, []corev1.PodSchedulingGate{{Name: "other"}}, pod.Spec.SchedulingGates)
+
+	err = b.Unblock(&pod, logger)
+	require.NoError(t, err)
+}
+
+func TestSchedulingGatesPodBlockerPreserve(t *testing.T) {
+	zlog, _ := zap.NewDevelopment()
+	logger := zapr.NewLogger(zlog)
+
+	oldPod := corev1.Pod{}
+	b := NewSchedulingGatesPodBlocker()
+	err := b.Block(&oldPod, logger)
+	require.NoError(t, err)
+
+	// removed gate is restored
+	pod := corev1.Pod{}
+	err = b.Preserve(&pod.Spec, &oldPod.Spec, logger)
+	require.NoError(t, err)
+	require.True(t, b.IsBlocked(&pod.Spec))
+
+	// pods that were not blocked are left as is
+	pod = corev1.Pod{}
+	err = b.Preserve(&pod.Spec, &corev1.PodSpec{}, logger)
+	require.NoError(t, err)
+	require.False(t, b.IsBlocked(&pod.Spec))
+}
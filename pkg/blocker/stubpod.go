@@ -3,11 +3,14 @@
 package blocker
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"straggler/pkg/blocker/types"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var _ types.PodBlocker = &stubPod{}
@@ -18,17 +21,33 @@ var _ types.PodBlocker = &stubPod{}
 // The use of sleeping init container marks the pod as both not Ready
 // and in `Init` state as an indication of being staggered.
 // A log line is dropped to also indicate that situation.
+// The original spec of pods is recorded in the pod annotations, or in a
+// config map if it is too large, so it can be restored by Unblock to
+// recreate the pod. Pods are not blocked if their spec cannot be recorded.
 type stubPod struct {
 	containerImage string
+	client         client.Client
 }
 
-func NewStubPod(containerImage string) types.PodBlocker {
+// Create a new stub pod blocker. client is used to keep original specs
+// that are too large for pod annotations, such specs cannot be recorded
+// if it is nil.
+func NewStubPod(containerImage string, client client.Client) types.PodBlocker {
 	return &stubPod{
 		containerImage: containerImage,
+		client:         client,
 	}
 }
 
-func (b *stubPod) Block(podSpec *corev1.PodSpec, logger logr.Logger) error {
+func (b *stubPod) Block(pod *corev1.Pod, logger logr.Logger) error {
+	podSpec := &pod.Spec
+	if b.IsBlocked(podSpec) {
+		return nil
+	}
+	if err := b.setOriginalSpec(pod, logger); err != nil {
+		return fmt.Errorf("failed to record original spec: %v", err)
+	}
+
 	for i, container := range podSpec.InitContainers {
 		container.Image = b.containerImage
 		container.Command = nil
@@ -53,8 +72,73 @@ func (b *stubPod) Block(podSpec *corev1.PodSpec, logger logr.Logger) error {
 	return nil
 }
 
-func (b *stubPod) Unblock(podSpec *corev1.PodSpec, logger logr.Logger) error {
-	return fmt.Errorf("not supported")
+// Restore containers of pod from its recorded original spec. Since pod
+// containers are immutable, this is only useful for pods that are about
+// to be created again.
+func (b *stubPod) Unblock(pod *corev1.Pod, logger logr.Logger) error {
+	if !b.IsBlocked(&pod.Spec) {
+		return nil
+	}
+	original, err := b.getOriginalSpec(pod)
+	if err != nil {
+		return err
+	}
+	if original == nil {
+		return fmt.Errorf("pod has no recorded original spec")
+	}
+	pod.Spec.InitContainers = original.InitContainers
+	pod.Spec.Containers = original.Containers
+	delete(pod.Annotations, DefaultOriginalSpecAnnotation)
+	delete(pod.Annotations, DefaultOriginalSpecConfigMapAnnotation)
+
+	return nil
+}
+
+// Record the spec of pod in its annotations, or in a config map if it is
+// too large.
+func (b *stubPod) setOriginalSpec(pod *corev1.Pod, logger logr.Logger) error {
+	err := SetOriginalSpec(&pod.ObjectMeta, &pod.Spec)
+	if !errors.Is(err, ErrOriginalSpecTooLarge) || b.client == nil {
+		return err
+	}
+	logger.Info("keeping original spec in config map", "reason", err)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultOriginalSpecTimeout)
+	defer cancel()
+	return SetOriginalSpecConfigMap(ctx, b.client, pod, &pod.Spec)
+}
+
+func (b *stubPod) getOriginalSpec(pod *corev1.Pod) (*corev1.PodSpec, error) {
+	if _, ok := pod.Annotations[DefaultOriginalSpecConfigMapAnnotation]; !ok || b.client == nil {
+		return GetOriginalSpec(pod.ObjectMeta)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultOriginalSpecTimeout)
+	defer cancel()
+	return GetOriginalSpecConfigMap(ctx, b.client, pod)
+}
+
+// Restore stub images of oldPodSpec. Since only images of containers can
+// be updated, this also restores the stagger init container.
+func (b *stubPod) Preserve(podSpec *corev1.PodSpec, oldPodSpec *corev1.PodSpec, logger logr.Logger) error {
+	if !b.IsBlocked(oldPodSpec) {
+		return nil
+	}
+	preserveImages(podSpec.InitContainers, oldPodSpec.InitContainers)
+	preserveImages(podSpec.Containers, oldPodSpec.Containers)
+
+	return nil
+}
+
+// Set images of containers to those of oldContainers with the same name.
+func preserveImages(containers []corev1.Container, oldContainers []corev1.Container) {
+	images := make(map[string]string)
+	for _, container := range oldContainers {
+		images[container.Name] = container.Image
+	}
+	for i, container := range containers {
+		if image, ok := images[container.Name]; ok {
+			containers[i].Image = image
+		}
+	}
 }
 
 func (b *stubPod) IsBlocked(podSpec *corev1.PodSpec) bool {
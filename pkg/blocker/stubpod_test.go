@@ -3,12 +3,17 @@
 package blocker
 
 import (
+	"context"
 	"testing"
 
 	"github.com/go-logr/zapr"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestStubPodSuccess(t *testing.T) {
@@ -36,8 +41,8 @@ func TestStubPodSuccess(t *testing.T) {
 		},
 	}
 
-	blocker := NewStubPod("staggerimage")
-	err := blocker.Block(&pod.Spec, logger)
+	blocker := NewStubPod("staggerimage", nil)
+	err := blocker.Block(&pod, logger)
 	require.NoError(t, err)
 	require.Len(t, pod.Spec.InitContainers, 2)
 	initContainer := pod.Spec.InitContainers[0]
@@ -58,3 +63,148 @@ func TestStubPodSuccess(t *testing.T) {
 	blocked = blocker.IsBlocked(&pod.Spec)
 	require.False(t, blocked)
 }
+
+func TestStubPodPreserve(t *testing.T) {
+	zlog, _ := zap.NewDevelopment()
+	logger := zapr.NewLogger(zlog)
+
+	oldPod := corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "container1", Image: "image1"},
+			},
+		},
+	}
+
+	blocker := NewStubPod("staggerimage", nil)
+	err := blocker.Block(&oldPod, logger)
+	require.NoError(t, err)
+
+	pod := *oldPod.DeepCopy()
+	pod.Spec.Containers[0].Image = "image1"
+	pod.Spec.InitContainers[0].Image = "someotherimage"
+	require.False(t, blocker.IsBlocked(&pod.Spec))
+	err = blocker.Preserve(&pod.Spec, &oldPod.Spec, logger)
+	require.NoError(t, err)
+	require.True(t, blocker.IsBlocked(&pod.Spec))
+	require.Equal(t, oldPod, pod)
+}
+
+func TestStubPodUnblock(t *testing.T) {
+	zlog, _ := zap.NewDevelopment()
+	logger := zapr.NewLogger(zlog)
+
+	original := corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{
+				{
+					Name:    "init1",
+					Image:   "image1",
+					Command: []string{"command1"},
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:         "container1",
+					Image:        "image1",
+					Args:         []string{"args1"},
+					VolumeMounts: []corev1.VolumeMount{{Name: "volume1", MountPath: "/data"}},
+				},
+			},
+		},
+	}
+
+	blocker := NewStubPod("staggerimage", nil)
+	pod := *original.DeepCopy()
+	err := blocker.Block(&pod, logger)
+	require.NoError(t, err)
+	require.Contains(t, pod.Annotations, DefaultOriginalSpecAnnotation)
+	recorded, err := GetOriginalSpec(pod.ObjectMeta)
+	require.NoError(t, err)
+	require.Equal(t, original.Spec, *recorded)
+
+	// blocking is idempotent
+	err = blocker.Block(&pod, logger)
+	require.NoError(t, err)
+	require.Len(t, pod.Spec.InitContainers, 2)
+
+	err = blocker.Unblock(&pod, logger)
+	require.NoError(t, err)
+	require.False(t, blocker.IsBlocked(&pod.Spec))
+	require.Equal(t, original.Spec, pod.Spec)
+	require.NotContains(t, pod.Annotations, DefaultOriginalSpecAnnotation)
+
+	// pods without recorded spec cannot be unblocked
+	err = blocker.Block(&pod, logger)
+	require.NoError(t, err)
+	delete(pod.Annotations, DefaultOriginalSpecAnnotation)
+	err = blocker.Unblock(&pod, logger)
+	require.Error(t, err)
+}
+
+func TestStubPodOriginalSpec(t *testing.T) {
+	zlog, _ := zap.NewDevelopment()
+	logger := zapr.NewLogger(zlog)
+
+	newPod := func() corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "pod",
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "container1", Image: "image1"},
+				},
+			},
+		}
+	}
+	cl := fake.NewClientBuilder().Build()
+	blocker := NewStubPod("staggerimage", cl)
+
+	// specs of pods with controllers are recorded as well
+	pod := newPod()
+	pod.OwnerReferences = []metav1.OwnerReference{
+		{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs", Controller: ptr.To(true)},
+	}
+	err := blocker.Block(&pod, logger)
+	require.NoError(t, err)
+	require.True(t, blocker.IsBlocked(&pod.Spec))
+	require.Contains(t, pod.Annotations, DefaultOriginalSpecAnnotation)
+
+	// specs too large for annotations are kept in config maps
+	maxSize := MaxOriginalSpecSize
+	MaxOriginalSpecSize = 1
+	defer func() { MaxOriginalSpecSize = maxSize }()
+	pod = newPod()
+	err = blocker.Block(&pod, logger)
+	require.NoError(t, err)
+	require.True(t, blocker.IsBlocked(&pod.Spec))
+	require.NotContains(t, pod.Annotations, DefaultOriginalSpecAnnotation)
+	name := pod.Annotations[DefaultOriginalSpecConfigMapAnnotation]
+	require.NotEmpty(t, name)
+
+	// config maps are owned by their pods once created
+	pod.UID = "uid"
+	err = AdoptOriginalSpecConfigMap(context.TODO(), cl, &pod)
+	require.NoError(t, err)
+	configMap := &corev1.ConfigMap{}
+	err = cl.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: name}, configMap)
+	require.NoError(t, err)
+	require.Equal(t, "1", configMap.Labels[DefaultOriginalSpecLabel])
+	require.Len(t, configMap.OwnerReferences, 1)
+	require.Equal(t, pod.UID, configMap.OwnerReferences[0].UID)
+
+	err = blocker.Unblock(&pod, logger)
+	require.NoError(t, err)
+	require.Equal(t, newPod().Spec, pod.Spec)
+	require.NotContains(t, pod.Annotations, DefaultOriginalSpecConfigMapAnnotation)
+
+	// pods are not blocked if their spec cannot be recorded
+	blocker = NewStubPod("staggerimage", nil)
+	pod = newPod()
+	err = blocker.Block(&pod, logger)
+	require.Error(t, err)
+	require.False(t, blocker.IsBlocked(&pod.Spec))
+	require.Equal(t, newPod(), pod)
+}
@@ -12,10 +12,13 @@ import (
 // PodBlocker is an interface to define functionality for blocking a pod from
 // being scheduled until capture jobs are run to completion.
 type PodBlocker interface {
-	// Block modifies podSpec such that it makes it impossible to schedule.
-	Block(podSpec *corev1.PodSpec, logger logr.Logger) error
-	// Unblock removes any blocking that was inserted in podSpec.
-	Unblock(podSpec *corev1.PodSpec, logger logr.Logger) error
+	// Block modifies pod such that it makes it impossible to schedule.
+	Block(pod *corev1.Pod, logger logr.Logger) error
+	// Unblock removes any blocking that was inserted in pod.
+	Unblock(pod *corev1.Pod, logger logr.Logger) error
 	// IsBlocked checks to see if podSpec has been blocked.
 	IsBlocked(podSpec *corev1.PodSpec) bool
+	// Preserve restores blocking of oldPodSpec that was changed in podSpec
+	// by a pod update.
+	Preserve(podSpec *corev1.PodSpec, oldPodSpec *corev1.PodSpec, logger logr.Logger) error
 }
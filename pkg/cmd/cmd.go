@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
@@ -25,13 +26,22 @@ func NewCMDWithManager(mgr manager.Manager, options Options, logger logr.Logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load configs: %v", err)
 	}
+	if errs := config.Validate(); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configs: %v", errs.ToAggregate())
+	}
 
-	classifier, err := NewGroupClassifier(config.StaggeringPolicies, logger)
+	namespaceLabeler, err := NewNamespaceLabeler(mgr, logger)
+	if err != nil {
+		return nil, err
+	}
+	// policies loaded later share the same clock.
+	realClock := clock.RealClock{}
+	classifier, err := NewGroupClassifier(config.StaggeringPolicies, namespaceLabeler, realClock, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	blocker, err := NewBlocker(options)
+	blocker, err := NewBlocker(options, mgr.GetClient())
 	if err != nil {
 		return nil, err
 	}
@@ -40,13 +50,19 @@ func NewCMDWithManager(mgr manager.Manager, options Options, logger logr.Logger)
 		return nil, err
 	}
 
-	recorderFactory, err := NewRecorderFactory(logger)
+	recorderFactory, err := NewRecorderFactory(mgr, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	matchPredicate, err := GetMatchLabelsPredicate(options, config, logger)
+	enableChecker, err := NewEnableChecker(options, namespaceLabeler, logger)
 	if err != nil {
+		return nil, err
+	}
+
+	matchPredicate := newReloadablePredicate(nil)
+	sources := newPolicySources(options, enableChecker, matchPredicate)
+	if err := sources.Set(configFilePolicySource, config.StaggeringPolicies, logger); err != nil {
 		return nil, fmt.Errorf("failed to get match predicates for reconciler: %v", err)
 	}
 	if err := RegisterReconciler(
@@ -55,6 +71,9 @@ func NewCMDWithManager(mgr manager.Manager, options Options, logger logr.Logger)
 		mgr,
 		classifier,
 		podGroupClassifier,
+		recorderFactory,
+		enableChecker,
+		blocker,
 		logger,
 	); err != nil {
 		return nil, err
@@ -67,11 +86,25 @@ func NewCMDWithManager(mgr manager.Manager, options Options, logger logr.Logger)
 		classifier,
 		podGroupClassifier,
 		recorderFactory,
+		enableChecker,
 		logger,
 	); err != nil {
 		return nil, err
 	}
 
+	if options.EnablePolicyCRDs {
+		if err := RegisterPolicyReconcilers(mgr, classifier, sources, realClock, logger); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.StaggeringConfigReloadInterval > 0 {
+		reloader := newConfigReloader(options, config, classifier, sources, realClock, logger)
+		if err := mgr.Add(reloader); err != nil {
+			return nil, fmt.Errorf("failed to add config reloader: %v", err)
+		}
+	}
+
 	return &CMD{
 		options: options,
 		mgr:     mgr,
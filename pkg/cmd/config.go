@@ -3,36 +3,34 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"reflect"
+
+	"straggler/pkg/apis/v1alpha1"
 
 	"github.com/go-logr/logr"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
 )
 
-type ExponentialPacer struct {
-	MinInitial *int
-	MaxStagger *int
-	Multiplier *float64
-}
-
-type LinearPacer struct {
-	MaxStagger *int
-	Step       *int
-}
-
-type Pacer struct {
-	Exponential *ExponentialPacer
-	Linear      *LinearPacer
-}
+type ExponentialPacer = v1alpha1.ExponentialPacer
+type LinearPacer = v1alpha1.LinearPacer
+type RatePacer = v1alpha1.RatePacer
+type ConcurrencyPacer = v1alpha1.ConcurrencyPacer
+type AdaptivePacer = v1alpha1.AdaptivePacer
+type Pacer = v1alpha1.Pacer
+type BasePacer = v1alpha1.BasePacer
+type SchedulePacer = v1alpha1.SchedulePacer
+type Ordering = v1alpha1.Ordering
+type CircuitBreaker = v1alpha1.CircuitBreaker
+type Readiness = v1alpha1.Readiness
 
+// StaggeringPolicy is a named policy spec. Policy specs are shared with
+// StaggeringPolicy custom resources.
 type StaggeringPolicy struct {
-	Name                string
-	LabelSelector       map[string]string
-	BypassLabelSelector map[string]string
-	GroupingExpression  string
-	MaxBlockedDuration  metav1.Duration
-	Pacer               Pacer
+	Name string `json:"name"`
+
+	v1alpha1.StaggeringPolicySpec `json:",inline"`
 }
 
 type Config struct {
@@ -58,3 +56,34 @@ func LoadConfigFromString(configString string, logger logr.Logger) (Config, erro
 
 	return config, nil
 }
+
+// Compare two configs by policy names and return policies in desired that are
+// added or changed and policies in current that are removed.
+func DiffConfigs(current, desired Config) (added, removed, changed []StaggeringPolicy, err error) {
+	currentByName := make(map[string]StaggeringPolicy)
+	for _, policy := range current.StaggeringPolicies {
+		currentByName[policy.Name] = policy
+	}
+	desiredNames := make(map[string]bool)
+	for _, policy := range desired.StaggeringPolicies {
+		if desiredNames[policy.Name] {
+			return nil, nil, nil, fmt.Errorf("duplicate policy name: %s", policy.Name)
+		}
+		desiredNames[policy.Name] = true
+
+		existing, ok := currentByName[policy.Name]
+		switch {
+		case !ok:
+			added = append(added, policy)
+		case !reflect.DeepEqual(existing, policy):
+			changed = append(changed, policy)
+		}
+	}
+	for _, policy := range current.StaggeringPolicies {
+		if !desiredNames[policy.Name] {
+			removed = append(removed, policy)
+		}
+	}
+
+	return
+}
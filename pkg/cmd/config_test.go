@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestLoadConfig_Success(t *testing.T) {
@@ -89,6 +91,45 @@ staggeringPolicies:
 	}
 }
 
+func TestLoadConfigFromString_Selectors(t *testing.T) {
+	logger := testr.New(t)
+
+	configString := `
+staggeringPolicies:
+  - name: map
+    labelSelector:
+      app: web
+  - name: full
+    labelSelector:
+      matchLabels:
+        app: web
+      matchExpressions:
+      - key: tier
+        operator: In
+        values: [frontend]
+    namespaceSelector:
+      matchLabels:
+        env: prod
+    excludedNamespaces: [kube-system]
+`
+
+	config, err := LoadConfigFromString(configString, logger)
+	require.NoError(t, err)
+	require.Len(t, config.StaggeringPolicies, 2)
+
+	mapPolicy := config.StaggeringPolicies[0]
+	require.Equal(t, map[string]string{"app": "web"}, mapPolicy.LabelSelector.MatchLabels)
+	require.Empty(t, mapPolicy.LabelSelector.MatchExpressions)
+
+	fullPolicy := config.StaggeringPolicies[1]
+	require.Equal(t, map[string]string{"app": "web"}, fullPolicy.LabelSelector.MatchLabels)
+	require.Equal(t, []metav1.LabelSelectorRequirement{
+		{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"frontend"}},
+	}, fullPolicy.LabelSelector.MatchExpressions)
+	require.Equal(t, map[string]string{"env": "prod"}, fullPolicy.NamespaceSelector.MatchLabels)
+	require.Equal(t, []string{"kube-system"}, fullPolicy.ExcludedNamespaces)
+}
+
 func TestLoadConfigFromString_InvalidYAML(t *testing.T) {
 	logger := testr.New(t)
 
@@ -3,24 +3,46 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"time"
+
+	"straggler/pkg/apis/v1alpha1"
 	"straggler/pkg/blocker"
 	blockertypes "straggler/pkg/blocker/types"
 	"straggler/pkg/config/types"
 	"straggler/pkg/controller"
 	controllertypes "straggler/pkg/controller/types"
+	"straggler/pkg/pacer"
+	"straggler/pkg/pacer/adaptive"
+	"straggler/pkg/pacer/breaker"
+	"straggler/pkg/pacer/concurrency"
 	"straggler/pkg/pacer/exponential"
 	"straggler/pkg/pacer/linear"
+	"straggler/pkg/pacer/ordering"
+	"straggler/pkg/pacer/rate"
+	"straggler/pkg/pacer/schedule"
 	pacertypes "straggler/pkg/pacer/types"
+	"straggler/pkg/unblocker"
+	unblockertypes "straggler/pkg/unblocker/types"
 
 	"github.com/go-logr/logr"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -28,6 +50,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
+var (
+	DefaultRatePacerPeriod = time.Minute
+)
+
 func NewControllerManager(options Options, logger logr.Logger) (manager.Manager, error) {
 	webhookOptions := webhook.Options{
 		CertDir:  options.TLSDir,
@@ -35,7 +61,25 @@ func NewControllerManager(options Options, logger logr.Logger) (manager.Manager,
 		CertName: options.TLSCertFilename,
 		Port:     options.TLSListenPort,
 	}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	// only config maps of pods to be recreated are of interest.
+	recreateSelector, err := labels.Parse(unblocker.DefaultRecreatePodLabel)
+	if err != nil {
+		return nil, err
+	}
 	managerOptions := manager.Options{
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.ConfigMap{}: {Label: recreateSelector},
+			},
+		},
+		Scheme:                 scheme,
 		LeaderElection:         options.LeaderElection,
 		LeaderElectionID:       options.LeaderElectionID,
 		Metrics:                server.Options{BindAddress: "0"},
@@ -66,45 +110,242 @@ func NewControllerManager(options Options, logger logr.Logger) (manager.Manager,
 	return mgr, nil
 }
 
-func NewPacerFactory(policy StaggeringPolicy, logger logr.Logger) (pacertypes.PacerFactory, error) {
-	switch {
-	case policy.Pacer.Exponential != nil:
+// Create a pacer factory for policy. If more than one pacer is configured
+// then a composite pacer factory is returned that allows pods only if allowed
+// by all pacers. Blocked pods are ordered before pacing by the policy
+// ordering, and no pods are allowed while its circuit breaker is open.
+func NewPacerFactory(policy StaggeringPolicy, clock clock.PassiveClock, logger logr.Logger) (pacertypes.PacerFactory, error) {
+	factories := newBasePacerFactories(policy.Name, policy.Pacer.BasePacer, clock, logger)
+	if policy.Pacer.Schedule != nil {
+		factory, err := newSchedulePacerFactory(policy.Name, *policy.Pacer.Schedule, clock, logger)
+		if err != nil {
+			return nil, err
+		}
+		factories = append(factories, factory)
+	}
+
+	factory, err := newCompositePacerFactory(factories)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.CircuitBreaker != nil {
+		config := breaker.Config{}
+		if policy.CircuitBreaker.FailureThreshold != nil {
+			config.FailureThreshold = *policy.CircuitBreaker.FailureThreshold
+		}
+		if policy.CircuitBreaker.FailureRatio != nil {
+			config.FailureRatio = *policy.CircuitBreaker.FailureRatio
+		}
+		if policy.CircuitBreaker.Cooldown != nil {
+			config.Cooldown = policy.CircuitBreaker.Cooldown.Duration
+		}
+		logger.Info("creating circuit breaker", "policy", policy.Name, "config", config)
+		factory = breaker.NewFactory(config, factory, clock)
+	}
+
+	config := ordering.Config{
+		Strategy: ordering.FIFO,
+	}
+	if policy.Ordering != nil {
+		if len(policy.Ordering.Strategy) > 0 {
+			config.Strategy = policy.Ordering.Strategy
+		}
+		config.NamespaceWeights = policy.Ordering.NamespaceWeights
+		config.RespectStatefulSetPartition = policy.Ordering.RespectStatefulSetPartition
+		if policy.Ordering.Fairness != nil {
+			config.Fairness = &ordering.FairnessConfig{
+				Mode:    policy.Ordering.Fairness.Mode,
+				By:      ordering.ByNamespace,
+				Weights: policy.Ordering.Fairness.Weights,
+			}
+			if len(policy.Ordering.Fairness.By) > 0 {
+				config.Fairness.By = policy.Ordering.Fairness.By
+			}
+		}
+	}
+	logger.V(1).Info("creating ordering", "policy", policy.Name, "config", config)
+	return ordering.NewFactory(config, factory), nil
+}
+
+func newBasePacerFactories(name string, basePacer BasePacer, clock clock.PassiveClock, logger logr.Logger) []pacertypes.PacerFactory {
+	factories := make([]pacertypes.PacerFactory, 0)
+	if basePacer.Exponential != nil {
 		config := exponential.Config{
-			MinInitial: *policy.Pacer.Exponential.MinInitial,
-			MaxStagger: *policy.Pacer.Exponential.MaxStagger,
-			Multiplier: *policy.Pacer.Exponential.Multiplier,
+			MinInitial: *basePacer.Exponential.MinInitial,
+			MaxStagger: *basePacer.Exponential.MaxStagger,
+			Multiplier: *basePacer.Exponential.Multiplier,
 		}
-		logger.Info("creating exponential pacer", "policy", policy.Name, "config", config)
-		return exponential.NewFactory(config), nil
-	case policy.Pacer.Linear != nil:
+		logger.Info("creating exponential pacer", "policy", name, "config", config)
+		factories = append(factories, exponential.NewFactory(config))
+	}
+	if basePacer.Linear != nil {
 		config := linear.Config{
-			MaxStagger: *policy.Pacer.Linear.MaxStagger,
-			Step:       *policy.Pacer.Linear.Step,
+			MaxStagger: *basePacer.Linear.MaxStagger,
+			Step:       *basePacer.Linear.Step,
 		}
-		logger.Info("creating exponential pacer", "policy", policy.Name, "config", config)
-		return linear.NewFactory(config), nil
-	default:
+		logger.Info("creating linear pacer", "policy", name, "config", config)
+		factories = append(factories, linear.NewFactory(config))
+	}
+	if basePacer.Rate != nil {
+		config := rate.Config{
+			Rate:   *basePacer.Rate.Rate,
+			Period: DefaultRatePacerPeriod,
+			Burst:  1,
+		}
+		if basePacer.Rate.Period != nil {
+			config.Period = basePacer.Rate.Period.Duration
+		}
+		if basePacer.Rate.Burst != nil {
+			config.Burst = *basePacer.Rate.Burst
+		}
+		logger.Info("creating rate pacer", "policy", name, "config", config)
+		factories = append(factories, rate.NewFactory(config, clock))
+	}
+	if basePacer.Concurrency != nil {
+		config := concurrency.Config{
+			MaxStarting: *basePacer.Concurrency.MaxStarting,
+		}
+		logger.Info("creating concurrency pacer", "policy", name, "config", config)
+		factories = append(factories, concurrency.NewFactory(config))
+	}
+	if basePacer.Adaptive != nil {
+		config := adaptive.Config{
+			TargetLatency:  basePacer.Adaptive.TargetLatency.Duration,
+			InitialWindow:  1,
+			MinWindow:      1,
+			MaxWindow:      *basePacer.Adaptive.MaxWindow,
+			Increase:       1,
+			DecreaseFactor: 0.5,
+		}
+		if basePacer.Adaptive.InitialWindow != nil {
+			config.InitialWindow = *basePacer.Adaptive.InitialWindow
+		}
+		if basePacer.Adaptive.MinWindow != nil {
+			config.MinWindow = *basePacer.Adaptive.MinWindow
+		}
+		if basePacer.Adaptive.Increase != nil {
+			config.Increase = *basePacer.Adaptive.Increase
+		}
+		if basePacer.Adaptive.DecreaseFactor != nil {
+			config.DecreaseFactor = *basePacer.Adaptive.DecreaseFactor
+		}
+		logger.Info("creating adaptive pacer", "policy", name, "config", config)
+		factories = append(factories, adaptive.NewFactory(config, clock))
+	}
+
+	return factories
+}
+
+func newSchedulePacerFactory(name string, schedulePacer SchedulePacer, clock clock.PassiveClock, logger logr.Logger) (pacertypes.PacerFactory, error) {
+	timeZone := schedulePacer.TimeZone
+	if len(timeZone) == 0 {
+		timeZone = "UTC"
+	}
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load time zone %s: %v", timeZone, err)
+	}
+
+	config := schedule.Config{
+		Location: location,
+	}
+	for i, window := range schedulePacer.Windows {
+		start, err := schedule.ParseTimeOfDay(window.Start)
+		if err != nil {
+			return nil, fmt.Errorf("window %d: %v", i, err)
+		}
+		end, err := schedule.ParseTimeOfDay(window.End)
+		if err != nil {
+			return nil, fmt.Errorf("window %d: %v", i, err)
+		}
+		days := make([]time.Weekday, 0)
+		for _, d := range window.Days {
+			day, err := schedule.ParseWeekday(d)
+			if err != nil {
+				return nil, fmt.Errorf("window %d: %v", i, err)
+			}
+			days = append(days, day)
+		}
+		factory, err := newCompositePacerFactory(newBasePacerFactories(name, window.Pacer, clock, logger))
+		if err != nil {
+			return nil, fmt.Errorf("window %d: %v", i, err)
+		}
+		config.Windows = append(config.Windows, schedule.Window{
+			Days:         days,
+			Start:        start,
+			End:          end,
+			PacerFactory: factory,
+		})
+	}
+	if schedulePacer.Default != nil {
+		factory, err := newCompositePacerFactory(newBasePacerFactories(name, *schedulePacer.Default, clock, logger))
+		if err != nil {
+			return nil, fmt.Errorf("default: %v", err)
+		}
+		config.DefaultPacerFactory = factory
+	}
+
+	logger.Info("creating schedule pacer", "policy", name, "timeZone", timeZone, "windows", len(config.Windows))
+	return schedule.NewFactory(config, clock), nil
+}
+
+func newCompositePacerFactory(factories []pacertypes.PacerFactory) (pacertypes.PacerFactory, error) {
+	switch len(factories) {
+	case 0:
 		return nil, fmt.Errorf("no pacer configuration specified")
+	case 1:
+		return factories[0], nil
+	default:
+		return pacer.NewCompositeFactory(factories), nil
+	}
+}
+
+func NewStaggerGroup(policy StaggeringPolicy, clock clock.PassiveClock, logger logr.Logger) (types.StaggerGroup, error) {
+	if errs := ValidateStaggeringPolicy(policy, field.NewPath(policy.Name)); len(errs) > 0 {
+		return types.StaggerGroup{}, fmt.Errorf("invalid policy %s: %v", policy.Name, errs.ToAggregate())
+	}
+	pacerFactory, err := NewPacerFactory(policy, clock, logger)
+	if err != nil {
+		return types.StaggerGroup{}, fmt.Errorf("failed to create pacer for %s: %v", policy.Name, err)
 	}
+
+	var readiness *types.Readiness
+	if policy.Readiness != nil {
+		readiness = &types.Readiness{
+			ConditionType:     corev1.PodConditionType(policy.Readiness.ConditionType),
+			ContainersRunning: policy.Readiness.ContainersRunning,
+			MinReadyDuration:  time.Duration(policy.Readiness.MinReadySeconds) * time.Second,
+			Succeeded:         policy.Readiness.Succeeded,
+		}
+	}
+
+	return types.StaggerGroup{
+		Name:                  policy.Name,
+		LabelSelector:         policy.LabelSelector.AsLabelSelector(),
+		BypassLabelSelector:   policy.BypassLabelSelector.AsLabelSelector(),
+		NamespaceSelector:     policy.NamespaceSelector,
+		Namespaces:            policy.Namespaces,
+		ExcludedNamespaces:    policy.ExcludedNamespaces,
+		GroupingExpression:    policy.GroupingExpression,
+		GroupingCELExpression: policy.GroupingCELExpression,
+		MaxBlockedDuration:    policy.MaxBlockedDuration.Duration,
+		Unblocker:             policy.Unblocker,
+		Readiness:             readiness,
+		PacerFactory:          pacerFactory,
+	}, nil
 }
 
-func NewGroupClassifier(policies []StaggeringPolicy, logger logr.Logger) (controllertypes.PodClassifier, error) {
-	classifier := controller.NewPodClassifier()
+func NewGroupClassifier(policies []StaggeringPolicy, namespaces controllertypes.NamespaceLabeler, clock clock.PassiveClock, logger logr.Logger) (controllertypes.ConfigurablePodClassifier, error) {
+	classifier := controller.NewPodClassifier(namespaces)
 
 	for _, policy := range policies {
-		logger.V(1).Info("creating new classifer", "policy", policy.Name, "expression", policy.GroupingExpression)
-		pacerFactory, err := NewPacerFactory(policy, logger)
+		logger.V(1).Info("creating new classifer", "policy", policy.Name, "expression", policy.GroupingExpression, "celExpression", policy.GroupingCELExpression)
+		group, err := NewStaggerGroup(policy, clock, logger)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create pacer for %s: %v", policy.Name, err)
-		}
-		err = classifier.AddConfig(types.StaggerGroup{
-			Name:                policy.Name,
-			LabelSelector:       policy.LabelSelector,
-			BypassLabelSelector: policy.BypassLabelSelector,
-			GroupingExpression:  policy.GroupingExpression,
-			MaxBlockedDuration:  policy.MaxBlockedDuration.Duration,
-			PacerFactory:        pacerFactory,
-		}, logger)
+			return nil, err
+		}
+		err = classifier.AddConfig(group, logger)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create pod group classifer for %s: %v", policy.Name, err)
 		}
@@ -119,8 +360,37 @@ func NewPodgroupClassifier(mgr manager.Manager, blocker blockertypes.PodBlocker,
 		blocker), nil
 }
 
-func NewRecorderFactory(logger logr.Logger) (controllertypes.ObjectRecorderFactory, error) {
-	return nil, nil
+func NewNamespaceLabeler(mgr manager.Manager, logger logr.Logger) (controllertypes.NamespaceLabeler, error) {
+	return controller.NewNamespaceLabeler(mgr.GetClient()), nil
+}
+
+// Create an enable checker for pods. If enabled in options, pods are also
+// enabled by their namespace.
+func NewEnableChecker(options Options, namespaces controllertypes.NamespaceLabeler, logger logr.Logger) (controllertypes.EnableChecker, error) {
+	if !options.EnableNamespaces {
+		namespaces = nil
+	}
+	logger.Info("creating enable checker", "enableLabel", options.EnableLabel, "namespaces", options.EnableNamespaces)
+	return controller.NewEnableChecker(options.EnableLabel, namespaces), nil
+}
+
+func NewRecorderFactory(mgr manager.Manager, logger logr.Logger) (controllertypes.ObjectRecorderFactory, error) {
+	return controller.NewRecorderFactory(
+		mgr.GetClient(),
+		mgr.GetEventRecorderFor("straggler")), nil
+}
+
+// Create a new pod validator based on validation mode in opts. nil is
+// returned if validation is off.
+func NewValidator(opts Options, classifier controllertypes.PodClassifier, blocker blockertypes.PodBlocker, enableChecker controllertypes.EnableChecker) (*controller.Validator, error) {
+	switch opts.Validation {
+	case ValidationOff, "":
+		return nil, nil
+	case ValidationWarn, ValidationDeny:
+		return controller.NewValidator(classifier, blocker, enableChecker, opts.Validation), nil
+	default:
+		return nil, fmt.Errorf("unknown validation mode: %s", opts.Validation)
+	}
 }
 
 func RegisterAdmissionController(
@@ -131,6 +401,7 @@ func RegisterAdmissionController(
 	classifier controllertypes.PodClassifier,
 	podGroupClassifier controllertypes.PodGroupStandingClassifier,
 	recorderFactory controllertypes.ObjectRecorderFactory,
+	enableChecker controllertypes.EnableChecker,
 	logger logr.Logger,
 ) error {
 	logger.Info("creating admission controller")
@@ -155,7 +426,9 @@ func RegisterAdmissionController(
 		blocker,
 		flightTracker,
 		options.BypassFailure,
-		options.EnableLabel,
+		enableChecker,
+		options.ServiceAccount,
+		controller.NewStatefulSetGetter(mgr.GetClient()),
 	)
 
 	logger.Info("registering admission controller for pods")
@@ -167,6 +440,21 @@ func RegisterAdmissionController(
 		return fmt.Errorf("failed to register pod admission: %v", err)
 	}
 
+	validator, err := NewValidator(options, classifier, blocker, enableChecker)
+	if err != nil {
+		return err
+	}
+	if validator != nil {
+		logger.Info("registering validation for pods", "mode", options.Validation)
+		err = builder.WebhookManagedBy(mgr).
+			For(&corev1.Pod{}).
+			WithValidator(validator).
+			Complete()
+		if err != nil {
+			return fmt.Errorf("failed to register pod validation: %v", err)
+		}
+	}
+
 	logger.Info("registering admission controller for jobs")
 	err = builder.WebhookManagedBy(mgr).
 		For(&batchv1.Job{}).
@@ -185,13 +473,27 @@ func RegisterReconciler(
 	mgr manager.Manager,
 	classifier controllertypes.PodClassifier,
 	podGroupClassifier controllertypes.PodGroupStandingClassifier,
+	recorderFactory controllertypes.ObjectRecorderFactory,
+	enableChecker controllertypes.EnableChecker,
+	blocker blockertypes.PodBlocker,
 	logger logr.Logger,
 ) error {
+	defaultUnblocker, unblockers, err := NewUnblockers(options, mgr.GetClient(), blocker)
+	if err != nil {
+		return err
+	}
+	logger.Info("using default unblocker", "unblocker", defaultUnblocker.Name())
 	reconciler := controller.NewReconciler(
 		mgr.GetClient(),
 		classifier,
-		podGroupClassifier)
-	err := builder.ControllerManagedBy(mgr).
+		podGroupClassifier,
+		recorderFactory,
+		enableChecker,
+		blocker,
+		defaultUnblocker,
+		unblockers,
+		NewOwnerlessUnblockers(options, mgr.GetClient(), blocker, unblockers))
+	err = builder.ControllerManagedBy(mgr).
 		Named("reconciler").
 		For(&corev1.Pod{}, builder.WithPredicates(matchPredicate)).
 		Complete(reconciler)
@@ -199,31 +501,148 @@ func RegisterReconciler(
 		return fmt.Errorf("failed to watch for pods: %v", err)
 	}
 
+	if options.RecreateOwnerlessPods {
+		logger.Info("registering recreate reconciler")
+		err = builder.ControllerManagedBy(mgr).
+			Named("recreate").
+			For(&corev1.ConfigMap{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+				_, ok := object.GetLabels()[unblocker.DefaultRecreatePodLabel]
+				return ok
+			}))).
+			Complete(controller.NewRecreateReconciler(mgr.GetClient(), recorderFactory))
+		if err != nil {
+			return fmt.Errorf("failed to watch for config maps: %v", err)
+		}
+	}
+
 	return nil
 }
 
-func GetMatchLabelsPredicate(options Options, config Config, logger logr.Logger) (predicate.Predicate, error) {
-	expressions := []metav1.LabelSelectorRequirement{
-		{
-			Key:      options.EnableLabel,
-			Operator: metav1.LabelSelectorOpExists,
-		},
+// Register reconcilers for StaggeringPolicy and ClusterStaggeringPolicy
+// resources that apply them to configurator using clock for their pacers.
+func RegisterPolicyReconcilers(
+	mgr manager.Manager,
+	configurator controllertypes.PodClassifierConfigurator,
+	sources *policySources,
+	clock clock.PassiveClock,
+	logger logr.Logger,
+) error {
+	groupFactory := func(name, namespace string, spec v1alpha1.StaggeringPolicySpec, logger logr.Logger) (types.StaggerGroup, error) {
+		group, err := NewStaggerGroup(StaggeringPolicy{Name: name, StaggeringPolicySpec: spec}, clock, logger)
+		if err != nil {
+			return types.StaggerGroup{}, err
+		}
+		group.Namespace = namespace
+		return group, nil
+	}
+	changeHandler := func(source string) controller.PolicyChangeHandler {
+		return func(specs map[string]v1alpha1.StaggeringPolicySpec, logger logr.Logger) {
+			policies := make([]StaggeringPolicy, 0)
+			for name, spec := range specs {
+				policies = append(policies, StaggeringPolicy{Name: name, StaggeringPolicySpec: spec})
+			}
+			if err := sources.Set(source, policies, logger); err != nil {
+				logger.Info("failed to update match predicates", "source", source, "error", err)
+			}
+		}
+	}
+
+	logger.Info("registering staggering policy reconcilers")
+	// all replicas serve admission so they all need policies.
+	controllerOptions := crcontroller.Options{NeedLeaderElection: ptr.To(false)}
+	err := builder.ControllerManagedBy(mgr).
+		Named("staggeringpolicy").
+		For(&v1alpha1.StaggeringPolicy{}).
+		WithOptions(controllerOptions).
+		Complete(controller.NewPolicyReconciler(
+			mgr.GetClient(),
+			configurator,
+			groupFactory,
+			changeHandler("staggeringpolicies"),
+			false))
+	if err != nil {
+		return fmt.Errorf("failed to watch for staggering policies: %v", err)
+	}
+	err = builder.ControllerManagedBy(mgr).
+		Named("clusterstaggeringpolicy").
+		For(&v1alpha1.ClusterStaggeringPolicy{}).
+		WithOptions(controllerOptions).
+		Complete(controller.NewPolicyReconciler(
+			mgr.GetClient(),
+			configurator,
+			groupFactory,
+			changeHandler("clusterstaggeringpolicies"),
+			true))
+	if err != nil {
+		return fmt.Errorf("failed to watch for cluster staggering policies: %v", err)
 	}
+
+	return nil
+}
+
+// Get a predicate that matches pods enabled by enableChecker and that may
+// be selected by policies in config.
+func GetMatchLabelsPredicate(options Options, config Config, enableChecker controllertypes.EnableChecker, logger logr.Logger) (predicate.Predicate, error) {
+	enablePredicate := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return enableChecker.IsEnabled(context.TODO(), object.GetNamespace(), object.GetLabels(), logger)
+	})
+
+	keys := make(map[string]bool)
+	keyPredicates := make([]predicate.Predicate, 0)
 	for _, policy := range config.StaggeringPolicies {
-		for label := range policy.LabelSelector {
-			expressions = append(expressions, metav1.LabelSelectorRequirement{
-				Key:      label,
-				Operator: metav1.LabelSelectorOpExists,
-			})
+		policyKeys := requiredLabelKeys(policy.LabelSelector.AsLabelSelector())
+		if len(policyKeys) == 0 {
+			// policy matches all enabled pods so there's no point
+			// in filtering by keys.
+			logger.Info("policy has no required labels, matching all enabled pods", "policy", policy.Name)
+			return enablePredicate, nil
 		}
+		for _, label := range policyKeys {
+			if keys[label] {
+				continue
+			}
+			keys[label] = true
+			keyPredicate, err := predicate.LabelSelectorPredicate(
+				metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{
+							Key:      label,
+							Operator: metav1.LabelSelectorOpExists,
+						},
+					},
+				})
+			if err != nil {
+				return nil, err
+			}
+			keyPredicates = append(keyPredicates, keyPredicate)
+		}
+	}
+
+	logger.Info("extracted reconciliation match label keys", "enableLabel", options.EnableLabel, "keys", keys)
+	if len(keyPredicates) == 0 {
+		return enablePredicate, nil
 	}
 
-	logger.Info("extracted reconciliation match expressions", "expression", expressions)
+	return predicate.And(enablePredicate, predicate.Or(keyPredicates...)), nil
+}
 
-	return predicate.LabelSelectorPredicate(
-		metav1.LabelSelector{
-			MatchExpressions: expressions,
-		})
+// Get label keys that must exist on pods matched by selector.
+func requiredLabelKeys(selector *metav1.LabelSelector) []string {
+	if selector == nil {
+		return nil
+	}
+	keys := make([]string, 0)
+	for key := range selector.MatchLabels {
+		keys = append(keys, key)
+	}
+	for _, requirement := range selector.MatchExpressions {
+		switch requirement.Operator {
+		case metav1.LabelSelectorOpIn, metav1.LabelSelectorOpExists:
+			keys = append(keys, requirement.Key)
+		}
+	}
+
+	return keys
 }
 
 func CreateKubernetesConfig(opts KubernetesOptions) (*rest.Config, error) {
@@ -238,10 +657,74 @@ func CreateKubernetesConfig(opts KubernetesOptions) (*rest.Config, error) {
 	return config, err
 }
 
-func NewBlocker(opts Options) (blockertypes.PodBlocker, error) {
-	if len(opts.StaggerContainerImage) == 0 {
-		return nil, fmt.Errorf("straggler container image must be specified")
+// Create the pod blocker selected in opts. client is used by blockers that
+// keep state outside of pods.
+func NewBlocker(opts Options, client client.Client) (blockertypes.PodBlocker, error) {
+	switch opts.Blocker {
+	case BlockerStubPod:
+		if len(opts.StaggerContainerImage) == 0 {
+			return nil, fmt.Errorf("straggler container image must be specified")
+		}
+		return blocker.NewStubPod(opts.StaggerContainerImage, client), nil
+	case BlockerSchedulingGates:
+		return blocker.NewSchedulingGatesPodBlocker(), nil
+	default:
+		return nil, fmt.Errorf("unknown blocker: %s", opts.Blocker)
+	}
+}
+
+// Create all supported unblockers keyed by name along with the default one.
+// If no default unblocker is set in opts then it is selected based on
+// the configured blocker.
+func NewUnblockers(opts Options, client client.Client, blocker blockertypes.PodBlocker) (unblockertypes.PodUnblocker, map[string]unblockertypes.PodUnblocker, error) {
+	unblockers := map[string]unblockertypes.PodUnblocker{}
+	evict := unblocker.NewEvict(client)
+	for _, u := range []unblockertypes.PodUnblocker{
+		evict,
+		unblocker.NewDelete(client),
+		// blockers that cannot be removed in place fall back to eviction.
+		unblocker.NewPatchRemoveGate(client, blocker, evict),
+		unblocker.NewPatchAnnotation(client),
+	} {
+		unblockers[u.Name()] = u
+	}
+
+	name := opts.Unblocker
+	if len(name) == 0 {
+		switch opts.Blocker {
+		case BlockerSchedulingGates:
+			name = unblocker.PatchRemoveGate
+		default:
+			name = unblocker.Evict
+		}
+	}
+	defaultUnblocker, ok := unblockers[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown unblocker: %s", name)
+	}
+
+	return defaultUnblocker, unblockers, nil
+}
+
+// Create unblockers for pods without owning controllers keyed by the name
+// of the unblocker they replace. Pods released by eviction or deletion are
+// recreated unless disabled in opts. Recreated pods are only let through
+// admission when made by the straggler service account, so pods are not
+// recreated without one.
+func NewOwnerlessUnblockers(opts Options, client client.Client, blocker blockertypes.PodBlocker, unblockers map[string]unblockertypes.PodUnblocker) map[string]unblockertypes.PodUnblocker {
+	ownerless := map[string]unblockertypes.PodUnblocker{}
+	if !opts.RecreateOwnerlessPods || len(opts.ServiceAccount) == 0 {
+		return ownerless
+	}
+	for _, name := range []string{unblocker.Evict, unblocker.Delete} {
+		if remover, ok := unblockers[name]; ok {
+			ownerless[name] = unblocker.NewRecreate(client, blocker, remover)
+		}
+	}
+	// ownerless pods that cannot be unblocked in place are recreated too.
+	if recreate, ok := ownerless[unblocker.Evict]; ok {
+		ownerless[unblocker.PatchRemoveGate] = unblocker.NewPatchRemoveGate(client, blocker, recreate)
 	}
 
-	return blocker.NewStubPod(opts.StaggerContainerImage), nil
+	return ownerless
 }
@@ -0,0 +1,147 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"straggler/pkg/apis/v1alpha1"
+	"straggler/pkg/controller"
+	"straggler/pkg/controller/mocks"
+	"straggler/pkg/pacer/types"
+	"straggler/pkg/unblocker"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestNewPacerFactoryComposite(t *testing.T) {
+	maxStarting := 2
+	policy := newTestPolicy("test", ".metadata.namespace", 4)
+	policy.Pacer.Concurrency = &ConcurrencyPacer{MaxStarting: &maxStarting}
+	require.Empty(t, Config{StaggeringPolicies: []StaggeringPolicy{policy}}.Validate())
+
+	factory, err := NewPacerFactory(policy, clock.RealClock{}, testr.New(t))
+	require.NoError(t, err)
+
+	// exponential allows 4 but concurrency caps at 2
+	allowed, err := factory.New("key").Pace(types.PodClassification{
+		Blocked: []corev1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{UID: "uid0"}},
+			{ObjectMeta: metav1.ObjectMeta{UID: "uid1"}},
+			{ObjectMeta: metav1.ObjectMeta{UID: "uid2"}},
+			{ObjectMeta: metav1.ObjectMeta{UID: "uid3"}},
+			{ObjectMeta: metav1.ObjectMeta{UID: "uid4"}},
+		},
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 2)
+}
+
+func TestNewPacerFactorySchedule(t *testing.T) {
+	config, err := LoadConfigFromString(`
+staggeringPolicies:
+- name: schedule
+  groupingExpression: .metadata.namespace
+  pacer:
+    schedule:
+      timeZone: Europe/Berlin
+      windows:
+      - days: [Sat, Sun]
+        start: "00:00"
+        end: "00:00"
+        pacer:
+          concurrency:
+            maxStarting: 1
+`, testr.New(t))
+	require.NoError(t, err)
+	require.Empty(t, config.Validate())
+
+	// saturday
+	clock := clocktesting.NewFakeClock(time.Date(2024, 6, 8, 12, 0, 0, 0, time.UTC))
+	factory, err := NewPacerFactory(config.StaggeringPolicies[0], clock, testr.New(t))
+	require.NoError(t, err)
+
+	blocked := types.PodClassification{
+		Blocked: []corev1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{UID: "uid0"}},
+			{ObjectMeta: metav1.ObjectMeta{UID: "uid1"}},
+		},
+	}
+	pacer := factory.New("key")
+	allowed, err := pacer.Pace(blocked, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 1)
+
+	// monday, allow all
+	clock.SetTime(time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC))
+	allowed, err = pacer.Pace(blocked, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 2)
+}
+
+func TestGetMatchLabelsPredicate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	namespaces := mocks.NewMockNamespaceLabeler(mockCtrl)
+	namespaces.EXPECT().NamespaceLabels(gomock.Any(), "enabled").Return(map[string]string{controller.DefaultEnableLabel: "1"}, nil).AnyTimes()
+	namespaces.EXPECT().NamespaceLabels(gomock.Any(), "other").Return(nil, nil).AnyTimes()
+
+	options := NewOptions()
+	policy := newTestPolicy("test", ".metadata.namespace", 1)
+	policy.LabelSelector = &v1alpha1.LabelSelector{
+		LabelSelector: metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: metav1.LabelSelectorOpExists},
+			},
+		},
+	}
+	p, err := GetMatchLabelsPredicate(
+		options,
+		Config{StaggeringPolicies: []StaggeringPolicy{policy}},
+		controller.NewEnableChecker(options.EnableLabel, namespaces),
+		testr.New(t))
+	require.NoError(t, err)
+
+	newPod := func(namespace string, labels map[string]string) event.CreateEvent {
+		return event.CreateEvent{Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Labels: labels}}}
+	}
+	require.True(t, p.Create(newPod("enabled", map[string]string{"tier": "web"})))
+	require.True(t, p.Create(newPod("other", map[string]string{"tier": "web", options.EnableLabel: "1"})))
+	require.False(t, p.Create(newPod("enabled", map[string]string{"tier": "web", options.EnableLabel: "0"})))
+	require.False(t, p.Create(newPod("enabled", map[string]string{"app": "web"})))
+	require.False(t, p.Create(newPod("other", map[string]string{"tier": "web"})))
+}
+
+func TestNewOwnerlessUnblockers(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client := mocks.NewMockClient(mockCtrl)
+	options := NewOptions()
+	_, unblockers, err := NewUnblockers(options, client, nil)
+	require.NoError(t, err)
+
+	// ownerless pods are not recreated by default.
+	options.ServiceAccount = "system:serviceaccount:straggler:straggler"
+	require.Empty(t, NewOwnerlessUnblockers(options, client, nil, unblockers))
+
+	// recreated pods are only let through admission with a service account.
+	options.RecreateOwnerlessPods = true
+	options.ServiceAccount = ""
+	require.Empty(t, NewOwnerlessUnblockers(options, client, nil, unblockers))
+
+	options.ServiceAccount = "system:serviceaccount:straggler:straggler"
+	ownerless := NewOwnerlessUnblockers(options, client, nil, unblockers)
+	require.Contains(t, ownerless, unblocker.Evict)
+	require.Contains(t, ownerless, unblocker.Delete)
+}
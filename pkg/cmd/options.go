@@ -10,6 +10,21 @@ import (
 	"k8s.io/client-go/rest"
 )
 
+const (
+	// Block pods by replacing their specs with stubs. Pods are released
+	// by eviction.
+	BlockerStubPod = "stubpod"
+	// Block pods using scheduling gates. Pods are released in place.
+	BlockerSchedulingGates = "schedulinggates"
+
+	// Do not validate pods after admission.
+	ValidationOff = "off"
+	// Return validation violations as admission warnings.
+	ValidationWarn = controller.ValidationModeWarn
+	// Deny creation of pods with validation violations.
+	ValidationDeny = controller.ValidationModeDeny
+)
+
 type LeaderElectionOptions struct {
 	LeaderElection   bool   `cliArgName:"kubernetes-leader-election" cliArgDescription:"enable leader election" cliArgGroup:"Kubernetes"`
 	LeaderElectionID string `cliArgName:"kubernetes-leader-election-id" cliArgDescription:"id to use for kubernetes leader election" cliArgGroup:"Kubernetes"`
@@ -29,16 +44,32 @@ type KubernetesOptions struct {
 type Options struct {
 	KubernetesOptions
 
-	StaggeringConfigPath   string        `cliArgName:"staggering-config-path" cliArgDescription:"path to staggering config yaml file" cliArgGroup:"Staggering"`
-	StaggerContainerImage  string        `cliArgName:"staggering-container-image" cliArgDescription:"straggler container image to use for stub pods" cliArgGroup:"Staggering"`
-	BypassFailure          bool          `cliArgName:"staggering-bypass-errors" cliArgDescription:"do not block admission on errors" cliArgGroup:"Staggering"`
-	EnableLabel            string        `cliArgName:"staggering-enable-label" cliArgDescription:"pod label to enable staggering behavior" cliArgGroup:"Staggering"`
-	MaxFlightDuration      time.Duration `cliArgName:"staggering-max-pod-flight-duration" cliArgDescription:"maximum time to wait for a pod from admission to reconciliation after which it is assumed committed" cliArgGroup:"Staggering"`
-	TLSDir                 string        `cliArgName:"tls-dir" cliArgDescription:"dir to look for tls pem files" cliArgGroup:"TLS"`
-	TLSKeyFilename         string        `cliArgName:"tls-key-filename" cliArgDescription:"path to tls key pem" cliArgGroup:"TLS"`
-	TLSCertFilename        string        `cliArgName:"tls-cert-filename" cliArgDescription:"path to tls certificate pem" cliArgGroup:"TLS"`
-	TLSListenPort          int           `cliArgName:"tls-port" cliArgDescription:"port to listen on for webhook admission requests" cliArgGroup:"TLS"`
-	HealthProbeBindAddress string        `cliArgName:"health-probe-bind-address" cliArgDescription:"address to bind on for http health server" cliArgGroup:"Health"`
+	StaggeringConfigPath           string        `cliArgName:"staggering-config-path" cliArgDescription:"path to staggering config yaml file" cliArgGroup:"Staggering"`
+	StaggeringConfigReloadInterval time.Duration `cliArgName:"staggering-config-reload-interval" cliArgDescription:"interval to reload staggering config file in case changes are missed, changes are otherwise picked up right away by watching its directory, 0 to disable reloading" cliArgGroup:"Staggering"`
+	EnablePolicyCRDs               bool          `cliArgName:"staggering-policy-crds" cliArgDescription:"watch StaggeringPolicy and ClusterStaggeringPolicy resources for policies" cliArgGroup:"Staggering"`
+	Blocker                        string        `cliArgName:"staggering-blocker" cliArgDescription:"pod blocker to use: stubpod or schedulinggates" cliArgGroup:"Staggering"`
+	Unblocker                      string        `cliArgName:"staggering-unblocker" cliArgDescription:"default pod unblocking strategy: evict, delete, patch-remove-gate or patch-annotation. Empty to select by blocker" cliArgGroup:"Staggering"`
+	StaggerContainerImage          string        `cliArgName:"staggering-container-image" cliArgDescription:"straggler container image to use for stub pods" cliArgGroup:"Staggering"`
+	BypassFailure                  bool          `cliArgName:"staggering-bypass-errors" cliArgDescription:"do not block admission on errors" cliArgGroup:"Staggering"`
+	EnableLabel                    string        `cliArgName:"staggering-enable-label" cliArgDescription:"pod label to enable staggering behavior" cliArgGroup:"Staggering"`
+	EnableNamespaces               bool          `cliArgName:"staggering-enable-namespaces" cliArgDescription:"enable staggering for pods in namespaces with the enable label" cliArgGroup:"Staggering"`
+	RecreateOwnerlessPods          bool          `cliArgName:"staggering-recreate-ownerless-pods" cliArgDescription:"recreate pods without owning controllers when they are released by eviction or deletion, requires staggering-service-account" cliArgGroup:"Staggering"`
+	ServiceAccount                 string        `cliArgName:"staggering-service-account" cliArgDescription:"username of straggler service account, only it is allowed to change straggler managed fields of pods. Empty to not protect them" cliArgGroup:"Staggering"`
+	Validation                     string        `cliArgName:"staggering-validation" cliArgDescription:"validation of straggler labels of admitted pods: off, warn or deny" cliArgGroup:"Staggering"`
+	MaxFlightDuration              time.Duration `cliArgName:"staggering-max-pod-flight-duration" cliArgDescription:"maximum time to wait for a pod from admission to reconciliation after which it is assumed committed" cliArgGroup:"Staggering"`
+	TLSDir                         string        `cliArgName:"tls-dir" cliArgDescription:"dir to look for tls pem files" cliArgGroup:"TLS"`
+	TLSKeyFilename                 string        `cliArgName:"tls-key-filename" cliArgDescription:"path to tls key pem" cliArgGroup:"TLS"`
+	TLSCertFilename                string        `cliArgName:"tls-cert-filename" cliArgDescription:"path to tls certificate pem" cliArgGroup:"TLS"`
+	TLSListenPort                  int           `cliArgName:"tls-port" cliArgDescription:"port to listen on for webhook admission requests" cliArgGroup:"TLS"`
+	HealthProbeBindAddress         string        `cliArgName:"health-probe-bind-address" cliArgDescription:"address to bind on for http health server" cliArgGroup:"Health"`
+}
+
+type SimulateOptions struct {
+	StaggeringConfigPath string        `cliArgName:"staggering-config-path" cliArgDescription:"path to staggering config yaml file" cliArgGroup:"Staggering"`
+	WorkloadPath         string        `cliArgName:"workload-path" cliArgDescription:"path to synthetic workload yaml file" cliArgGroup:"Simulation"`
+	Step                 time.Duration `cliArgName:"step" cliArgDescription:"virtual clock step" cliArgGroup:"Simulation"`
+	MaxDuration          time.Duration `cliArgName:"max-duration" cliArgDescription:"maximum virtual time to simulate" cliArgGroup:"Simulation"`
+	Seed                 int           `cliArgName:"seed" cliArgDescription:"seed for random pod startup durations" cliArgGroup:"Simulation"`
 }
 
 func NewKubernetesOptions() KubernetesOptions {
@@ -57,15 +88,25 @@ func NewLeaderElectionOptions() LeaderElectionOptions {
 
 func NewOptions() Options {
 	return Options{
-		KubernetesOptions:      NewKubernetesOptions(),
-		StaggerContainerImage:  "technicianted/stagger",
-		BypassFailure:          true,
-		EnableLabel:            controller.DefaultEnableLabel,
-		MaxFlightDuration:      1000 * time.Millisecond,
-		TLSDir:                 ".",
-		TLSKeyFilename:         "tls.key",
-		TLSCertFilename:        "tls.crt",
-		TLSListenPort:          9443,
-		HealthProbeBindAddress: ":9444",
+		KubernetesOptions:              NewKubernetesOptions(),
+		StaggeringConfigReloadInterval: 10 * time.Second,
+		Blocker:                        BlockerStubPod,
+		StaggerContainerImage:          "technicianted/stagger",
+		BypassFailure:                  true,
+		EnableLabel:                    controller.DefaultEnableLabel,
+		Validation:                     ValidationOff,
+		MaxFlightDuration:              1000 * time.Millisecond,
+		TLSDir:                         ".",
+		TLSKeyFilename:                 "tls.key",
+		TLSCertFilename:                "tls.crt",
+		TLSListenPort:                  9443,
+		HealthProbeBindAddress:         ":9444",
+	}
+}
+
+func NewSimulateOptions() SimulateOptions {
+	return SimulateOptions{
+		Step:        100 * time.Millisecond,
+		MaxDuration: time.Hour,
 	}
 }
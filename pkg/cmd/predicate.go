@@ -0,0 +1,107 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package cmd
+
+import (
+	"sort"
+	"sync"
+
+	controllertypes "straggler/pkg/controller/types"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+var _ predicate.Predicate = &reloadablePredicate{}
+
+// A predicate that delegates to an inner predicate that can be replaced
+// at runtime. Controllers are built with predicates once so this allows
+// updating their filtering when configs change.
+type reloadablePredicate struct {
+	sync.RWMutex
+
+	predicate predicate.Predicate
+}
+
+func newReloadablePredicate(p predicate.Predicate) *reloadablePredicate {
+	return &reloadablePredicate{
+		predicate: p,
+	}
+}
+
+func (r *reloadablePredicate) Set(p predicate.Predicate) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.predicate = p
+}
+
+func (r *reloadablePredicate) get() predicate.Predicate {
+	r.RLock()
+	defer r.RUnlock()
+
+	return r.predicate
+}
+
+func (r *reloadablePredicate) Create(e event.CreateEvent) bool {
+	return r.get().Create(e)
+}
+
+func (r *reloadablePredicate) Delete(e event.DeleteEvent) bool {
+	return r.get().Delete(e)
+}
+
+func (r *reloadablePredicate) Update(e event.UpdateEvent) bool {
+	return r.get().Update(e)
+}
+
+func (r *reloadablePredicate) Generic(e event.GenericEvent) bool {
+	return r.get().Generic(e)
+}
+
+// Tracks policies from multiple sources, such as config files and custom
+// resources, to maintain a single match predicate for all of them.
+type policySources struct {
+	sync.Mutex
+
+	options       Options
+	enableChecker controllertypes.EnableChecker
+	predicate     *reloadablePredicate
+	policies      map[string][]StaggeringPolicy
+}
+
+func newPolicySources(options Options, enableChecker controllertypes.EnableChecker, predicate *reloadablePredicate) *policySources {
+	return &policySources{
+		options:       options,
+		enableChecker: enableChecker,
+		predicate:     predicate,
+		policies:      make(map[string][]StaggeringPolicy),
+	}
+}
+
+// Set policies of source and rebuild match predicate.
+func (p *policySources) Set(source string, policies []StaggeringPolicy, logger logr.Logger) error {
+	p.Lock()
+	defer p.Unlock()
+
+	p.policies[source] = policies
+
+	sources := make([]string, 0)
+	for source := range p.policies {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	config := Config{}
+	for _, source := range sources {
+		config.StaggeringPolicies = append(config.StaggeringPolicies, p.policies[source]...)
+	}
+
+	predicate, err := GetMatchLabelsPredicate(p.options, config, p.enableChecker, logger)
+	if err != nil {
+		return err
+	}
+	p.predicate.Set(predicate)
+
+	return nil
+}
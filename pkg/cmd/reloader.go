@@ -0,0 +1,223 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	controllertypes "straggler/pkg/controller/types"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	configFilePolicySource = "file"
+)
+
+var (
+	_ manager.Runnable               = &configReloader{}
+	_ manager.LeaderElectionRunnable = &configReloader{}
+)
+
+// Reloads staggering policies from a config file when it changes and applies
+// changes to a running classifier. The config file directory is watched so
+// that updates of mounted config maps, which swap a symlink in it, are
+// picked up right away. The file is also reloaded periodically in case
+// file events are missed.
+type configReloader struct {
+	options      Options
+	configurator controllertypes.PodClassifierConfigurator
+	sources      *policySources
+	config       Config
+	clock        clock.PassiveClock
+	logger       logr.Logger
+}
+
+// Create a new config reloader that watches options.StaggeringConfigPath
+// starting with config as the currently applied one.
+func newConfigReloader(
+	options Options,
+	config Config,
+	configurator controllertypes.PodClassifierConfigurator,
+	sources *policySources,
+	clock clock.PassiveClock,
+	logger logr.Logger,
+) *configReloader {
+	return &configReloader{
+		options:      options,
+		configurator: configurator,
+		sources:      sources,
+		config:       config,
+		clock:        clock,
+		logger:       logger.WithName("reloader"),
+	}
+}
+
+// All replicas serve admission requests so they all need to reload.
+func (r *configReloader) NeedLeaderElection() bool {
+	return false
+}
+
+func (r *configReloader) Start(ctx context.Context) error {
+	r.logger.Info("starting config reloader", "path", r.options.StaggeringConfigPath, "interval", r.options.StaggeringConfigReloadInterval)
+
+	// nil channels block forever if the watcher cannot be created.
+	var events chan fsnotify.Event
+	var watchErrors chan error
+	watcher, err := r.newWatcher()
+	if err != nil {
+		r.logger.Info("failed to watch config file, only reloading periodically", "error", err)
+	} else {
+		defer watcher.Close()
+		events = watcher.Events
+		watchErrors = watcher.Errors
+	}
+
+	ticker := time.NewTicker(r.options.StaggeringConfigReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("config reloader terminated")
+			return nil
+		case event := <-events:
+			// config map updates change other files in the directory
+			// so any event may change the config file.
+			r.logger.V(1).Info("config directory changed", "event", event)
+		case err := <-watchErrors:
+			r.logger.Info("failed to watch config file", "error", err)
+			continue
+		case <-ticker.C:
+		}
+		if err := r.Reload(r.logger); err != nil {
+			r.logger.Info("failed to reload config", "error", err)
+		}
+	}
+}
+
+// Create a watcher of the directory of the config file. The directory is
+// watched instead of the file since the file may be replaced.
+func (r *configReloader) newWatcher() (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(filepath.Dir(r.options.StaggeringConfigPath)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	return watcher, nil
+}
+
+// Reload config file and apply any changes. Policies that fail to apply
+// are retried with the next reload.
+func (r *configReloader) Reload(logger logr.Logger) error {
+	config, err := LoadConfig(r.options.StaggeringConfigPath, logger.V(1))
+	if err != nil {
+		return fmt.Errorf("failed to load config: %v", err)
+	}
+	if errs := config.Validate(); len(errs) > 0 {
+		return fmt.Errorf("invalid config: %v", errs.ToAggregate())
+	}
+
+	applied, applyErr := ApplyConfigChanges(r.configurator, r.config, config, r.clock, logger)
+	if reflect.DeepEqual(applied, r.config) {
+		return applyErr
+	}
+	r.config = applied
+
+	if err := r.sources.Set(configFilePolicySource, r.config.StaggeringPolicies, logger); err != nil {
+		return fmt.Errorf("failed to get match predicates: %v", err)
+	}
+
+	return applyErr
+}
+
+// Apply the differences between current and desired policies to configurator
+// using clock for pacers of new and changed policies.
+// Returns the config that is actually applied, which is equal to desired
+// when all changes are applied successfully.
+func ApplyConfigChanges(
+	configurator controllertypes.PodClassifierConfigurator,
+	current Config,
+	desired Config,
+	clock clock.PassiveClock,
+	logger logr.Logger,
+) (Config, error) {
+	added, removed, changed, err := DiffConfigs(current, desired)
+	if err != nil {
+		return current, err
+	}
+	if len(added) == 0 && len(removed) == 0 && len(changed) == 0 {
+		return current, nil
+	}
+	logger.Info("applying config changes", "added", len(added), "removed", len(removed), "changed", len(changed))
+
+	applied := make(map[string]StaggeringPolicy)
+	for _, policy := range current.StaggeringPolicies {
+		applied[policy.Name] = policy
+	}
+	var errs []error
+	// removals go first to release names and grouping expressions
+	// that may be reused by other changes.
+	for _, policy := range removed {
+		logger.Info("removing policy", "policy", policy.Name)
+		if err := configurator.RemoveConfig(policy.Name, logger); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove policy %s: %v", policy.Name, err))
+			continue
+		}
+		delete(applied, policy.Name)
+	}
+	for _, policy := range changed {
+		logger.Info("updating policy", "policy", policy.Name)
+		group, err := NewStaggerGroup(policy, clock, logger)
+		if err == nil {
+			err = configurator.UpdateConfig(group, logger)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to update policy %s: %v", policy.Name, err))
+			continue
+		}
+		applied[policy.Name] = policy
+	}
+	for _, policy := range added {
+		logger.Info("adding policy", "policy", policy.Name)
+		group, err := NewStaggerGroup(policy, clock, logger)
+		if err == nil {
+			err = configurator.AddConfig(group, logger)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to add policy %s: %v", policy.Name, err))
+			continue
+		}
+		applied[policy.Name] = policy
+	}
+
+	// maintain desired ordering of policies
+	result := Config{}
+	for _, policy := range desired.StaggeringPolicies {
+		if p, ok := applied[policy.Name]; ok {
+			result.StaggeringPolicies = append(result.StaggeringPolicies, p)
+			delete(applied, policy.Name)
+		}
+	}
+	// policies that failed to be removed
+	for _, policy := range current.StaggeringPolicies {
+		if p, ok := applied[policy.Name]; ok {
+			result.StaggeringPolicies = append(result.StaggeringPolicies, p)
+		}
+	}
+
+	if len(errs) > 0 {
+		return result, fmt.Errorf("failed to apply some policies: %v", errs)
+	}
+	return result, nil
+}
@@ -0,0 +1,199 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"straggler/pkg/apis/v1alpha1"
+	"straggler/pkg/controller"
+	"straggler/pkg/controller/mocks"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+func newTestPolicy(name, expression string, minInitial int) StaggeringPolicy {
+	maxStagger := 10
+	multiplier := 2.0
+	policy := StaggeringPolicy{Name: name}
+	policy.LabelSelector = v1alpha1.LabelSelectorFromSet(map[string]string{name: "1"})
+	policy.GroupingExpression = expression
+	policy.Pacer.Exponential = &ExponentialPacer{
+		MinInitial: &minInitial,
+		MaxStagger: &maxStagger,
+		Multiplier: &multiplier,
+	}
+	return policy
+}
+
+func TestDiffConfigs(t *testing.T) {
+	current := Config{
+		StaggeringPolicies: []StaggeringPolicy{
+			newTestPolicy("unchanged", ".metadata.namespace", 1),
+			newTestPolicy("changed", ".metadata.name", 1),
+			newTestPolicy("removed", ".spec.nodeName", 1),
+		},
+	}
+	desired := Config{
+		StaggeringPolicies: []StaggeringPolicy{
+			newTestPolicy("unchanged", ".metadata.namespace", 1),
+			newTestPolicy("changed", ".metadata.name", 2),
+			newTestPolicy("added", ".spec.schedulerName", 1),
+		},
+	}
+
+	added, removed, changed, err := DiffConfigs(current, desired)
+	require.NoError(t, err)
+	require.Len(t, added, 1)
+	require.Equal(t, "added", added[0].Name)
+	require.Len(t, removed, 1)
+	require.Equal(t, "removed", removed[0].Name)
+	require.Len(t, changed, 1)
+	require.Equal(t, "changed", changed[0].Name)
+
+	desired.StaggeringPolicies = append(desired.StaggeringPolicies, newTestPolicy("added", ".spec.hostname", 1))
+	_, _, _, err = DiffConfigs(current, desired)
+	require.Error(t, err)
+}
+
+func TestApplyConfigChanges(t *testing.T) {
+	logger := testr.New(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	current := Config{
+		StaggeringPolicies: []StaggeringPolicy{
+			newTestPolicy("changed", ".metadata.name", 1),
+			newTestPolicy("removed", ".spec.nodeName", 1),
+		},
+	}
+	desired := Config{
+		StaggeringPolicies: []StaggeringPolicy{
+			newTestPolicy("changed", ".metadata.name", 2),
+			newTestPolicy("added", ".spec.schedulerName", 1),
+		},
+	}
+
+	configurator := mocks.NewMockPodClassifierConfigurator(mockCtrl)
+	gomock.InOrder(
+		configurator.EXPECT().RemoveConfig("removed", gomock.Any()).Return(nil),
+		configurator.EXPECT().UpdateConfig(gomock.Any(), gomock.Any()).Return(nil),
+		configurator.EXPECT().AddConfig(gomock.Any(), gomock.Any()).Return(nil),
+	)
+	applied, err := ApplyConfigChanges(configurator, current, desired, clock.RealClock{}, logger)
+	require.NoError(t, err)
+	require.Equal(t, desired, applied)
+
+	// failed additions are not part of applied config
+	configurator.EXPECT().AddConfig(gomock.Any(), gomock.Any()).Return(errors.New("failed"))
+	applied, err = ApplyConfigChanges(configurator, current, Config{
+		StaggeringPolicies: append(current.StaggeringPolicies, newTestPolicy("added", ".spec.schedulerName", 1)),
+	}, clock.RealClock{}, logger)
+	require.Error(t, err)
+	require.Equal(t, current, applied)
+}
+
+func TestConfigReloaderReload(t *testing.T) {
+	logger := testr.New(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+staggeringPolicies:
+  - name: "policy1"
+    labelSelector:
+      app: "my-app"
+    groupingExpression: ".metadata.namespace"
+    pacer:
+      linear:
+        maxStagger: 10
+        step: 2
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	options := NewOptions()
+	options.StaggeringConfigPath = configPath
+	configurator := mocks.NewMockPodClassifierConfigurator(mockCtrl)
+	sources := newPolicySources(options, controller.NewEnableChecker(options.EnableLabel, nil), newReloadablePredicate(predicate.Funcs{}))
+	reloader := newConfigReloader(options, Config{}, configurator, sources, clock.RealClock{}, logger)
+
+	configurator.EXPECT().AddConfig(gomock.Any(), gomock.Any()).Return(nil)
+	require.NoError(t, reloader.Reload(logger))
+	require.Len(t, reloader.config.StaggeringPolicies, 1)
+
+	// no changes, no calls
+	require.NoError(t, reloader.Reload(logger))
+}
+
+func TestConfigReloaderWatch(t *testing.T) {
+	logger := testr.New(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// mounted config maps keep files in a versioned directory and swap a
+	// ..data symlink to it on updates.
+	dir := t.TempDir()
+	writeVersion := func(version, content string) {
+		versionDir := filepath.Join(dir, version)
+		require.NoError(t, os.Mkdir(versionDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(versionDir, "config.yaml"), []byte(content), 0644))
+		require.NoError(t, os.Symlink(version, filepath.Join(dir, "..data_tmp")))
+		require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+	}
+	writeVersion("..v1", "staggeringPolicies: []\n")
+	configPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.Symlink(filepath.Join("..data", "config.yaml"), configPath))
+
+	options := NewOptions()
+	options.StaggeringConfigPath = configPath
+	// changes are picked up long before the next periodic reload.
+	options.StaggeringConfigReloadInterval = time.Hour
+	configurator := mocks.NewMockPodClassifierConfigurator(mockCtrl)
+	sources := newPolicySources(options, controller.NewEnableChecker(options.EnableLabel, nil), newReloadablePredicate(predicate.Funcs{}))
+	reloader := newConfigReloader(options, Config{}, configurator, sources, clock.RealClock{}, logger)
+
+	added := make(chan struct{})
+	configurator.EXPECT().AddConfig(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, _ any) error {
+		close(added)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- reloader.Start(ctx)
+	}()
+	defer func() {
+		cancel()
+		require.NoError(t, <-done)
+	}()
+
+	// wait for the watch to be set up before updating.
+	time.Sleep(100 * time.Millisecond)
+	writeVersion("..v2", `
+staggeringPolicies:
+  - name: "policy1"
+    labelSelector:
+      app: "my-app"
+    groupingExpression: ".metadata.namespace"
+    pacer:
+      linear:
+        maxStagger: 10
+        step: 2
+`)
+	select {
+	case <-added:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "config change not picked up")
+	}
+}
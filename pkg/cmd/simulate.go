@@ -0,0 +1,41 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package cmd
+
+import (
+	"fmt"
+
+	"straggler/pkg/simulator"
+
+	"github.com/go-logr/logr"
+)
+
+// Simulate a synthetic workload against staggering policies.
+func Simulate(options SimulateOptions, logger logr.Logger) (simulator.Result, error) {
+	config, err := LoadConfig(options.StaggeringConfigPath, logger)
+	if err != nil {
+		return simulator.Result{}, fmt.Errorf("failed to load configs: %v", err)
+	}
+	if errs := config.Validate(); len(errs) > 0 {
+		return simulator.Result{}, fmt.Errorf("invalid configs: %v", errs.ToAggregate())
+	}
+	workload, err := simulator.LoadWorkload(options.WorkloadPath)
+	if err != nil {
+		return simulator.Result{}, fmt.Errorf("failed to load workload: %v", err)
+	}
+
+	// pacers use the simulation virtual clock. there is no cluster so
+	// namespaces have no labels.
+	clock := simulator.NewClock()
+	classifier, err := NewGroupClassifier(config.StaggeringPolicies, nil, clock, logger)
+	if err != nil {
+		return simulator.Result{}, err
+	}
+
+	sim := simulator.New(classifier, clock, simulator.Options{
+		Step:        options.Step,
+		MaxDuration: options.MaxDuration,
+		Seed:        int64(options.Seed),
+	})
+	return sim.Run(workload, logger)
+}
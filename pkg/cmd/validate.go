@@ -0,0 +1,472 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"straggler/pkg/controller"
+	"straggler/pkg/pacer/ordering"
+	"straggler/pkg/pacer/schedule"
+	"straggler/pkg/unblocker"
+
+	"github.com/go-logr/logr"
+	"github.com/ohler55/ojg/jp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Validate all policies in config and return every problem found.
+func (c Config) Validate() field.ErrorList {
+	errs := field.ErrorList{}
+	policiesPath := field.NewPath("staggeringPolicies")
+
+	names := make(map[string]bool)
+	expressions := make(map[string]string)
+	for i, policy := range c.StaggeringPolicies {
+		path := policyPath(policiesPath, i, policy.Name)
+		if len(policy.Name) > 0 {
+			if names[policy.Name] {
+				errs = append(errs, field.Duplicate(path.Child("name"), policy.Name))
+			}
+			names[policy.Name] = true
+			if isReservedPolicyName(policy.Name) {
+				errs = append(errs, field.Invalid(
+					path.Child("name"),
+					policy.Name,
+					"must not have the form of a policy resource name, <namespace>/<name> or "+controller.ClusterPolicyConfigPrefix+"<name>"))
+			}
+		}
+		for _, expression := range []struct{ child, value string }{
+			{"groupingExpression", policy.GroupingExpression},
+			{"groupingCELExpression", policy.GroupingCELExpression},
+		} {
+			if len(expression.value) == 0 {
+				continue
+			}
+			key := expression.child + "/" + expression.value
+			if other, ok := expressions[key]; ok {
+				errs = append(errs, field.Invalid(
+					path.Child(expression.child),
+					expression.value,
+					"grouping expression already used by policy "+other))
+			} else {
+				expressions[key] = policy.Name
+			}
+		}
+
+		errs = append(errs, ValidateStaggeringPolicy(policy, path)...)
+	}
+	errs = append(errs, validateOverlappingPolicies(c.StaggeringPolicies, policiesPath)...)
+
+	return errs
+}
+
+// Check whether name may clash with the name of a policy resource as returned
+// by controller.PolicyConfigName.
+func isReservedPolicyName(name string) bool {
+	if strings.HasPrefix(name, controller.ClusterPolicyConfigPrefix) {
+		return true
+	}
+	namespace, objectName, ok := strings.Cut(name, "/")
+	return ok &&
+		len(validation.IsDNS1123Label(namespace)) == 0 &&
+		len(validation.IsDNS1123Subdomain(objectName)) == 0
+}
+
+// Check for policies that may select the same pods with conflicting
+// unblockers or readiness. Groups of pods matching several policies use the
+// first policy that sets them, which is rarely intended.
+func validateOverlappingPolicies(policies []StaggeringPolicy, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	for i, policy := range policies {
+		unblockerConflict, readinessConflict := false, false
+		for _, other := range policies[:i] {
+			if !policiesMayOverlap(policy, other) {
+				continue
+			}
+			if !unblockerConflict &&
+				unblocker.IsKnown(policy.Unblocker) && unblocker.IsKnown(other.Unblocker) &&
+				policy.Unblocker != other.Unblocker {
+				unblockerConflict = true
+				errs = append(errs, field.Invalid(
+					policyPath(path, i, policy.Name).Child("unblocker"),
+					policy.Unblocker,
+					fmt.Sprintf("conflicts with unblocker %s of policy %s that may select the same pods", other.Unblocker, other.Name)))
+			}
+			if !readinessConflict &&
+				policy.Readiness != nil && other.Readiness != nil &&
+				*policy.Readiness != *other.Readiness {
+				readinessConflict = true
+				errs = append(errs, field.Invalid(
+					policyPath(path, i, policy.Name).Child("readiness"),
+					*policy.Readiness,
+					fmt.Sprintf("conflicts with readiness of policy %s that may select the same pods", other.Name)))
+			}
+		}
+	}
+
+	return errs
+}
+
+// Check whether two policies may select the same pods. Only namespaces and
+// exact label matches are compared, policies are otherwise assumed to overlap.
+func policiesMayOverlap(policy, other StaggeringPolicy) bool {
+	if len(policy.Namespaces) > 0 && len(other.Namespaces) > 0 &&
+		!slices.ContainsFunc(policy.Namespaces, func(namespace string) bool {
+			return slices.Contains(other.Namespaces, namespace)
+		}) {
+		return false
+	}
+	if policy.LabelSelector == nil || other.LabelSelector == nil {
+		return true
+	}
+	for key, value := range policy.LabelSelector.MatchLabels {
+		if otherValue, ok := other.LabelSelector.MatchLabels[key]; ok && otherValue != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Validate a single policy at path.
+func ValidateStaggeringPolicy(policy StaggeringPolicy, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if len(policy.Name) == 0 {
+		errs = append(errs, field.Required(path.Child("name"), ""))
+	}
+
+	switch {
+	case len(policy.GroupingExpression) == 0 && len(policy.GroupingCELExpression) == 0:
+		errs = append(errs, field.Required(path.Child("groupingExpression"), "one of groupingExpression or groupingCELExpression must be set"))
+	case len(policy.GroupingExpression) > 0 && len(policy.GroupingCELExpression) > 0:
+		errs = append(errs, field.Invalid(path.Child("groupingCELExpression"), policy.GroupingCELExpression, "must not be set with groupingExpression"))
+	case len(policy.GroupingExpression) > 0:
+		if _, err := jp.ParseString(policy.GroupingExpression); err != nil {
+			errs = append(errs, field.Invalid(path.Child("groupingExpression"), policy.GroupingExpression, err.Error()))
+		}
+	default:
+		if err := controller.ValidateCELGroupingExpression(policy.GroupingCELExpression); err != nil {
+			errs = append(errs, field.Invalid(path.Child("groupingCELExpression"), policy.GroupingCELExpression, err.Error()))
+		}
+	}
+
+	errs = append(errs, validateSelectors(policy.LabelSelector.AsLabelSelector(), policy.BypassLabelSelector.AsLabelSelector(), path)...)
+	errs = append(errs, metav1validation.ValidateLabelSelector(
+		policy.NamespaceSelector,
+		metav1validation.LabelSelectorValidationOptions{},
+		path.Child("namespaceSelector"))...)
+	errs = append(errs, validateNamespaces(policy.Namespaces, policy.ExcludedNamespaces, path)...)
+
+	if policy.MaxBlockedDuration.Duration < 0 {
+		errs = append(errs, field.Invalid(path.Child("maxBlockedDuration"), policy.MaxBlockedDuration.Duration.String(), "must not be negative"))
+	}
+
+	if len(policy.Unblocker) > 0 && !unblocker.IsKnown(policy.Unblocker) {
+		errs = append(errs, field.NotSupported(
+			path.Child("unblocker"),
+			policy.Unblocker,
+			[]string{unblocker.Evict, unblocker.Delete, unblocker.PatchRemoveGate, unblocker.PatchAnnotation}))
+	}
+
+	errs = append(errs, validatePacer(policy.Pacer, path.Child("pacer"))...)
+
+	if policy.Ordering != nil {
+		errs = append(errs, validateOrdering(*policy.Ordering, path.Child("ordering"))...)
+	}
+
+	if policy.CircuitBreaker != nil {
+		errs = append(errs, validateCircuitBreaker(*policy.CircuitBreaker, path.Child("circuitBreaker"))...)
+	}
+
+	if policy.Readiness != nil {
+		readinessPath := path.Child("readiness")
+		if policy.Readiness.ContainersRunning && len(policy.Readiness.ConditionType) > 0 {
+			errs = append(errs, field.Invalid(readinessPath.Child("conditionType"), policy.Readiness.ConditionType, "must not be set with containersRunning"))
+		}
+		if policy.Readiness.MinReadySeconds < 0 {
+			errs = append(errs, field.Invalid(readinessPath.Child("minReadySeconds"), policy.Readiness.MinReadySeconds, "must not be negative"))
+		}
+	}
+
+	return errs
+}
+
+func validatePacer(pacer Pacer, path *field.Path) field.ErrorList {
+	errs, count := validateBasePacer(pacer.BasePacer, path)
+	if pacer.Schedule != nil {
+		count++
+		errs = append(errs, validateSchedulePacer(*pacer.Schedule, path.Child("schedule"))...)
+	}
+
+	if count == 0 {
+		errs = append(errs, field.Required(path, "at least one of exponential, linear, rate, concurrency, adaptive or schedule must be set"))
+	}
+
+	return errs
+}
+
+func validateSchedulePacer(pacer SchedulePacer, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if len(pacer.TimeZone) > 0 {
+		if _, err := time.LoadLocation(pacer.TimeZone); err != nil {
+			errs = append(errs, field.Invalid(path.Child("timeZone"), pacer.TimeZone, err.Error()))
+		}
+	}
+
+	if len(pacer.Windows) == 0 {
+		errs = append(errs, field.Required(path.Child("windows"), ""))
+	}
+	for i, window := range pacer.Windows {
+		windowPath := path.Child("windows").Index(i)
+		for j, day := range window.Days {
+			if _, err := schedule.ParseWeekday(day); err != nil {
+				errs = append(errs, field.Invalid(windowPath.Child("days").Index(j), day, err.Error()))
+			}
+		}
+		if _, err := schedule.ParseTimeOfDay(window.Start); err != nil {
+			errs = append(errs, field.Invalid(windowPath.Child("start"), window.Start, err.Error()))
+		}
+		if _, err := schedule.ParseTimeOfDay(window.End); err != nil {
+			errs = append(errs, field.Invalid(windowPath.Child("end"), window.End, err.Error()))
+		}
+		errs = append(errs, validateRequiredBasePacer(window.Pacer, windowPath.Child("pacer"))...)
+	}
+
+	if pacer.Default != nil {
+		errs = append(errs, validateRequiredBasePacer(*pacer.Default, path.Child("default"))...)
+	}
+
+	return errs
+}
+
+func validateRequiredBasePacer(pacer BasePacer, path *field.Path) field.ErrorList {
+	errs, count := validateBasePacer(pacer, path)
+	if count == 0 {
+		errs = append(errs, field.Required(path, "at least one of exponential, linear, rate, concurrency or adaptive must be set"))
+	}
+	return errs
+}
+
+// Validate base pacers and return the number of configured ones.
+func validateBasePacer(pacer BasePacer, path *field.Path) (field.ErrorList, int) {
+	errs := field.ErrorList{}
+
+	count := 0
+	if pacer.Exponential != nil {
+		count++
+		exponentialPath := path.Child("exponential")
+		errs = append(errs, validateMinimum(pacer.Exponential.MinInitial, 1, exponentialPath.Child("minInitial"))...)
+		errs = append(errs, validateMinimum(pacer.Exponential.MaxStagger, 1, exponentialPath.Child("maxStagger"))...)
+		if pacer.Exponential.Multiplier == nil {
+			errs = append(errs, field.Required(exponentialPath.Child("multiplier"), ""))
+		} else if *pacer.Exponential.Multiplier < 1 {
+			errs = append(errs, field.Invalid(exponentialPath.Child("multiplier"), *pacer.Exponential.Multiplier, "must be at least 1"))
+		}
+	}
+	if pacer.Linear != nil {
+		count++
+		linearPath := path.Child("linear")
+		errs = append(errs, validateMinimum(pacer.Linear.MaxStagger, 1, linearPath.Child("maxStagger"))...)
+		errs = append(errs, validateMinimum(pacer.Linear.Step, 1, linearPath.Child("step"))...)
+	}
+	if pacer.Rate != nil {
+		count++
+		ratePath := path.Child("rate")
+		errs = append(errs, validateMinimum(pacer.Rate.Rate, 1, ratePath.Child("rate"))...)
+		if pacer.Rate.Period != nil && pacer.Rate.Period.Duration <= 0 {
+			errs = append(errs, field.Invalid(ratePath.Child("period"), pacer.Rate.Period.Duration.String(), "must be positive"))
+		}
+		if pacer.Rate.Burst != nil {
+			errs = append(errs, validateMinimum(pacer.Rate.Burst, 1, ratePath.Child("burst"))...)
+		}
+	}
+
+	if pacer.Concurrency != nil {
+		count++
+		errs = append(errs, validateMinimum(pacer.Concurrency.MaxStarting, 1, path.Child("concurrency").Child("maxStarting"))...)
+	}
+	if pacer.Adaptive != nil {
+		count++
+		errs = append(errs, validateAdaptivePacer(*pacer.Adaptive, path.Child("adaptive"))...)
+	}
+
+	return errs, count
+}
+
+func validateAdaptivePacer(pacer AdaptivePacer, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if pacer.TargetLatency == nil {
+		errs = append(errs, field.Required(path.Child("targetLatency"), ""))
+	} else if pacer.TargetLatency.Duration <= 0 {
+		errs = append(errs, field.Invalid(path.Child("targetLatency"), pacer.TargetLatency.Duration.String(), "must be positive"))
+	}
+	minWindow := 1
+	if pacer.MinWindow != nil {
+		errs = append(errs, validateMinimum(pacer.MinWindow, 1, path.Child("minWindow"))...)
+		minWindow = *pacer.MinWindow
+	}
+	if pacer.InitialWindow != nil {
+		errs = append(errs, validateMinimum(pacer.InitialWindow, minWindow, path.Child("initialWindow"))...)
+	}
+	errs = append(errs, validateMinimum(pacer.MaxWindow, minWindow, path.Child("maxWindow"))...)
+	if pacer.Increase != nil && *pacer.Increase <= 0 {
+		errs = append(errs, field.Invalid(path.Child("increase"), *pacer.Increase, "must be positive"))
+	}
+	if pacer.DecreaseFactor != nil && (*pacer.DecreaseFactor <= 0 || *pacer.DecreaseFactor >= 1) {
+		errs = append(errs, field.Invalid(path.Child("decreaseFactor"), *pacer.DecreaseFactor, "must be between 0 and 1"))
+	}
+
+	return errs
+}
+
+func validateCircuitBreaker(config CircuitBreaker, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if config.FailureThreshold == nil && config.FailureRatio == nil {
+		errs = append(errs, field.Required(path, "at least one of failureThreshold or failureRatio must be set"))
+	}
+	if config.FailureThreshold != nil {
+		errs = append(errs, validateMinimum(config.FailureThreshold, 1, path.Child("failureThreshold"))...)
+	}
+	if config.FailureRatio != nil && (*config.FailureRatio <= 0 || *config.FailureRatio > 1) {
+		errs = append(errs, field.Invalid(path.Child("failureRatio"), *config.FailureRatio, "must be greater than 0 and at most 1"))
+	}
+	if config.Cooldown != nil && config.Cooldown.Duration <= 0 {
+		errs = append(errs, field.Invalid(path.Child("cooldown"), config.Cooldown.Duration.String(), "must be positive"))
+	}
+
+	return errs
+}
+
+func validateOrdering(config Ordering, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if len(config.Strategy) > 0 && !ordering.IsKnown(config.Strategy) {
+		errs = append(errs, field.NotSupported(
+			path.Child("strategy"),
+			config.Strategy,
+			[]string{ordering.FIFO, ordering.Priority}))
+	}
+
+	if fairness := config.Fairness; fairness != nil {
+		fairnessPath := path.Child("fairness")
+		if !ordering.IsKnownFairnessMode(fairness.Mode) {
+			errs = append(errs, field.NotSupported(
+				fairnessPath.Child("mode"),
+				fairness.Mode,
+				[]string{ordering.RoundRobin, ordering.DRF}))
+		}
+		if len(fairness.By) > 0 && !ordering.IsKnownFairnessKey(fairness.By) {
+			errs = append(errs, field.NotSupported(
+				fairnessPath.Child("by"),
+				fairness.By,
+				[]string{ordering.ByNamespace, ordering.ByOwner}))
+		}
+		for key, weight := range fairness.Weights {
+			if weight < 1 {
+				errs = append(errs, field.Invalid(fairnessPath.Child("weights").Key(key), weight, "must be at least 1"))
+			}
+		}
+	}
+
+	return errs
+}
+
+func validateMinimum(value *int, minimum int, path *field.Path) field.ErrorList {
+	if value == nil {
+		return field.ErrorList{field.Required(path, "")}
+	}
+	if *value < minimum {
+		return field.ErrorList{field.Invalid(path, *value, fmt.Sprintf("must be at least %d", minimum))}
+	}
+	return nil
+}
+
+// Check selectors are valid, and for bypass selectors that either never match
+// or always match pods selected by the label selector.
+func validateSelectors(selector, bypassSelector *metav1.LabelSelector, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	errs = append(errs, metav1validation.ValidateLabelSelector(
+		selector,
+		metav1validation.LabelSelectorValidationOptions{},
+		path.Child("labelSelector"))...)
+	errs = append(errs, metav1validation.ValidateLabelSelector(
+		bypassSelector,
+		metav1validation.LabelSelectorValidationOptions{},
+		path.Child("bypassLabelSelector"))...)
+	if len(errs) > 0 || bypassSelector == nil ||
+		(len(bypassSelector.MatchLabels) == 0 && len(bypassSelector.MatchExpressions) == 0) {
+		return errs
+	}
+
+	var selectorLabels map[string]string
+	if selector != nil {
+		selectorLabels = selector.MatchLabels
+	}
+	// only exact label matches can be compared.
+	bypassAll := len(bypassSelector.MatchExpressions) == 0
+	for key, value := range bypassSelector.MatchLabels {
+		selectorValue, ok := selectorLabels[key]
+		if !ok {
+			bypassAll = false
+			continue
+		}
+		if selectorValue != value {
+			return field.ErrorList{field.Invalid(
+				path.Child("bypassLabelSelector").Key(key),
+				value,
+				"conflicts with labelSelector value "+selectorValue+", bypass selector never matches")}
+		}
+	}
+	if bypassAll {
+		return field.ErrorList{field.Invalid(
+			path.Child("bypassLabelSelector"),
+			bypassSelector.MatchLabels,
+			"matches all pods selected by labelSelector, policy never applies")}
+	}
+
+	return nil
+}
+
+// Check for namespaces that are both included and excluded.
+func validateNamespaces(namespaces, excludedNamespaces []string, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	for i, namespace := range excludedNamespaces {
+		if slices.Contains(namespaces, namespace) {
+			errs = append(errs, field.Invalid(
+				path.Child("excludedNamespaces").Index(i),
+				namespace,
+				"namespace is also in namespaces"))
+		}
+	}
+
+	return errs
+}
+
+func policyPath(path *field.Path, index int, name string) *field.Path {
+	if len(name) == 0 {
+		return path.Index(index)
+	}
+	return path.Key(name)
+}
+
+// Load and validate a config file. An error is returned only if the file
+// cannot be loaded.
+func ValidateConfigFile(path string, logger logr.Logger) (field.ErrorList, error) {
+	config, err := LoadConfig(path, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.Validate(), nil
+}
@@ -0,0 +1,214 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/clock"
+)
+
+func TestConfigValidate(t *testing.T) {
+	config := Config{
+		StaggeringPolicies: []StaggeringPolicy{
+			newTestPolicy("valid", ".metadata.namespace", 1),
+		},
+	}
+	require.Empty(t, config.Validate())
+
+	config, err := LoadConfigFromString(`
+staggeringPolicies:
+- name: exponential
+  groupingExpression: .spec.containers[0].image
+  pacer:
+    exponential:
+      maxStagger: 16
+      multiplier: 0.5
+- name: linear
+  labelSelector: {app: web}
+  bypassLabelSelector: {app: db}
+  groupingExpression: .spec.containers[0].image
+  pacer:
+    linear:
+      maxStagger: 4
+      step: 0
+    adaptive:
+      maxWindow: 4
+      decreaseFactor: 1.5
+- name: linear
+  labelSelector: {app: web, tier: frontend}
+  bypassLabelSelector: {app: web}
+  groupingExpression: "[[["
+- name: schedule
+  groupingExpression: .metadata.labels.app
+  circuitBreaker:
+    failureRatio: 2
+  readiness:
+    conditionType: example.com/warm
+    containersRunning: true
+    minReadySeconds: -1
+  ordering:
+    strategy: random
+    fairness:
+      mode: drf
+      by: tenant
+      weights: {team-a: 0}
+  pacer:
+    schedule:
+      timeZone: Nowhere/Unknown
+      windows:
+      - days: [Mon, Someday]
+        start: "09:00"
+        end: "9pm"
+        pacer:
+          concurrency:
+            maxStarting: 2
+      - start: "22:00"
+        end: "02:00"
+        pacer: {}
+- name: cel
+  groupingCELExpression: pod.spec.containers.size()
+  pacer:
+    linear:
+      maxStagger: 4
+      step: 1
+- groupingExpression: .metadata.name
+  unblocker: unknown
+  pacer:
+    linear:
+      maxStagger: 4
+      step: 1
+- name: team-a/policy
+  groupingExpression: .metadata.labels.team
+  pacer:
+    linear:
+      maxStagger: 4
+      step: 1
+- name: cluster:policy
+  groupingExpression: .metadata.labels.cluster
+  pacer:
+    linear:
+      maxStagger: 4
+      step: 1
+- name: Team A/web:v2
+  groupingExpression: .metadata.labels.web
+  pacer:
+    linear:
+      maxStagger: 4
+      step: 1
+`, testr.New(t))
+	require.NoError(t, err)
+
+	errs := config.Validate()
+	paths := make(map[string]field.ErrorType)
+	for _, err := range errs {
+		paths[err.Field] = err.Type
+	}
+	require.Equal(t, map[string]field.ErrorType{
+		"staggeringPolicies[exponential].pacer.exponential.minInitial":   field.ErrorTypeRequired,
+		"staggeringPolicies[exponential].pacer.exponential.multiplier":   field.ErrorTypeInvalid,
+		"staggeringPolicies[linear].bypassLabelSelector[app]":            field.ErrorTypeInvalid,
+		"staggeringPolicies[linear].groupingExpression":                  field.ErrorTypeInvalid,
+		"staggeringPolicies[linear].pacer.linear.step":                   field.ErrorTypeInvalid,
+		"staggeringPolicies[linear].pacer.adaptive.targetLatency":        field.ErrorTypeRequired,
+		"staggeringPolicies[linear].pacer.adaptive.decreaseFactor":       field.ErrorTypeInvalid,
+		"staggeringPolicies[linear].name":                                field.ErrorTypeDuplicate,
+		"staggeringPolicies[linear].bypassLabelSelector":                 field.ErrorTypeInvalid,
+		"staggeringPolicies[linear].pacer":                               field.ErrorTypeRequired,
+		"staggeringPolicies[schedule].ordering.strategy":                 field.ErrorTypeNotSupported,
+		"staggeringPolicies[schedule].readiness.conditionType":           field.ErrorTypeInvalid,
+		"staggeringPolicies[schedule].readiness.minReadySeconds":         field.ErrorTypeInvalid,
+		"staggeringPolicies[schedule].circuitBreaker.failureRatio":       field.ErrorTypeInvalid,
+		"staggeringPolicies[schedule].ordering.fairness.by":              field.ErrorTypeNotSupported,
+		"staggeringPolicies[schedule].ordering.fairness.weights[team-a]": field.ErrorTypeInvalid,
+		"staggeringPolicies[schedule].pacer.schedule.timeZone":           field.ErrorTypeInvalid,
+		"staggeringPolicies[schedule].pacer.schedule.windows[0].days[1]": field.ErrorTypeInvalid,
+		"staggeringPolicies[schedule].pacer.schedule.windows[0].end":     field.ErrorTypeInvalid,
+		"staggeringPolicies[schedule].pacer.schedule.windows[1].pacer":   field.ErrorTypeRequired,
+		"staggeringPolicies[cel].groupingCELExpression":                  field.ErrorTypeInvalid,
+		"staggeringPolicies[5].name":                                     field.ErrorTypeRequired,
+		"staggeringPolicies[5].unblocker":                                field.ErrorTypeNotSupported,
+		"staggeringPolicies[team-a/policy].name":                         field.ErrorTypeInvalid,
+		"staggeringPolicies[cluster:policy].name":                        field.ErrorTypeInvalid,
+	}, paths)
+}
+
+func TestConfigValidateOverlapping(t *testing.T) {
+	config, err := LoadConfigFromString(`
+staggeringPolicies:
+- name: web
+  labelSelector: {app: web}
+  groupingExpression: .metadata.labels.app
+  unblocker: evict
+  readiness:
+    succeeded: true
+  pacer:
+    linear:
+      maxStagger: 4
+      step: 1
+- name: frontend
+  labelSelector: {tier: frontend}
+  groupingExpression: .metadata.labels.tier
+  unblocker: delete
+  readiness:
+    containersRunning: true
+  pacer:
+    linear:
+      maxStagger: 4
+      step: 1
+- name: db
+  labelSelector: {app: db}
+  groupingExpression: .metadata.labels.zone
+  unblocker: evict
+  pacer:
+    linear:
+      maxStagger: 4
+      step: 1
+- name: team-b
+  namespaces: [team-b]
+  labelSelector: {app: batch, tier: backend}
+  groupingExpression: .metadata.labels.rack
+  readiness:
+    minReadySeconds: 10
+  pacer:
+    linear:
+      maxStagger: 4
+      step: 1
+- name: team-a
+  namespaces: [team-a]
+  labelSelector: {app: batch, tier: backend}
+  groupingExpression: .metadata.labels.region
+  readiness:
+    minReadySeconds: 30
+  pacer:
+    linear:
+      maxStagger: 4
+      step: 1
+`, testr.New(t))
+	require.NoError(t, err)
+
+	// frontend may select web and db pods, db pods are never web pods and
+	// policies of distinct namespaces never select the same pods.
+	errs := config.Validate()
+	paths := make(map[string]field.ErrorType)
+	for _, err := range errs {
+		paths[err.Field] = err.Type
+	}
+	require.Equal(t, map[string]field.ErrorType{
+		"staggeringPolicies[frontend].unblocker": field.ErrorTypeInvalid,
+		"staggeringPolicies[frontend].readiness": field.ErrorTypeInvalid,
+		"staggeringPolicies[db].unblocker":       field.ErrorTypeInvalid,
+	}, paths)
+}
+
+func TestNewStaggerGroupInvalid(t *testing.T) {
+	policy := newTestPolicy("test", ".metadata.namespace", 1)
+	policy.Pacer.Exponential.MinInitial = nil
+
+	// invalid policies are rejected instead of panicking.
+	_, err := NewStaggerGroup(policy, clock.RealClock{}, testr.New(t))
+	require.Error(t, err)
+}
@@ -6,19 +6,50 @@ import (
 	"time"
 
 	pacertypes "straggler/pkg/pacer/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// Definition of a ready pod for pacing. The zero value requires the
+// PodReady condition.
+type Readiness struct {
+	// pod condition type that must be true. Default PodReady.
+	ConditionType corev1.PodConditionType
+	// require all containers to be running instead of a condition.
+	ContainersRunning bool
+	// minimum duration pods must be ready for.
+	MinReadyDuration time.Duration
+	// count succeeded pods as ready.
+	Succeeded bool
+}
+
 type StaggerGroup struct {
 	// group name. must be unique.
 	Name string
-	// set of labels to apply this staggering configuration.
-	LabelSelector map[string]string
-	// set of labels to bypass staggering.
-	BypassLabelSelector map[string]string
+	// if set, only pods in this namespace are considered.
+	Namespace string
+	// selector of pods to apply this staggering configuration. Nil for all.
+	LabelSelector *metav1.LabelSelector
+	// selector of pods to bypass staggering. Nil for none.
+	BypassLabelSelector *metav1.LabelSelector
+	// selector of namespace labels to apply this staggering configuration.
+	// Nil for all.
+	NamespaceSelector *metav1.LabelSelector
+	// if set, only pods in these namespaces are considered.
+	Namespaces []string
+	// pods in these namespaces are not considered.
+	ExcludedNamespaces []string
 	// jsonpath aggregation grouping expression.
 	GroupingExpression string
+	// CEL grouping expression. Used if GroupingExpression is empty.
+	GroupingCELExpression string
 	// Maximum time to keep a pod in blocked state. Default none.
 	MaxBlockedDuration time.Duration
+	// strategy used to release blocked pods. Empty for default.
+	Unblocker string
+	// definition of ready pods for pacing. Nil for default.
+	Readiness *Readiness
 
 	PacerFactory pacertypes.PacerFactory
 }
@@ -4,14 +4,21 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"straggler/pkg/blocker"
 	blockertypes "straggler/pkg/blocker/types"
 	"straggler/pkg/controller/types"
-	pacertypes "straggler/pkg/pacer/types"
+	"straggler/pkg/pacer/ordering"
+	"straggler/pkg/unblocker"
 
 	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -23,9 +30,11 @@ import (
 var (
 	DefaultEnableLabel         = "v1.straggler.technicianted/enable"
 	DefaultStaggerGroupIDLabel = "v1.straggler.technicianted/group"
-	DefaultStaggeredPodLabel   = "v1.straggler.technicianted/staggered"
 	DefaultJobPodLabel         = "v1.straggler.technicianted/jobPod"
 	DefaultFlightWait          = 500 * time.Millisecond
+	// Annotation with members of the pod staggering group used to restore
+	// it after restarts.
+	DefaultGroupMembersAnnotation = "v1.straggler.technicianted/groupMembers"
 )
 
 var _ admission.CustomDefaulter = &Admission{}
@@ -37,11 +46,17 @@ type Admission struct {
 	recorderFactory    types.ObjectRecorderFactory
 	podBlocker         blockertypes.PodBlocker
 	flightTracker      types.AdmissionFlightTracker
+	enableChecker      types.EnableChecker
+	statefulSets       types.StatefulSetGetter
 
-	enableLabel         string
 	staggerGroupIDLabel string
 	jobPodLabel         string
 
+	// username allowed to change straggler managed fields of pods. Changes
+	// by other users are reverted. If empty, changes are not reverted and
+	// no requests are trusted as made by straggler.
+	serviceAccount string
+
 	bypassFailures bool
 }
 
@@ -51,7 +66,9 @@ func NewAdmission(classifier types.PodClassifier,
 	podBlocker blockertypes.PodBlocker,
 	flightTracker types.AdmissionFlightTracker,
 	bypassFailures bool,
-	enableLabel string,
+	enableChecker types.EnableChecker,
+	serviceAccount string,
+	statefulSets types.StatefulSetGetter,
 ) *Admission {
 	return &Admission{
 		classifier:          classifier,
@@ -59,9 +76,11 @@ func NewAdmission(classifier types.PodClassifier,
 		recorderFactory:     recorderFactory,
 		podBlocker:          podBlocker,
 		flightTracker:       flightTracker,
-		enableLabel:         enableLabel,
+		enableChecker:       enableChecker,
+		statefulSets:        statefulSets,
 		staggerGroupIDLabel: DefaultStaggerGroupIDLabel,
 		jobPodLabel:         DefaultJobPodLabel,
+		serviceAccount:      serviceAccount,
 		bypassFailures:      bypassFailures,
 	}
 }
@@ -79,7 +98,7 @@ func newAdmission(classifier types.PodClassifier,
 		recorderFactory:     recorderFactory,
 		flightTracker:       flightTracker,
 		podBlocker:          podBlocker,
-		enableLabel:         DefaultEnableLabel,
+		enableChecker:       NewEnableChecker(DefaultEnableLabel, nil),
 		staggerGroupIDLabel: DefaultStaggerGroupIDLabel,
 		jobPodLabel:         DefaultJobPodLabel,
 		bypassFailures:      bypassFailures,
@@ -88,12 +107,20 @@ func newAdmission(classifier types.PodClassifier,
 
 func (a *Admission) Default(ctx context.Context, obj runtime.Object) error {
 	logger := logf.FromContext(ctx)
+	req, _ := admission.RequestFromContext(ctx)
+	update := req.Operation == admissionv1.Update
 	var err error
 	switch o := obj.(type) {
 	case *corev1.Pod:
-		err = a.handlePodAdmission(ctx, o, logger)
+		if update {
+			err = a.handlePodUpdate(ctx, req, o, logger)
+		} else {
+			err = a.handlePodAdmission(ctx, o, logger)
+		}
 	case *batchv1.Job:
-		err = a.handleJobAdmission(ctx, o, logger)
+		if !update {
+			err = a.handleJobAdmission(ctx, o, logger)
+		}
 	default:
 		err = fmt.Errorf("unexpected object type %T", obj)
 	}
@@ -105,18 +132,43 @@ func (a *Admission) Default(ctx context.Context, obj runtime.Object) error {
 	return err
 }
 
-func (a *Admission) handlePodAdmission(ctx context.Context, pod *corev1.Pod, logger logr.Logger) error {
+func (a *Admission) handlePodAdmission(ctx context.Context, pod *corev1.Pod, logger logr.Logger) (err error) {
 	logger.V(10).Info("handling admission of pod", "name", pod.Name, "generateName", pod.GenerateName, "namespace", pod.Namespace)
-	if !a.checkEnabled(&pod.ObjectMeta, logger) {
+	// pods created without an explicit namespace get it from the request.
+	if len(pod.Namespace) == 0 {
+		if req, err := admission.RequestFromContext(ctx); err == nil {
+			pod.Namespace = req.Namespace
+		}
+	}
+	if !a.enableChecker.IsEnabled(ctx, pod.Namespace, pod.Labels, logger) {
 		logger.V(0).Info("skipping not enabled pod")
 		return nil
 	}
 
+	var policies []string
+	outcome := admissionOutcomeBypassed
+	defer func() {
+		if err != nil {
+			outcome = admissionOutcomeErrored
+		}
+		recordAdmission(policies, outcome)
+	}()
+
+	// pods recreated by straggler after their release are let through.
+	if _, ok := pod.Annotations[unblocker.DefaultRecreatedAnnotation]; ok {
+		if a.isServiceAccount(ctx) {
+			logger.Info("not blocking recreated pod")
+			return nil
+		}
+		delete(pod.Annotations, unblocker.DefaultRecreatedAnnotation)
+	}
+
 	// If this pod belongs to a job with set backoffLimit then we immediately block it
 	// since it has to be handled in the reconciler.
 	// See job handling for reasonong.
 	if len(pod.Labels) > 0 {
 		if _, ok := pod.Labels[a.jobPodLabel]; ok {
+			outcome = admissionOutcomeBlocked
 			return a.blockPod(pod, logger)
 		}
 	}
@@ -130,11 +182,26 @@ func (a *Admission) handlePodAdmission(ctx context.Context, pod *corev1.Pod, log
 		return nil
 	}
 	logger.V(1).Info("staggering group", "id", group.ID, "pacer", group.Pacer)
+	policies = group.Policies
 
 	if pod.Labels == nil {
 		pod.Labels = make(map[string]string)
 	}
 	pod.Labels[a.staggerGroupIDLabel] = group.ID
+	if err := setGroupMembers(&pod.ObjectMeta, group.Members); err != nil {
+		return err
+	}
+
+	if statefulSet := a.getStatefulSet(ctx, pod, logger); statefulSet != nil {
+		// OrderedReady StatefulSets already start their pods one at a time
+		// and blocking them would hold up the rest of the set.
+		if isOrderedReady(statefulSet) {
+			logger.Info("not blocking pod of OrderedReady statefulset", "statefulset", statefulSet.Name)
+			outcome = admissionOutcomeAdmitted
+			return nil
+		}
+		setStatefulSetPartition(pod, statefulSet)
+	}
 
 	logger.V(1).Info("will wait for flight tracker", "wait", DefaultFlightWait)
 	flightCTX, cancel := context.WithTimeout(ctx, DefaultFlightWait)
@@ -144,18 +211,19 @@ func (a *Admission) handlePodAdmission(ctx context.Context, pod *corev1.Pod, log
 		logger.Info("failed to wait on flight tracker", "error", err)
 	}
 
-	ready, starting, blocked, err := a.podGroupClassifier.ClassifyPodGroup(ctx, group.ID, logger)
+	classification, err := a.podGroupClassifier.ClassifyPodGroup(ctx, group.ID, group.GroupPolicies.Readiness, logger)
 	if err != nil {
 		return fmt.Errorf("failed to classify pod group: %v", err)
 	}
-	logger.V(1).Info("pod group break down", "ready", len(ready), "starting", len(starting), "blocked", len(blocked))
+	logger.V(1).Info("pod group break down",
+		"ready", len(classification.Ready),
+		"starting", len(classification.Starting),
+		"blocked", len(classification.Blocked),
+		"failed", len(classification.Failed))
 
-	unblocked, err := group.Pacer.Pace(pacertypes.PodClassification{
-		Ready:    ready,
-		Starting: starting,
-		// append current pod to blocked and see if it'll be allowed
-		Blocked: append(blocked, *pod),
-	}, logger)
+	// append current pod to blocked and see if it'll be allowed
+	classification.Blocked = append(classification.Blocked, *pod)
+	unblocked, err := group.Pacer.Pace(classification, logger)
 	if err != nil {
 		return fmt.Errorf("failed to pace pod: %v", err)
 	}
@@ -165,19 +233,127 @@ func (a *Admission) handlePodAdmission(ctx context.Context, pod *corev1.Pod, log
 			unblockedPod.Namespace == pod.Namespace &&
 			unblockedPod.GenerateName == pod.GenerateName {
 			logger.Info("not blocking pod as pacer allows it")
+			outcome = admissionOutcomeAdmitted
 			return nil
 		}
 	}
 
 	logger.Info("pacer will not allow pod")
-	pod.Labels[DefaultStaggeredPodLabel] = "1"
+	if err := a.blockPod(pod, logger); err != nil {
+		return err
+	}
+	pod.Labels[blocker.DefaultStaggeredPodLabel] = "1"
+	outcome = admissionOutcomeBlocked
+	a.recorderFactory.AsyncRecorderForRootController(pod, logger).Normalf(
+		EventReasonStaggered,
+		"pod %s staggered by policy %s in group %s",
+		podName(pod),
+		strings.Join(group.Policies, ","),
+		group.ID)
 
-	return a.blockPod(pod, logger)
+	return nil
 }
 
-func (a *Admission) handleJobAdmission(_ context.Context, job *batchv1.Job, logger logr.Logger) error {
+// Revert changes to straggler managed labels, annotations and blocking of
+// pod unless they are made by the straggler service account. These are
+// used to track pods of staggering groups so changing them breaks pacing.
+func (a *Admission) handlePodUpdate(ctx context.Context, req admission.Request, pod *corev1.Pod, logger logr.Logger) error {
+	// straggler changes cannot be told apart from others without a service
+	// account so nothing is protected.
+	if len(a.serviceAccount) == 0 || a.isServiceAccount(ctx) {
+		return nil
+	}
+	logger.V(10).Info("handling update of pod", "name", pod.Name, "namespace", pod.Namespace, "user", req.UserInfo.Username)
+
+	oldPod := corev1.Pod{}
+	if err := json.Unmarshal(req.OldObject.Raw, &oldPod); err != nil {
+		return fmt.Errorf("failed to decode old pod: %v", err)
+	}
+
+	changed := preserveKeys(&pod.Labels, oldPod.Labels, a.managedLabels())
+	if preserveKeys(&pod.Annotations, oldPod.Annotations, a.managedAnnotations()) {
+		changed = true
+	}
+	wasBlocked := a.podBlocker.IsBlocked(&oldPod.Spec)
+	if err := a.podBlocker.Preserve(&pod.Spec, &oldPod.Spec, logger); err != nil {
+		return fmt.Errorf("failed to preserve pod blocking: %v", err)
+	}
+	if wasBlocked && !a.podBlocker.IsBlocked(&pod.Spec) {
+		return fmt.Errorf("failed to preserve pod blocking")
+	}
+	if changed {
+		logger.Info("reverted changes to straggler managed pod metadata", "user", req.UserInfo.Username)
+	}
+
+	return nil
+}
+
+// Check if the admission request in ctx is made by the straggler service
+// account. No requests are if it is not set.
+func (a *Admission) isServiceAccount(ctx context.Context) bool {
+	if len(a.serviceAccount) == 0 {
+		return false
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return false
+	}
+
+	return req.UserInfo.Username == a.serviceAccount
+}
+
+func (a *Admission) managedLabels() []string {
+	return []string{
+		a.staggerGroupIDLabel,
+		a.jobPodLabel,
+		blocker.DefaultStaggeredPodLabel,
+	}
+}
+
+func (a *Admission) managedAnnotations() []string {
+	return []string{
+		DefaultGroupMembersAnnotation,
+		unblocker.DefaultUnblockedByAnnotation,
+		unblocker.DefaultRecreatedAnnotation,
+		blocker.DefaultOriginalSpecAnnotation,
+		blocker.DefaultOriginalSpecConfigMapAnnotation,
+		ordering.DefaultStatefulSetPartitionAnnotation,
+	}
+}
+
+// Set keys of values to those of oldValues, removing ones not in
+// oldValues. Returns true if values were changed.
+func preserveKeys(values *map[string]string, oldValues map[string]string, keys []string) bool {
+	changed := false
+	for _, key := range keys {
+		oldValue, oldOK := oldValues[key]
+		value, ok := (*values)[key]
+		if oldOK == ok && oldValue == value {
+			continue
+		}
+		changed = true
+		if !oldOK {
+			delete(*values, key)
+			continue
+		}
+		if *values == nil {
+			*values = make(map[string]string)
+		}
+		(*values)[key] = oldValue
+	}
+
+	return changed
+}
+
+func (a *Admission) handleJobAdmission(ctx context.Context, job *batchv1.Job, logger logr.Logger) error {
 	logger.V(10).Info("handling admission of job", "name", job.Name, "namespace", job.Namespace)
-	if !a.checkEnabled(&job.Spec.Template.ObjectMeta, logger) {
+	namespace := job.Namespace
+	if len(namespace) == 0 {
+		if req, err := admission.RequestFromContext(ctx); err == nil {
+			namespace = req.Namespace
+		}
+	}
+	if !a.enableChecker.IsEnabled(ctx, namespace, job.Spec.Template.Labels, logger) {
 		logger.V(0).Info("skipping not enabled job")
 		return nil
 	}
@@ -208,6 +384,11 @@ func (a *Admission) handleJobAdmission(_ context.Context, job *batchv1.Job, logg
 	}
 	if policyExists && policyAction != batchv1.PodFailurePolicyActionIgnore {
 		logger.Info("job already has a defined DisruptionTarget policy and will be bypassed")
+		a.recorderFactory.AsyncRecorderForRootController(job, logger).Warnf(
+			EventReasonJobBypassed,
+			"job podFailurePolicy has %s rule with action %s, staggering will not be applied",
+			corev1.DisruptionTarget,
+			policyAction)
 		return nil
 	}
 	if !policyExists {
@@ -225,27 +406,64 @@ func (a *Admission) handleJobAdmission(_ context.Context, job *batchv1.Job, logg
 				},
 			},
 		})
+		a.recorderFactory.AsyncRecorderForRootController(job, logger).Normalf(
+			EventReasonJobPatched,
+			"job podFailurePolicy patched to ignore %s pod failures",
+			corev1.DisruptionTarget)
 	}
 
 	return nil
 }
 
-func (a *Admission) checkEnabled(objectMeta *metav1.ObjectMeta, logger logr.Logger) bool {
-	if len(objectMeta.Labels) == 0 {
-		return false
+// Get the StatefulSet owning pod, or nil if it is not owned by one.
+func (a *Admission) getStatefulSet(ctx context.Context, pod *corev1.Pod, logger logr.Logger) *appsv1.StatefulSet {
+	owner := metav1.GetControllerOf(pod)
+	if a.statefulSets == nil || owner == nil || owner.Kind != "StatefulSet" {
+		return nil
 	}
-	if value, ok := objectMeta.Labels[a.enableLabel]; ok {
-		if value != "1" {
-			logger.Info("found enable label %s but has unexpected value %s", a.enableLabel, value)
-			return false
-		}
-		return true
+	statefulSet, err := a.statefulSets.GetStatefulSet(ctx, pod.Namespace, owner.Name)
+	if err != nil {
+		logger.Info("failed to get pod statefulset", "error", err)
+		return nil
 	}
 
-	return false
+	return statefulSet
+}
+
+func isOrderedReady(statefulSet *appsv1.StatefulSet) bool {
+	policy := statefulSet.Spec.PodManagementPolicy
+	return len(policy) == 0 || policy == appsv1.OrderedReadyPodManagement
+}
+
+// Annotate pod with the rolling update partition of statefulSet for
+// ordering if pod is created by an ongoing rolling update.
+func setStatefulSetPartition(pod *corev1.Pod, statefulSet *appsv1.StatefulSet) {
+	rollingUpdate := statefulSet.Spec.UpdateStrategy.RollingUpdate
+	if statefulSet.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType ||
+		rollingUpdate == nil ||
+		rollingUpdate.Partition == nil {
+		return
+	}
+	// pods created on scale up or restarts are not part of an update.
+	if statefulSet.Status.UpdateRevision == statefulSet.Status.CurrentRevision ||
+		pod.Labels[appsv1.ControllerRevisionHashLabelKey] != statefulSet.Status.UpdateRevision {
+		return
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[ordering.DefaultStatefulSetPartitionAnnotation] = strconv.Itoa(int(*rollingUpdate.Partition))
+}
+
+// Get a name for a pod that may not have one assigned yet.
+func podName(pod *corev1.Pod) string {
+	if len(pod.Name) > 0 {
+		return pod.Name
+	}
+	return pod.GenerateName
 }
 
 func (a *Admission) blockPod(pod *corev1.Pod, logger logr.Logger) error {
 	logger.V(1).Info("blocking pod", "name", pod.Name, "namespace", pod.Namespace)
-	return a.podBlocker.Block(&pod.Spec, logger)
+	return a.podBlocker.Block(pod, logger)
 }
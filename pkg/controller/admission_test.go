@@ -4,21 +4,35 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
 
+	"straggler/pkg/blocker"
 	blockermocks "straggler/pkg/blocker/mocks"
 	"straggler/pkg/controller/mocks"
 	"straggler/pkg/controller/types"
 	pacermocks "straggler/pkg/pacer/mocks"
+	"straggler/pkg/pacer/ordering"
+	pacertypes "straggler/pkg/pacer/types"
+	"straggler/pkg/unblocker"
 
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 func TestAdmissionEnableLabel(t *testing.T) {
@@ -29,10 +43,10 @@ func TestAdmissionEnableLabel(t *testing.T) {
 
 	classifier := mocks.NewMockPodClassifier(mockCtrl)
 	podGroupClassifier := mocks.NewMockPodGroupStandingClassifier(mockCtrl)
-	recorderFactory := mocks.NewMockObjectRecorderFactory(mockCtrl)
-	blocker := blockermocks.NewMockPodBlocker(mockCtrl)
+	recorderFactory := NewRecorderFactory(mocks.NewMockClient(mockCtrl), record.NewFakeRecorder(100))
+	podBlocker := blockermocks.NewMockPodBlocker(mockCtrl)
 
-	admission := newAdmission(classifier, podGroupClassifier, recorderFactory, blocker, &noopFlightTracker{}, false)
+	admission := newAdmission(classifier, podGroupClassifier, recorderFactory, podBlocker, &noopFlightTracker{}, false)
 	err := admission.Default(context.Background(), &pod)
 	require.NoError(t, err)
 	require.EqualValues(t, corev1.Pod{}, pod)
@@ -56,23 +70,28 @@ func TestAdmissionPodAdmissionBlocking(t *testing.T) {
 
 	classifier := mocks.NewMockPodClassifier(mockCtrl)
 	classifier.EXPECT().Classify(pod.ObjectMeta, pod.Spec, gomock.Any()).Return(&types.PodClassification{
-		ID:    "testid",
-		Pacer: pacer,
+		ID:      "testid",
+		Pacer:   pacer,
+		Members: []types.GroupMember{{Policy: "policy", Key: "key"}},
 	}, nil)
 	podGroupClassifier := mocks.NewMockPodGroupStandingClassifier(mockCtrl)
-	podGroupClassifier.EXPECT().ClassifyPodGroup(gomock.Any(), "testid", gomock.Any()).Return(nil, nil, nil, nil).Times(2)
-	recorderFactory := mocks.NewMockObjectRecorderFactory(mockCtrl)
-	blocker := blockermocks.NewMockPodBlocker(mockCtrl)
-	blocker.EXPECT().Block(gomock.Any(), gomock.Any()).Return(nil)
+	podGroupClassifier.EXPECT().ClassifyPodGroup(gomock.Any(), "testid", gomock.Any(), gomock.Any()).Return(pacertypes.PodClassification{}, nil).Times(2)
+	recorderFactory := NewRecorderFactory(mocks.NewMockClient(mockCtrl), record.NewFakeRecorder(100))
+	podBlocker := blockermocks.NewMockPodBlocker(mockCtrl)
+	podBlocker.EXPECT().Block(gomock.Any(), gomock.Any()).Return(nil)
 
-	admission := newAdmission(classifier, podGroupClassifier, recorderFactory, blocker, &noopFlightTracker{}, false)
+	admission := newAdmission(classifier, podGroupClassifier, recorderFactory, podBlocker, &noopFlightTracker{}, false)
 	err := admission.Default(context.Background(), &pod)
 	require.NoError(t, err)
 	// check group label
 	require.Contains(t, pod.Labels, DefaultStaggerGroupIDLabel)
 	require.Equal(t, "testid", pod.Labels[DefaultStaggerGroupIDLabel])
-	require.Contains(t, pod.Labels, DefaultStaggeredPodLabel)
-	require.Equal(t, "1", pod.Labels[DefaultStaggeredPodLabel])
+	require.Contains(t, pod.Labels, blocker.DefaultStaggeredPodLabel)
+	require.Equal(t, "1", pod.Labels[blocker.DefaultStaggeredPodLabel])
+	// check group members can be restored
+	members, err := getGroupMembers(&pod.ObjectMeta)
+	require.NoError(t, err)
+	require.Equal(t, []types.GroupMember{{Policy: "policy", Key: "key"}}, members)
 
 	// allow pod. we expect the group label but not blocking
 	pod = corev1.Pod{
@@ -123,16 +142,16 @@ func TestAdmissionPodErrorBypass(t *testing.T) {
 	classifier := mocks.NewMockPodClassifier(mockCtrl)
 	classifier.EXPECT().Classify(pod.ObjectMeta, pod.Spec, gomock.Any()).Return(nil, fmt.Errorf("test error")).Times(2)
 	podGroupClassifier := mocks.NewMockPodGroupStandingClassifier(mockCtrl)
-	recorderFactory := mocks.NewMockObjectRecorderFactory(mockCtrl)
-	blocker := blockermocks.NewMockPodBlocker(mockCtrl)
+	recorderFactory := NewRecorderFactory(mocks.NewMockClient(mockCtrl), record.NewFakeRecorder(100))
+	podBlocker := blockermocks.NewMockPodBlocker(mockCtrl)
 
 	// we should get an error
-	admission := newAdmission(classifier, podGroupClassifier, recorderFactory, blocker, &noopFlightTracker{}, false)
+	admission := newAdmission(classifier, podGroupClassifier, recorderFactory, podBlocker, &noopFlightTracker{}, false)
 	err := admission.Default(context.Background(), &pod)
 	require.Error(t, err)
 
 	// we should not get an error
-	admission = newAdmission(classifier, podGroupClassifier, recorderFactory, blocker, &noopFlightTracker{}, true)
+	admission = newAdmission(classifier, podGroupClassifier, recorderFactory, podBlocker, &noopFlightTracker{}, true)
 	err = admission.Default(context.Background(), &pod)
 	require.NoError(t, err)
 }
@@ -143,8 +162,8 @@ func TestAdmissionJobSimple(t *testing.T) {
 
 	classifier := mocks.NewMockPodClassifier(mockCtrl)
 	podGroupClassifier := mocks.NewMockPodGroupStandingClassifier(mockCtrl)
-	recorderFactory := mocks.NewMockObjectRecorderFactory(mockCtrl)
-	blocker := blockermocks.NewMockPodBlocker(mockCtrl)
+	recorderFactory := NewRecorderFactory(mocks.NewMockClient(mockCtrl), record.NewFakeRecorder(100))
+	podBlocker := blockermocks.NewMockPodBlocker(mockCtrl)
 
 	job := batchv1.Job{
 		Spec: batchv1.JobSpec{
@@ -157,7 +176,7 @@ func TestAdmissionJobSimple(t *testing.T) {
 			},
 		},
 	}
-	admission := newAdmission(classifier, podGroupClassifier, recorderFactory, blocker, &noopFlightTracker{}, false)
+	admission := newAdmission(classifier, podGroupClassifier, recorderFactory, podBlocker, &noopFlightTracker{}, false)
 	err := admission.Default(context.Background(), &job)
 	require.NoError(t, err)
 	// check if policy was added
@@ -192,9 +211,9 @@ func TestAdmissionPodFlight(t *testing.T) {
 		Pacer: pacer,
 	}, nil)
 	podGroupClassifier := mocks.NewMockPodGroupStandingClassifier(mockCtrl)
-	podGroupClassifier.EXPECT().ClassifyPodGroup(gomock.Any(), "testid", gomock.Any()).Return(nil, nil, nil, nil)
-	recorderFactory := mocks.NewMockObjectRecorderFactory(mockCtrl)
-	blocker := blockermocks.NewMockPodBlocker(mockCtrl)
+	podGroupClassifier.EXPECT().ClassifyPodGroup(gomock.Any(), "testid", gomock.Any(), gomock.Any()).Return(pacertypes.PodClassification{}, nil)
+	recorderFactory := NewRecorderFactory(mocks.NewMockClient(mockCtrl), record.NewFakeRecorder(100))
+	podBlocker := blockermocks.NewMockPodBlocker(mockCtrl)
 	flightTracker := mocks.NewMockAdmissionFlightTracker(mockCtrl)
 	flightChan := make(chan struct{})
 	flightTracker.EXPECT().WaitOne(gomock.Any(), gomock.Any(), gomock.Any(), gomock.All()).DoAndReturn(
@@ -204,7 +223,7 @@ func TestAdmissionPodFlight(t *testing.T) {
 		})
 
 	// we should get an error
-	admission := newAdmission(classifier, podGroupClassifier, recorderFactory, blocker, flightTracker, false)
+	admission := newAdmission(classifier, podGroupClassifier, recorderFactory, podBlocker, flightTracker, false)
 	// this should block
 	go func() {
 		<-time.After(100 * time.Millisecond)
@@ -217,3 +236,251 @@ func TestAdmissionPodFlight(t *testing.T) {
 	require.NoError(t, err)
 	require.InDelta(t, 100*time.Millisecond, time.Since(startTime), float64(10*time.Millisecond))
 }
+
+func TestAdmissionMetrics(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	newPod := func() corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					DefaultEnableLabel: "1",
+				},
+			},
+		}
+	}
+	policy := t.Name()
+	counter := func(outcome string) float64 {
+		return testutil.ToFloat64(admissionsTotal.WithLabelValues(policy, outcome))
+	}
+
+	pacer := pacermocks.NewMockPacer(mockCtrl)
+	classifier := mocks.NewMockPodClassifier(mockCtrl)
+	classifier.EXPECT().Classify(gomock.Any(), gomock.Any(), gomock.Any()).Return(&types.PodClassification{
+		ID:       "testid",
+		Pacer:    pacer,
+		Policies: []string{policy},
+	}, nil).Times(2)
+	podGroupClassifier := mocks.NewMockPodGroupStandingClassifier(mockCtrl)
+	podGroupClassifier.EXPECT().ClassifyPodGroup(gomock.Any(), "testid", gomock.Any(), gomock.Any()).Return(pacertypes.PodClassification{}, nil).Times(2)
+	recorderFactory := NewRecorderFactory(mocks.NewMockClient(mockCtrl), record.NewFakeRecorder(100))
+	podBlocker := blockermocks.NewMockPodBlocker(mockCtrl)
+	podBlocker.EXPECT().Block(gomock.Any(), gomock.Any()).Return(nil)
+	admission := newAdmission(classifier, podGroupClassifier, recorderFactory, podBlocker, &noopFlightTracker{}, false)
+
+	// blocked pod
+	pod := newPod()
+	pacer.EXPECT().Pace(gomock.Any(), gomock.Any()).Return(nil, nil)
+	err := admission.Default(context.Background(), &pod)
+	require.NoError(t, err)
+	require.Equal(t, float64(1), counter(admissionOutcomeBlocked))
+	require.Equal(t, float64(0), counter(admissionOutcomeAdmitted))
+
+	// admitted pod
+	pod = newPod()
+	pacer.EXPECT().Pace(gomock.Any(), gomock.Any()).Return([]corev1.Pod{pod}, nil)
+	err = admission.Default(context.Background(), &pod)
+	require.NoError(t, err)
+	require.Equal(t, float64(1), counter(admissionOutcomeBlocked))
+	require.Equal(t, float64(1), counter(admissionOutcomeAdmitted))
+}
+
+func TestAdmissionPodUpdate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	oldPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				DefaultEnableLabel:               "1",
+				DefaultStaggerGroupIDLabel:       "testid",
+				blocker.DefaultStaggeredPodLabel: "1",
+			},
+			Annotations: map[string]string{
+				DefaultGroupMembersAnnotation: "[]",
+			},
+		},
+	}
+	oldRaw, err := json.Marshal(oldPod)
+	require.NoError(t, err)
+	newPod := func() corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					DefaultEnableLabel:         "1",
+					DefaultStaggerGroupIDLabel: "othergroup",
+					"other":                    "1",
+				},
+			},
+		}
+	}
+	updateCTX := func(username string) context.Context {
+		return admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Update,
+				OldObject: runtime.RawExtension{Raw: oldRaw},
+				UserInfo:  authenticationv1.UserInfo{Username: username},
+			},
+		})
+	}
+
+	classifier := mocks.NewMockPodClassifier(mockCtrl)
+	podGroupClassifier := mocks.NewMockPodGroupStandingClassifier(mockCtrl)
+	recorderFactory := NewRecorderFactory(mocks.NewMockClient(mockCtrl), record.NewFakeRecorder(100))
+	podBlocker := blockermocks.NewMockPodBlocker(mockCtrl)
+	podBlocker.EXPECT().IsBlocked(gomock.Any()).Return(true).Times(2)
+	podBlocker.EXPECT().Preserve(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	a := newAdmission(classifier, podGroupClassifier, recorderFactory, podBlocker, &noopFlightTracker{}, false)
+	a.serviceAccount = "system:serviceaccount:straggler:straggler"
+
+	// changes by other users are reverted
+	pod := newPod()
+	err = a.Default(updateCTX("user"), &pod)
+	require.NoError(t, err)
+	require.Equal(t, "testid", pod.Labels[DefaultStaggerGroupIDLabel])
+	require.Equal(t, "1", pod.Labels[blocker.DefaultStaggeredPodLabel])
+	require.Equal(t, "1", pod.Labels["other"])
+	require.Equal(t, "[]", pod.Annotations[DefaultGroupMembersAnnotation])
+
+	// changes by straggler are kept
+	pod = newPod()
+	err = a.Default(updateCTX(a.serviceAccount), &pod)
+	require.NoError(t, err)
+	require.Equal(t, newPod(), pod)
+}
+
+func TestAdmissionRecreatedPod(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	newPod := func() corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					DefaultEnableLabel: "1",
+				},
+				Annotations: map[string]string{
+					unblocker.DefaultRecreatedAnnotation: "uid",
+				},
+			},
+		}
+	}
+	createCTX := func(username string) context.Context {
+		return admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				UserInfo:  authenticationv1.UserInfo{Username: username},
+			},
+		})
+	}
+
+	classifier := mocks.NewMockPodClassifier(mockCtrl)
+	podGroupClassifier := mocks.NewMockPodGroupStandingClassifier(mockCtrl)
+	recorderFactory := NewRecorderFactory(mocks.NewMockClient(mockCtrl), record.NewFakeRecorder(100))
+	podBlocker := blockermocks.NewMockPodBlocker(mockCtrl)
+	a := newAdmission(classifier, podGroupClassifier, recorderFactory, podBlocker, &noopFlightTracker{}, false)
+	a.serviceAccount = "system:serviceaccount:straggler:straggler"
+
+	// recreated pods are let through
+	pod := newPod()
+	err := a.Default(createCTX(a.serviceAccount), &pod)
+	require.NoError(t, err)
+	require.Equal(t, newPod(), pod)
+
+	// recreated marker set by other users is ignored
+	pod = newPod()
+	classifier.EXPECT().Classify(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	err = a.Default(createCTX("user"), &pod)
+	require.NoError(t, err)
+	require.NotContains(t, pod.Annotations, unblocker.DefaultRecreatedAnnotation)
+
+	// recreated marker is ignored without a service account
+	a.serviceAccount = ""
+	pod = newPod()
+	classifier.EXPECT().Classify(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	err = a.Default(createCTX(""), &pod)
+	require.NoError(t, err)
+	require.NotContains(t, pod.Annotations, unblocker.DefaultRecreatedAnnotation)
+}
+
+func TestAdmissionStatefulSetPod(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	newPod := func(statefulSet string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      statefulSet + "-0",
+				Namespace: "default",
+				Labels: map[string]string{
+					DefaultEnableLabel: "1",
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "StatefulSet", Name: statefulSet, Controller: ptr.To(true)},
+				},
+			},
+		}
+	}
+
+	pacer := pacermocks.NewMockPacer(mockCtrl)
+	classifier := mocks.NewMockPodClassifier(mockCtrl)
+	classifier.EXPECT().Classify(gomock.Any(), gomock.Any(), gomock.Any()).Return(&types.PodClassification{
+		ID:    "testid",
+		Pacer: pacer,
+	}, nil).Times(3)
+	podGroupClassifier := mocks.NewMockPodGroupStandingClassifier(mockCtrl)
+	// owner of staggered pod is looked up in the background to record events.
+	recorderFactory := NewRecorderFactory(fake.NewClientBuilder().Build(), record.NewFakeRecorder(100))
+	podBlocker := blockermocks.NewMockPodBlocker(mockCtrl)
+	statefulSets := mocks.NewMockStatefulSetGetter(mockCtrl)
+	statefulSets.EXPECT().GetStatefulSet(gomock.Any(), "default", "ordered").Return(&appsv1.StatefulSet{}, nil)
+	newParallel := func(partition int32, updateRevision string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			Spec: appsv1.StatefulSetSpec{
+				PodManagementPolicy: appsv1.ParallelPodManagement,
+				UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+					Type: appsv1.RollingUpdateStatefulSetStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{
+						Partition: ptr.To(partition),
+					},
+				},
+			},
+			Status: appsv1.StatefulSetStatus{
+				CurrentRevision: "current",
+				UpdateRevision:  updateRevision,
+			},
+		}
+	}
+	statefulSets.EXPECT().GetStatefulSet(gomock.Any(), "default", "parallel").Return(newParallel(3, "update"), nil)
+	statefulSets.EXPECT().GetStatefulSet(gomock.Any(), "default", "scaled").Return(newParallel(0, "current"), nil)
+	a := newAdmission(classifier, podGroupClassifier, recorderFactory, podBlocker, &noopFlightTracker{}, false)
+	a.statefulSets = statefulSets
+
+	// pods of OrderedReady statefulsets are not paced
+	pod := newPod("ordered")
+	err := a.Default(context.Background(), &pod)
+	require.NoError(t, err)
+	require.Equal(t, "testid", pod.Labels[DefaultStaggerGroupIDLabel])
+	require.NotContains(t, pod.Labels, blocker.DefaultStaggeredPodLabel)
+
+	// pods of parallel statefulsets are paced with their partition during
+	// rolling updates
+	pod = newPod("parallel")
+	pod.Labels[appsv1.ControllerRevisionHashLabelKey] = "update"
+	podGroupClassifier.EXPECT().ClassifyPodGroup(gomock.Any(), "testid", gomock.Any(), gomock.Any()).Return(pacertypes.PodClassification{}, nil).Times(2)
+	pacer.EXPECT().Pace(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+	podBlocker.EXPECT().Block(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	err = a.Default(context.Background(), &pod)
+	require.NoError(t, err)
+	require.Contains(t, pod.Labels, blocker.DefaultStaggeredPodLabel)
+	require.Equal(t, "3", pod.Annotations[ordering.DefaultStatefulSetPartitionAnnotation])
+
+	// the default partition 0 is ignored on scale up
+	pod = newPod("scaled")
+	pod.Labels[appsv1.ControllerRevisionHashLabelKey] = "current"
+	err = a.Default(context.Background(), &pod)
+	require.NoError(t, err)
+	require.Contains(t, pod.Labels, blocker.DefaultStaggeredPodLabel)
+	require.NotContains(t, pod.Annotations, ordering.DefaultStatefulSetPartitionAnnotation)
+}
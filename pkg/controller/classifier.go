@@ -3,9 +3,12 @@
 package controller
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -16,7 +19,6 @@ import (
 	pacertypes "straggler/pkg/pacer/types"
 
 	"github.com/go-logr/logr"
-	"github.com/ohler55/ojg/jp"
 	"github.com/patrickmn/go-cache"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,35 +33,46 @@ var (
 type configEntry struct {
 	configtypes.StaggerGroup
 
-	groupingJSONPath jp.Expr
-	selector         labels.Selector
-	bypassSelector   labels.Selector
+	groupingKeys      groupingKeyEvaluator
+	selector          labels.Selector
+	bypassSelector    labels.Selector
+	namespaceSelector labels.Selector
 }
 
 type groupEntry struct {
 	id             string
 	configs        []configEntry
+	keys           []string
 	compositePacer pacertypes.Pacer
 }
 
 type podClassifier struct {
 	sync.Mutex
 
+	namespaces  types.NamespaceLabeler
 	configs     map[string]configEntry
 	configNames []string
 	groupsByID  *cache.Cache
-	// TODO: keys can theoritically by deuplicate across configs
+	// pacers are cached by config name and grouping key.
 	pacersByKey *cache.Cache
 }
 
-// Create a new pod classifier into pacer.
-func NewPodClassifier() *podClassifier {
-	return &podClassifier{
+// Create a new pod classifier into pacer. Namespace labels used by namespace
+// selectors are obtained from namespaces. If nil, namespaces have no labels.
+func NewPodClassifier(namespaces types.NamespaceLabeler) *podClassifier {
+	classifier := &podClassifier{
+		namespaces:  namespaces,
 		configs:     make(map[string]configEntry),
 		configNames: make([]string, 0),
 		groupsByID:  cache.New(30*time.Minute, 1*time.Minute),
 		pacersByKey: cache.New(30*time.Minute, 1*time.Minute),
 	}
+	// drop metrics of idle groups.
+	classifier.groupsByID.OnEvicted(func(id string, _ interface{}) {
+		pacer.DeleteGroupMetrics(id)
+	})
+
+	return classifier
 }
 
 func (c *podClassifier) AddConfig(config configtypes.StaggerGroup, logger logr.Logger) error {
@@ -76,6 +89,9 @@ func (c *podClassifier) AddConfig(config configtypes.StaggerGroup, logger logr.L
 	}
 	c.configs[entry.Name] = entry
 	c.configNames = append(c.configNames, entry.Name)
+	// a config with the same name may have existed before so make sure
+	// that stale groups do not keep using old pacers.
+	c.refreshGroupsLocked(entry, logger)
 
 	return nil
 }
@@ -96,6 +112,9 @@ func (c *podClassifier) RemoveConfig(name string, logger logr.Logger) error {
 		}
 	}
 	c.configNames = newNames
+	// existing groups are left intact such that already blocked pods
+	// continue to be paced. new pods will no longer match this config.
+	c.deletePacersLocked(name)
 
 	return nil
 }
@@ -104,151 +123,364 @@ func (c *podClassifier) UpdateConfig(config configtypes.StaggerGroup, logger log
 	c.Lock()
 	defer c.Unlock()
 
+	if _, ok := c.configs[config.Name]; !ok {
+		return fmt.Errorf("config not found: %s", config.Name)
+	}
+
 	entry, err := c.newConfigEntryLocked(config)
 	if err != nil {
 		return err
 	}
 
-	delete(c.configs, config.Name)
 	c.configs[entry.Name] = entry
+	c.refreshGroupsLocked(entry, logger)
 
 	return nil
 }
 
 func (c *podClassifier) Classify(podMeta metav1.ObjectMeta, podSpec corev1.PodSpec, logger logr.Logger) (*types.PodClassification, error) {
-	logger.V(10).Info("classifying pod", "name", podMeta.Name, "namespace", podMeta.Name, "uid", podMeta.UID)
+	logger.V(10).Info("classifying pod", "name", podMeta.Name, "namespace", podMeta.Namespace, "uid", podMeta.UID)
 
 	c.Lock()
 	defer c.Unlock()
 
+	configs, keys, err := c.matchConfigsLocked(podMeta, podSpec, logger)
+	if err != nil {
+		return nil, err
+	}
+	if len(configs) == 0 {
+		return nil, nil
+	}
+
 	var group *groupEntry
-	pacers := make([]pacertypes.Pacer, 0)
+	id := c.calculateGroupID(keys, configs)
+	if g, ok := c.groupsByID.Get(id); ok {
+		group = g.(*groupEntry)
+	} else {
+		pacers := make([]pacertypes.Pacer, 0, len(configs))
+		for i := range configs {
+			pacers = append(pacers, c.getPacerLocked(configs[i], keys[i]))
+		}
+		group = &groupEntry{
+			id:             id,
+			configs:        configs,
+			keys:           keys,
+			compositePacer: pacer.NewComposite(id, pacers),
+		}
+	}
+	// refresh expiration
+	c.groupsByID.Set(group.id, group, 0)
+
+	return c.groupClassification(group), nil
+}
+
+func (c *podClassifier) GetGroupID(podMeta metav1.ObjectMeta, podSpec corev1.PodSpec, logger logr.Logger) (string, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	configs, keys, err := c.matchConfigsLocked(podMeta, podSpec, logger)
+	if err != nil || len(configs) == 0 {
+		return "", err
+	}
+
+	return c.calculateGroupID(keys, configs), nil
+}
+
+// Get configs that match a pod along with their grouping keys. A config is
+// returned once for each of its keys.
+func (c *podClassifier) matchConfigsLocked(podMeta metav1.ObjectMeta, podSpec corev1.PodSpec, logger logr.Logger) ([]configEntry, []string, error) {
 	configs := make([]configEntry, 0)
-	dummyPod := corev1.Pod{
-		ObjectMeta: podMeta,
-		Spec:       podSpec,
+	keys := make([]string, 0)
+	dummyPod := &evaluationPod{
+		Pod: corev1.Pod{
+			ObjectMeta: podMeta,
+			Spec:       podSpec,
+		},
 	}
+	var namespaceLabels labels.Set
 
 	for _, name := range c.configNames {
 		config := c.configs[name]
+		if len(config.Namespace) > 0 && config.Namespace != podMeta.Namespace {
+			logger.V(1).Info("skipping config due to namespace", "name", name)
+			continue
+		}
+		if len(config.Namespaces) > 0 && !slices.Contains(config.Namespaces, podMeta.Namespace) {
+			logger.V(1).Info("skipping config due to namespaces", "name", name)
+			continue
+		}
+		if slices.Contains(config.ExcludedNamespaces, podMeta.Namespace) {
+			logger.V(1).Info("skipping config due to excluded namespaces", "name", name)
+			continue
+		}
 		if !config.selector.Matches(labels.Set(dummyPod.Labels)) {
 			logger.V(1).Info("skipping config due to label selector", "name", name)
 			continue
 		}
-		if !config.bypassSelector.Empty() &&
+		if config.bypassSelector != nil &&
 			config.bypassSelector.Matches(labels.Set(dummyPod.Labels)) {
 			logger.Info("skipping config due to bypass selector match", "name", name)
 			continue
 		}
-
-		results := config.groupingJSONPath.Get(dummyPod)
-		if len(results) == 0 {
-			logger.V(1).Info("skipping config due to empty json path selector", "name", name)
-			continue
+		if config.namespaceSelector != nil {
+			if namespaceLabels == nil {
+				var err error
+				namespaceLabels, err = c.getNamespaceLabels(podMeta.Namespace)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+			if !config.namespaceSelector.Matches(namespaceLabels) {
+				logger.V(1).Info("skipping config due to namespace selector", "name", name)
+				continue
+			}
 		}
-		key := ""
-		for _, result := range results {
-			key += fmt.Sprintf("%v", result)
+
+		configKeys, err := config.groupingKeys.Keys(dummyPod)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get grouping keys of %s: %v", name, err)
 		}
-		if len(key) == 0 {
-			logger.V(1).Info("skipping config due to empty json path selector", "name", name)
+		if len(configKeys) == 0 {
+			logger.V(1).Info("skipping config due to empty grouping keys", "name", name)
 			continue
 		}
-		logger.V(10).Info("obtained grouping key", "key", key, "jsonpathResults", len(results))
+		logger.V(10).Info("obtained grouping keys", "keys", configKeys)
 
-		var pacer pacertypes.Pacer
-		pacerItem, ok := c.pacersByKey.Get(key)
-		if !ok {
-			pacer = config.PacerFactory.New(key)
-		} else {
-			pacer = pacerItem.(pacertypes.Pacer)
+		// pods with multiple keys join a group of each key.
+		for _, key := range configKeys {
+			configs = append(configs, config)
+			keys = append(keys, key)
 		}
-		c.pacersByKey.Set(key, pacer, 0)
-		pacers = append(pacers, pacer)
-		configs = append(configs, config)
 	}
 
-	if len(configs) > 0 {
-		id := c.calculateGroupID(pacers, configs)
-		if g, ok := c.groupsByID.Get(id); ok {
-			group = g.(*groupEntry)
-		} else {
-			group = &groupEntry{
-				id:             id,
-				configs:        configs,
-				compositePacer: pacer.NewComposite(id, pacers),
-			}
-			c.groupsByID.Set(group.id, group, 0)
+	return configs, keys, nil
+}
+
+func (c *podClassifier) ClassifyByGroupID(groupID string, logger logr.Logger) (*types.PodClassification, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	g, ok := c.groupsByID.Get(groupID)
+	if ok {
+		return c.groupClassification(g.(*groupEntry)), nil
+	}
+
+	return nil, nil
+}
+
+func (c *podClassifier) RestoreGroup(groupID string, members []types.GroupMember, logger logr.Logger) (*types.PodClassification, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if g, ok := c.groupsByID.Get(groupID); ok {
+		return c.groupClassification(g.(*groupEntry)), nil
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	pacers := make([]pacertypes.Pacer, 0, len(members))
+	configs := make([]configEntry, 0, len(members))
+	keys := make([]string, 0, len(members))
+	for _, member := range members {
+		config, ok := c.configs[member.Policy]
+		if !ok {
+			logger.Info("cannot restore group of removed policy", "id", groupID, "policy", member.Policy)
+			return nil, nil
 		}
+		configs = append(configs, config)
+		keys = append(keys, member.Key)
+	}
+	if id := c.calculateGroupID(keys, configs); id != groupID {
+		logger.Info("cannot restore group with mismatching members", "id", groupID, "membersID", id)
+		return nil, nil
+	}
+	for i := range configs {
+		pacers = append(pacers, c.getPacerLocked(configs[i], keys[i]))
 	}
 
-	if group != nil {
-		return &types.PodClassification{
-			ID:            group.id,
-			Pacer:         group.compositePacer,
-			GroupPolicies: c.calculateAggregateGroupPolicy(configs),
-		}, nil
+	logger.Info("restored group", "id", groupID, "members", len(members))
+	group := &groupEntry{
+		id:             groupID,
+		configs:        configs,
+		keys:           keys,
+		compositePacer: pacer.NewComposite(groupID, pacers),
 	}
+	c.groupsByID.Set(group.id, group, 0)
 
-	return nil, nil
+	return c.groupClassification(group), nil
 }
 
-func (c *podClassifier) ClassifyByGroupID(groupID string, logger logr.Logger) (*types.PodClassification, error) {
-	g, ok := c.groupsByID.Get(groupID)
-	if ok {
-		group := g.(*groupEntry)
-		return &types.PodClassification{
-			ID:    group.id,
-			Pacer: group.compositePacer,
-		}, nil
+func (c *podClassifier) groupClassification(group *groupEntry) *types.PodClassification {
+	members := make([]types.GroupMember, 0, len(group.configs))
+	for i := range group.configs {
+		members = append(members, types.GroupMember{
+			Policy: group.configs[i].Name,
+			Key:    group.keys[i],
+		})
 	}
 
-	return nil, nil
+	return &types.PodClassification{
+		ID:            group.id,
+		Pacer:         group.compositePacer,
+		GroupPolicies: c.calculateAggregateGroupPolicy(group.configs),
+		Policies:      configNames(group.configs),
+		Members:       members,
+	}
 }
 
 func (c *podClassifier) newConfigEntryLocked(config configtypes.StaggerGroup) (entry configEntry, err error) {
-	if len(config.GroupingExpression) == 0 {
+	if len(config.GroupingExpression) == 0 && len(config.GroupingCELExpression) == 0 {
 		err = fmt.Errorf("empty grouping expression")
 		return
 	}
-	// no duplicate expressions.
+	if len(config.GroupingExpression) > 0 && len(config.GroupingCELExpression) > 0 {
+		err = fmt.Errorf("only one of jsonpath or cel grouping expressions can be set")
+		return
+	}
+	// no duplicate expressions for configs that may select the same pods.
 	for name := range c.configs {
-		if config.GroupingExpression == c.configs[name].GroupingExpression {
+		if name == config.Name {
+			continue
+		}
+		if !namespacesOverlap(config, c.configs[name].StaggerGroup) {
+			continue
+		}
+		if config.GroupingExpression == c.configs[name].GroupingExpression &&
+			config.GroupingCELExpression == c.configs[name].GroupingCELExpression {
 			err = fmt.Errorf("grouping expression already exists: %s", name)
 			return
 		}
 	}
-	expr, err := jp.ParseString(config.GroupingExpression)
+	var groupingKeys groupingKeyEvaluator
+	if len(config.GroupingExpression) > 0 {
+		groupingKeys, err = newJSONPathEvaluator(config.GroupingExpression)
+	} else {
+		groupingKeys, err = newCELEvaluator(config.GroupingCELExpression)
+	}
 	if err != nil {
-		err = fmt.Errorf("failed to parse jsonpath %s: %v", config.GroupingExpression, err)
 		return
 	}
 
 	entry = configEntry{
-		StaggerGroup:     config,
-		groupingJSONPath: expr,
-		selector:         labels.SelectorFromSet(config.LabelSelector),
-		bypassSelector:   labels.SelectorFromSet(config.BypassLabelSelector),
+		StaggerGroup: config,
+		groupingKeys: groupingKeys,
+		selector:     labels.Everything(),
+	}
+	if config.LabelSelector != nil {
+		entry.selector, err = metav1.LabelSelectorAsSelector(config.LabelSelector)
+		if err != nil {
+			err = fmt.Errorf("invalid label selector: %v", err)
+			return
+		}
+	}
+	// an empty bypass selector would otherwise bypass all pods.
+	if config.BypassLabelSelector != nil &&
+		(len(config.BypassLabelSelector.MatchLabels) > 0 || len(config.BypassLabelSelector.MatchExpressions) > 0) {
+		entry.bypassSelector, err = metav1.LabelSelectorAsSelector(config.BypassLabelSelector)
+		if err != nil {
+			err = fmt.Errorf("invalid bypass label selector: %v", err)
+			return
+		}
+	}
+	if config.NamespaceSelector != nil {
+		entry.namespaceSelector, err = metav1.LabelSelectorAsSelector(config.NamespaceSelector)
+		if err != nil {
+			err = fmt.Errorf("invalid namespace selector: %v", err)
+			return
+		}
 	}
 	return
 }
 
-func (c *podClassifier) calculateGroupID(pacers []pacertypes.Pacer, matchedConfigs []configEntry) string {
+// Get labels of namespace. Returned labels are never nil.
+func (c *podClassifier) getNamespaceLabels(namespace string) (labels.Set, error) {
+	namespaceLabels := labels.Set{}
+	if c.namespaces == nil {
+		return namespaceLabels, nil
+	}
+	nsLabels, err := c.namespaces.NamespaceLabels(context.TODO(), namespace)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range nsLabels {
+		namespaceLabels[k] = v
+	}
+
+	return namespaceLabels, nil
+}
+
+// Get a cached pacer for config and key, or create a new one.
+func (c *podClassifier) getPacerLocked(config configEntry, key string) pacertypes.Pacer {
+	cacheKey := pacerCacheKey(config.Name, key)
+	var pacer pacertypes.Pacer
+	pacerItem, ok := c.pacersByKey.Get(cacheKey)
+	if !ok {
+		pacer = config.PacerFactory.New(key)
+	} else {
+		pacer = pacerItem.(pacertypes.Pacer)
+	}
+	c.pacersByKey.Set(cacheKey, pacer, 0)
+
+	return pacer
+}
+
+// Delete all cached pacers created for config name.
+func (c *podClassifier) deletePacersLocked(name string) {
+	for cacheKey := range c.pacersByKey.Items() {
+		if configName, ok := pacerCacheKeyConfigName(cacheKey); ok && configName == name {
+			c.pacersByKey.Delete(cacheKey)
+		}
+	}
+}
+
+// Rebuild pacers of all groups that use config such that they pick up
+// the new configuration while maintaining the same group identity.
+func (c *podClassifier) refreshGroupsLocked(config configEntry, logger logr.Logger) {
+	c.deletePacersLocked(config.Name)
+
+	for _, item := range c.groupsByID.Items() {
+		group := item.Object.(*groupEntry)
+		found := false
+		for i := range group.configs {
+			if group.configs[i].Name == config.Name {
+				group.configs[i] = config
+				found = true
+			}
+		}
+		if !found {
+			continue
+		}
+
+		logger.V(1).Info("refreshing group pacers", "id", group.id, "config", config.Name)
+		pacers := make([]pacertypes.Pacer, 0)
+		for i := range group.configs {
+			pacers = append(pacers, c.getPacerLocked(group.configs[i], group.keys[i]))
+		}
+		group.compositePacer = pacer.NewComposite(group.id, pacers)
+	}
+}
+
+// Get the group ID of keys of matchedConfigs. Keys and config names are
+// quoted such that different keys cannot have the same ID whatever they
+// contain.
+func (c *podClassifier) calculateGroupID(keys []string, matchedConfigs []configEntry) string {
+	quotedKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		quotedKeys = append(quotedKeys, strconv.Quote(key))
+	}
 	configNames := make([]string, 0)
 	for _, config := range matchedConfigs {
-		configNames = append(configNames, config.Name)
+		configNames = append(configNames, strconv.Quote(config.Name))
 	}
-	pacerNames := make([]string, 0)
-	for _, pacer := range pacers {
-		pacerNames = append(pacerNames, pacer.ID())
-	}
-	id := fmt.Sprintf("[%s](%s)", strings.Join(pacerNames, ","), strings.Join(configNames, ","))
+	id := fmt.Sprintf("[%s](%s)", strings.Join(quotedKeys, ","), strings.Join(configNames, ","))
 	hash := md5.New()
 	hash.Write([]byte(id))
 	return hex.EncodeToString(hash.Sum(nil))
 }
 
 func (c *podClassifier) calculateAggregateGroupPolicy(matchedConfigs []configEntry) (policies types.StaggeringGroupPolicies) {
+	hasReadiness := false
 	for _, config := range matchedConfigs {
 		// find the minimum configured max blocked duration
 		if config.MaxBlockedDuration > 0 &&
@@ -256,7 +488,92 @@ func (c *podClassifier) calculateAggregateGroupPolicy(matchedConfigs []configEnt
 				config.MaxBlockedDuration < policies.MaxBlockedDuration) {
 			policies.MaxBlockedDuration = config.MaxBlockedDuration
 		}
+		// first config that specifies an unblocker wins.
+		if len(policies.Unblocker) == 0 {
+			policies.Unblocker = config.Unblocker
+		}
+		// first config that specifies readiness wins.
+		if !hasReadiness && config.Readiness != nil {
+			policies.Readiness = *config.Readiness
+			hasReadiness = true
+		}
 	}
 
 	return
 }
+
+// Get unique names of configs in order. Configs are repeated for each of
+// their grouping keys.
+func configNames(configs []configEntry) []string {
+	names := make([]string, 0, len(configs))
+	for _, config := range configs {
+		if !slices.Contains(names, config.Name) {
+			names = append(names, config.Name)
+		}
+	}
+	return names
+}
+
+// Check if config and other can select pods in the same namespaces. Namespace
+// selectors are not known in advance and are assumed to overlap.
+func namespacesOverlap(config, other configtypes.StaggerGroup) bool {
+	namespaces, restricted := allowedNamespaces(config)
+	otherNamespaces, otherRestricted := allowedNamespaces(other)
+	switch {
+	case !restricted && !otherRestricted:
+		// excluded namespaces are finite, both select all other namespaces.
+		return true
+	case !restricted:
+		return slices.ContainsFunc(otherNamespaces, func(namespace string) bool {
+			return !slices.Contains(config.ExcludedNamespaces, namespace)
+		})
+	case !otherRestricted:
+		return slices.ContainsFunc(namespaces, func(namespace string) bool {
+			return !slices.Contains(other.ExcludedNamespaces, namespace)
+		})
+	default:
+		return slices.ContainsFunc(namespaces, func(namespace string) bool {
+			return slices.Contains(otherNamespaces, namespace)
+		})
+	}
+}
+
+// Get the namespaces config selects pods in. restricted is false if config
+// selects pods in all namespaces but its excluded ones.
+func allowedNamespaces(config configtypes.StaggerGroup) (namespaces []string, restricted bool) {
+	switch {
+	case len(config.Namespace) > 0:
+		namespaces = []string{config.Namespace}
+		if len(config.Namespaces) > 0 && !slices.Contains(config.Namespaces, config.Namespace) {
+			namespaces = nil
+		}
+	case len(config.Namespaces) > 0:
+		namespaces = config.Namespaces
+	default:
+		return nil, false
+	}
+
+	return slices.DeleteFunc(slices.Clone(namespaces), func(namespace string) bool {
+		return slices.Contains(config.ExcludedNamespaces, namespace)
+	}), true
+}
+
+// Get a pacer cache key of config name and grouping key. Config names are
+// quoted such that they can be told apart from keys whatever they contain.
+func pacerCacheKey(configName, key string) string {
+	return strconv.Quote(configName) + key
+}
+
+// Get the config name of a pacer cache key.
+func pacerCacheKeyConfigName(cacheKey string) (string, bool) {
+	quoted, err := strconv.QuotedPrefix(cacheKey)
+	if err != nil {
+		return "", false
+	}
+	configName, err := strconv.Unquote(quoted)
+	if err != nil {
+		return "", false
+	}
+
+	return configName, true
+}
@@ -4,8 +4,11 @@ package controller
 
 import (
 	"straggler/pkg/config/types"
+	controllermocks "straggler/pkg/controller/mocks"
+	controllertypes "straggler/pkg/controller/types"
 	"straggler/pkg/pacer/mocks"
 	"testing"
+	"time"
 
 	"github.com/go-logr/zapr"
 	"github.com/stretchr/testify/require"
@@ -28,7 +31,7 @@ func TestClassifierClassifySuccess(t *testing.T) {
 	pacerFactory := mocks.NewMockPacerFactory(mockCtrl)
 	pacerFactory.EXPECT().New(testNamespace).Return(pacer)
 
-	classifier := NewPodClassifier()
+	classifier := NewPodClassifier(nil)
 	err := classifier.AddConfig(types.StaggerGroup{
 		GroupingExpression: ".metadata.namespace",
 		PacerFactory:       pacerFactory,
@@ -48,6 +51,50 @@ func TestClassifierClassifySuccess(t *testing.T) {
 	require.NotNil(t, result)
 }
 
+func TestClassifierGetGroupID(t *testing.T) {
+	zlog, _ := zap.NewDevelopment()
+	logger := zapr.NewLogger(zlog)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	testNamespace := "testnamespace"
+	pacer := mocks.NewMockPacer(mockCtrl)
+	pacer.EXPECT().ID().Return("pacer").AnyTimes()
+	pacerFactory := mocks.NewMockPacerFactory(mockCtrl)
+
+	classifier := NewPodClassifier(nil)
+	err := classifier.AddConfig(types.StaggerGroup{
+		GroupingExpression: ".metadata.namespace",
+		LabelSelector:      &v1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+		PacerFactory:       pacerFactory,
+	}, logger)
+	require.NoError(t, err)
+
+	// no pacers or groups are created
+	pod := corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Namespace: testNamespace, Labels: map[string]string{"app": "test"}},
+	}
+	id, err := classifier.GetGroupID(pod.ObjectMeta, pod.Spec, logger)
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+	result, err := classifier.ClassifyByGroupID(id, logger)
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	// same id as classification
+	pacerFactory.EXPECT().New(testNamespace).Return(pacer)
+	result, err = classifier.Classify(pod.ObjectMeta, pod.Spec, logger)
+	require.NoError(t, err)
+	require.Equal(t, id, result.ID)
+
+	// no policy
+	pod.Labels = nil
+	id, err = classifier.GetGroupID(pod.ObjectMeta, pod.Spec, logger)
+	require.NoError(t, err)
+	require.Empty(t, id)
+}
+
 func TestClassifierClassifyMultiSuccess(t *testing.T) {
 	zlog, _ := zap.NewDevelopment()
 	logger := zapr.NewLogger(zlog)
@@ -69,7 +116,7 @@ func TestClassifierClassifyMultiSuccess(t *testing.T) {
 	pacerFactory2 := mocks.NewMockPacerFactory(mockCtrl)
 	pacerFactory2.EXPECT().New(testLabelvalue).Return(pacer2)
 
-	classifier := NewPodClassifier()
+	classifier := NewPodClassifier(nil)
 	err := classifier.AddConfig(types.StaggerGroup{
 		Name:               "config1",
 		GroupingExpression: ".metadata.namespace",
@@ -100,9 +147,9 @@ func TestClassifierSkipSelector(t *testing.T) {
 	zlog, _ := zap.NewDevelopment()
 	logger := zapr.NewLogger(zlog)
 
-	classifier := NewPodClassifier()
+	classifier := NewPodClassifier(nil)
 	err := classifier.AddConfig(types.StaggerGroup{
-		LabelSelector:      map[string]string{"key": "value"},
+		LabelSelector:      &v1.LabelSelector{MatchLabels: map[string]string{"key": "value"}},
 		GroupingExpression: ".metadata.name",
 	}, logger)
 	require.NoError(t, err)
@@ -117,10 +164,10 @@ func TestClassifierSkipBypassSelector(t *testing.T) {
 	zlog, _ := zap.NewDevelopment()
 	logger := zapr.NewLogger(zlog)
 
-	classifier := NewPodClassifier()
+	classifier := NewPodClassifier(nil)
 	err := classifier.AddConfig(types.StaggerGroup{
-		LabelSelector:       map[string]string{"key": "value"},
-		BypassLabelSelector: map[string]string{"bypass": "this"},
+		LabelSelector:       &v1.LabelSelector{MatchLabels: map[string]string{"key": "value"}},
+		BypassLabelSelector: &v1.LabelSelector{MatchLabels: map[string]string{"bypass": "this"}},
 		GroupingExpression:  ".metadata.name",
 	}, logger)
 	require.NoError(t, err)
@@ -138,11 +185,192 @@ func TestClassifierSkipBypassSelector(t *testing.T) {
 	require.Nil(t, result)
 }
 
+func TestClassifierMatchExpressions(t *testing.T) {
+	zlog, _ := zap.NewDevelopment()
+	logger := zapr.NewLogger(zlog)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pacer := mocks.NewMockPacer(mockCtrl)
+	pacer.EXPECT().ID().Return("pacer").AnyTimes()
+	pacerFactory := mocks.NewMockPacerFactory(mockCtrl)
+	pacerFactory.EXPECT().New("frontend").Return(pacer)
+
+	classifier := NewPodClassifier(nil)
+	err := classifier.AddConfig(types.StaggerGroup{
+		LabelSelector: &v1.LabelSelector{
+			MatchExpressions: []v1.LabelSelectorRequirement{
+				{Key: "tier", Operator: v1.LabelSelectorOpIn, Values: []string{"frontend", "backend"}},
+			},
+		},
+		BypassLabelSelector: &v1.LabelSelector{
+			MatchExpressions: []v1.LabelSelectorRequirement{
+				{Key: "bypass", Operator: v1.LabelSelectorOpExists},
+			},
+		},
+		GroupingExpression: ".metadata.labels.tier",
+		PacerFactory:       pacerFactory,
+	}, logger)
+	require.NoError(t, err)
+
+	pod := corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{
+			Labels: map[string]string{"tier": "frontend"},
+		},
+	}
+	result, err := classifier.Classify(pod.ObjectMeta, pod.Spec, logger)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+
+	pod.Labels["bypass"] = ""
+	result, err = classifier.Classify(pod.ObjectMeta, pod.Spec, logger)
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	pod.Labels = map[string]string{"tier": "data"}
+	result, err = classifier.Classify(pod.ObjectMeta, pod.Spec, logger)
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestClassifierNamespaces(t *testing.T) {
+	zlog, _ := zap.NewDevelopment()
+	logger := zapr.NewLogger(zlog)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pacer := mocks.NewMockPacer(mockCtrl)
+	pacer.EXPECT().ID().Return("pacer").AnyTimes()
+	pacerFactory := mocks.NewMockPacerFactory(mockCtrl)
+	pacerFactory.EXPECT().New("prod1").Return(pacer)
+	namespaces := controllermocks.NewMockNamespaceLabeler(mockCtrl)
+	namespaces.EXPECT().NamespaceLabels(gomock.Any(), "prod1").Return(map[string]string{"env": "prod"}, nil)
+	namespaces.EXPECT().NamespaceLabels(gomock.Any(), "dev1").Return(map[string]string{"env": "dev"}, nil)
+
+	classifier := NewPodClassifier(namespaces)
+	err := classifier.AddConfig(types.StaggerGroup{
+		NamespaceSelector: &v1.LabelSelector{
+			MatchLabels: map[string]string{"env": "prod"},
+		},
+		ExcludedNamespaces: []string{"prod2"},
+		GroupingExpression: ".metadata.namespace",
+		PacerFactory:       pacerFactory,
+	}, logger)
+	require.NoError(t, err)
+
+	for namespace, matches := range map[string]bool{
+		"prod1": true,
+		"prod2": false,
+		"dev1":  false,
+	} {
+		pod := corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Namespace: namespace},
+		}
+		result, err := classifier.Classify(pod.ObjectMeta, pod.Spec, logger)
+		require.NoError(t, err)
+		require.Equal(t, matches, result != nil, namespace)
+	}
+}
+
+func TestClassifierMultipleKeys(t *testing.T) {
+	zlog, _ := zap.NewDevelopment()
+	logger := zapr.NewLogger(zlog)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pacer1 := mocks.NewMockPacer(mockCtrl)
+	pacer1.EXPECT().ID().Return("pacer1").AnyTimes()
+	pacer2 := mocks.NewMockPacer(mockCtrl)
+	pacer2.EXPECT().ID().Return("pacer2").AnyTimes()
+	pacerFactory := mocks.NewMockPacerFactory(mockCtrl)
+	pacerFactory.EXPECT().New("image1").Return(pacer1)
+	pacerFactory.EXPECT().New("image2").Return(pacer2)
+
+	classifier := NewPodClassifier(nil)
+	err := classifier.AddConfig(types.StaggerGroup{
+		Name:                  "config1",
+		GroupingCELExpression: "pod.spec.containers.map(c, c.image)",
+		PacerFactory:          pacerFactory,
+	}, logger)
+	require.NoError(t, err)
+
+	pod := corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "c1", Image: "image2"},
+				{Name: "c2", Image: "image1"},
+			},
+		},
+	}
+	result, err := classifier.Classify(pod.ObjectMeta, pod.Spec, logger)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, []string{"config1"}, result.Policies)
+
+	require.Contains(t, result.Pacer.ID(), "pacer1,pacer2")
+
+	// pods with a subset of keys are in a different group sharing pacers.
+	// pods are only counted in the group of all their keys so pacers do
+	// not see pods of other groups.
+	pod.Spec.Containers = pod.Spec.Containers[:1]
+	other, err := classifier.Classify(pod.ObjectMeta, pod.Spec, logger)
+	require.NoError(t, err)
+	require.NotNil(t, other)
+	require.NotEqual(t, result.ID, other.ID)
+	require.Contains(t, other.Pacer.ID(), "pacer2")
+	require.NotContains(t, other.Pacer.ID(), "pacer1")
+
+	// both jsonpath and cel expressions cannot be set.
+	err = classifier.AddConfig(types.StaggerGroup{
+		Name:                  "config2",
+		GroupingExpression:    ".metadata.name",
+		GroupingCELExpression: "pod.metadata.name",
+	}, logger)
+	require.Error(t, err)
+}
+
+func TestClassifierKeysWithSeparators(t *testing.T) {
+	zlog, _ := zap.NewDevelopment()
+	logger := zapr.NewLogger(zlog)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pacer := mocks.NewMockPacer(mockCtrl)
+	pacer.EXPECT().ID().Return("pacer").AnyTimes()
+	pacerFactory := mocks.NewMockPacerFactory(mockCtrl)
+	pacerFactory.EXPECT().New(gomock.Any()).Return(pacer).AnyTimes()
+
+	classifier := NewPodClassifier(nil)
+	err := classifier.AddConfig(types.StaggerGroup{
+		Name:                  "config1",
+		GroupingCELExpression: `pod.metadata.annotations.keys.split(";")`,
+		PacerFactory:          pacerFactory,
+	}, logger)
+	require.NoError(t, err)
+
+	// keys containing separators are not confused with other keys.
+	pod := corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Annotations: map[string]string{"keys": "a,b;c"}},
+	}
+	id, err := classifier.GetGroupID(pod.ObjectMeta, pod.Spec, logger)
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+	pod.Annotations["keys"] = "a;b,c"
+	other, err := classifier.GetGroupID(pod.ObjectMeta, pod.Spec, logger)
+	require.NoError(t, err)
+	require.NotEmpty(t, other)
+	require.NotEqual(t, id, other)
+}
+
 func TestClassifierSkipNoKey(t *testing.T) {
 	zlog, _ := zap.NewDevelopment()
 	logger := zapr.NewLogger(zlog)
 
-	classifier := NewPodClassifier()
+	classifier := NewPodClassifier(nil)
 	err := classifier.AddConfig(types.StaggerGroup{
 		// this won't match
 		GroupingExpression: ".metadata.name",
@@ -159,9 +387,229 @@ func TestClassifierBadJSONPath(t *testing.T) {
 	zlog, _ := zap.NewDevelopment()
 	logger := zapr.NewLogger(zlog)
 
-	classifier := NewPodClassifier()
+	classifier := NewPodClassifier(nil)
 	err := classifier.AddConfig(types.StaggerGroup{
 		GroupingExpression: "bad jsonpath",
 	}, logger)
 	require.Error(t, err)
 }
+
+func TestClassifierUpdateConfig(t *testing.T) {
+	zlog, _ := zap.NewDevelopment()
+	logger := zapr.NewLogger(zlog)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	testNamespace := "testnamespace"
+	pacer1 := mocks.NewMockPacer(mockCtrl)
+	pacerFactory1 := mocks.NewMockPacerFactory(mockCtrl)
+	pacerFactory1.EXPECT().New(testNamespace).Return(pacer1)
+	pacer2 := mocks.NewMockPacer(mockCtrl)
+	pacerFactory2 := mocks.NewMockPacerFactory(mockCtrl)
+	pacerFactory2.EXPECT().New(testNamespace).Return(pacer2)
+
+	classifier := NewPodClassifier(nil)
+	err := classifier.AddConfig(types.StaggerGroup{
+		Name:               "config1",
+		GroupingExpression: ".metadata.namespace",
+		PacerFactory:       pacerFactory1,
+	}, logger)
+	require.NoError(t, err)
+
+	pod := corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Namespace: testNamespace},
+	}
+	result, err := classifier.Classify(pod.ObjectMeta, pod.Spec, logger)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	groupID := result.ID
+
+	// same grouping expression for the same config must be accepted
+	err = classifier.UpdateConfig(types.StaggerGroup{
+		Name:               "config1",
+		GroupingExpression: ".metadata.namespace",
+		MaxBlockedDuration: time.Minute,
+		PacerFactory:       pacerFactory2,
+	}, logger)
+	require.NoError(t, err)
+
+	// existing group should keep its identity with new pacers and policies
+	result, err = classifier.ClassifyByGroupID(groupID, logger)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, time.Minute, result.GroupPolicies.MaxBlockedDuration)
+
+	result, err = classifier.Classify(pod.ObjectMeta, pod.Spec, logger)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, groupID, result.ID)
+
+	err = classifier.UpdateConfig(types.StaggerGroup{
+		Name:               "notfound",
+		GroupingExpression: ".metadata.name",
+	}, logger)
+	require.Error(t, err)
+}
+
+func TestClassifierRemoveConfig(t *testing.T) {
+	zlog, _ := zap.NewDevelopment()
+	logger := zapr.NewLogger(zlog)
+
+	classifier := NewPodClassifier(nil)
+	err := classifier.AddConfig(types.StaggerGroup{
+		Name:               "config1",
+		GroupingExpression: ".metadata.namespace",
+	}, logger)
+	require.NoError(t, err)
+
+	err = classifier.RemoveConfig("config1", logger)
+	require.NoError(t, err)
+	err = classifier.RemoveConfig("config1", logger)
+	require.Error(t, err)
+
+	// expression can now be reused
+	err = classifier.AddConfig(types.StaggerGroup{
+		Name:               "config2",
+		GroupingExpression: ".metadata.namespace",
+	}, logger)
+	require.NoError(t, err)
+}
+
+func TestClassifierDuplicateExpression(t *testing.T) {
+	zlog, _ := zap.NewDevelopment()
+	logger := zapr.NewLogger(zlog)
+
+	classifier := NewPodClassifier(nil)
+	for _, config := range []types.StaggerGroup{
+		{Name: "team-a/policy", Namespace: "team-a"},
+		{Name: "team-b/policy", Namespace: "team-b"},
+	} {
+		config.GroupingExpression = ".spec.containers[0].image"
+		// policies of different namespaces never select the same pods.
+		require.NoError(t, classifier.AddConfig(config, logger))
+	}
+
+	for _, config := range []types.StaggerGroup{
+		{Name: "team-a/other", Namespace: "team-a"},
+		{Name: "cluster:policy"},
+		{Name: "file", Namespaces: []string{"team-c", "team-b"}},
+		{Name: "excluded", ExcludedNamespaces: []string{"team-a"}},
+	} {
+		config.GroupingExpression = ".spec.containers[0].image"
+		require.Error(t, classifier.AddConfig(config, logger), config.Name)
+	}
+
+	// allowed and excluded namespaces are taken into account.
+	for _, config := range []types.StaggerGroup{
+		{Name: "allowed", Namespaces: []string{"team-c", "team-d"}},
+		{Name: "excluded", ExcludedNamespaces: []string{"team-a", "team-b", "team-c", "team-d"}},
+		{Name: "team-a/excluded", Namespace: "team-a", ExcludedNamespaces: []string{"team-a"}},
+		{Name: "team-b/other", Namespace: "team-b", Namespaces: []string{"team-e"}},
+	} {
+		config.GroupingExpression = ".spec.containers[0].image"
+		require.NoError(t, classifier.AddConfig(config, logger), config.Name)
+	}
+}
+
+func TestClassifierRemoveConfigPacers(t *testing.T) {
+	zlog, _ := zap.NewDevelopment()
+	logger := zapr.NewLogger(zlog)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	testNamespace := "team-a"
+	pacer1 := mocks.NewMockPacer(mockCtrl)
+	pacer1.EXPECT().ID().Return("pacer1").AnyTimes()
+	pacerFactory1 := mocks.NewMockPacerFactory(mockCtrl)
+	pacerFactory1.EXPECT().New(testNamespace).Return(pacer1)
+	pacer2 := mocks.NewMockPacer(mockCtrl)
+	pacer2.EXPECT().ID().Return("pacer2").AnyTimes()
+	pacerFactory2 := mocks.NewMockPacerFactory(mockCtrl)
+	pacerFactory2.EXPECT().New(testNamespace).Return(pacer2).Times(1)
+
+	// config names where one is a prefix of the other.
+	classifier := NewPodClassifier(nil)
+	require.NoError(t, classifier.AddConfig(types.StaggerGroup{
+		Name:               "team-a",
+		GroupingExpression: ".metadata.namespace",
+		PacerFactory:       pacerFactory1,
+	}, logger))
+	require.NoError(t, classifier.AddConfig(types.StaggerGroup{
+		Name:               "team-a/policy",
+		Namespace:          testNamespace,
+		GroupingExpression: ".metadata.labels.app",
+		PacerFactory:       pacerFactory2,
+	}, logger))
+
+	pod := corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace: testNamespace,
+			Labels:    map[string]string{"app": testNamespace},
+		},
+	}
+	_, err := classifier.Classify(pod.ObjectMeta, pod.Spec, logger)
+	require.NoError(t, err)
+
+	// pacers of the other config must be kept.
+	require.NoError(t, classifier.RemoveConfig("team-a", logger))
+	result, err := classifier.Classify(pod.ObjectMeta, pod.Spec, logger)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, []string{"team-a/policy"}, result.Policies)
+}
+
+func TestClassifierRestoreGroup(t *testing.T) {
+	zlog, _ := zap.NewDevelopment()
+	logger := zapr.NewLogger(zlog)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	testNamespace := "testnamespace"
+	pacer := mocks.NewMockPacer(mockCtrl)
+	pacer.EXPECT().ID().Return("pacer").AnyTimes()
+	pacerFactory := mocks.NewMockPacerFactory(mockCtrl)
+	pacerFactory.EXPECT().New(testNamespace).Return(pacer).Times(2)
+	config := types.StaggerGroup{
+		Name:               "config1",
+		GroupingExpression: ".metadata.namespace",
+		PacerFactory:       pacerFactory,
+	}
+
+	classifier := NewPodClassifier(nil)
+	require.NoError(t, classifier.AddConfig(config, logger))
+	pod := corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Namespace: testNamespace},
+	}
+	result, err := classifier.Classify(pod.ObjectMeta, pod.Spec, logger)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, []controllertypes.GroupMember{{Policy: "config1", Key: testNamespace}}, result.Members)
+
+	// a new classifier, such as after a restart, does not know the group
+	// until restored.
+	restarted := NewPodClassifier(nil)
+	require.NoError(t, restarted.AddConfig(config, logger))
+	restored, err := restarted.ClassifyByGroupID(result.ID, logger)
+	require.NoError(t, err)
+	require.Nil(t, restored)
+
+	restored, err = restarted.RestoreGroup(result.ID, []controllertypes.GroupMember{{Policy: "config1", Key: "other"}}, logger)
+	require.NoError(t, err)
+	require.Nil(t, restored)
+	restored, err = restarted.RestoreGroup(result.ID, []controllertypes.GroupMember{{Policy: "notfound", Key: testNamespace}}, logger)
+	require.NoError(t, err)
+	require.Nil(t, restored)
+
+	restored, err = restarted.RestoreGroup(result.ID, result.Members, logger)
+	require.NoError(t, err)
+	require.NotNil(t, restored)
+	require.Equal(t, result.ID, restored.ID)
+	require.Equal(t, result.Policies, restored.Policies)
+
+	restored, err = restarted.ClassifyByGroupID(result.ID, logger)
+	require.NoError(t, err)
+	require.NotNil(t, restored)
+}
@@ -0,0 +1,56 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package controller
+
+import (
+	"context"
+
+	"straggler/pkg/controller/types"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	enabledValue = "1"
+)
+
+var _ types.EnableChecker = &enableChecker{}
+
+type enableChecker struct {
+	enableLabel string
+	namespaces  types.NamespaceLabeler
+}
+
+// Create a new enable checker. Pods are enabled if they have enableLabel set
+// to "1". Pods without enableLabel are enabled if their namespace has a label
+// enableLabel set to "1". Namespaces are only enabled by label since admission
+// webhooks can only select namespaces by their labels. Pods in enabled
+// namespaces can opt out by setting enableLabel to any other value. If
+// namespaces is nil, only pod labels are checked.
+func NewEnableChecker(enableLabel string, namespaces types.NamespaceLabeler) types.EnableChecker {
+	return &enableChecker{
+		enableLabel: enableLabel,
+		namespaces:  namespaces,
+	}
+}
+
+func (e *enableChecker) IsEnabled(ctx context.Context, namespace string, labels map[string]string, logger logr.Logger) bool {
+	if value, ok := labels[e.enableLabel]; ok {
+		if value != enabledValue {
+			logger.V(1).Info("pod opted out by enable label", "label", e.enableLabel, "value", value)
+			return false
+		}
+		return true
+	}
+	if e.namespaces == nil || len(namespace) == 0 {
+		return false
+	}
+
+	namespaceLabels, err := e.namespaces.NamespaceLabels(ctx, namespace)
+	if err != nil {
+		logger.Info("failed to get namespace labels", "namespace", namespace, "error", err)
+		return false
+	}
+
+	return namespaceLabels[e.enableLabel] == enabledValue
+}
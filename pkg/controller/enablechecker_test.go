@@ -0,0 +1,73 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"straggler/pkg/controller/mocks"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEnableCheckerPodLabel(t *testing.T) {
+	logger := testr.New(t)
+
+	checker := NewEnableChecker(DefaultEnableLabel, nil)
+	require.True(t, checker.IsEnabled(context.Background(), "test", map[string]string{DefaultEnableLabel: "1"}, logger))
+	require.False(t, checker.IsEnabled(context.Background(), "test", map[string]string{DefaultEnableLabel: "0"}, logger))
+	require.False(t, checker.IsEnabled(context.Background(), "test", nil, logger))
+}
+
+func TestEnableCheckerNamespace(t *testing.T) {
+	logger := testr.New(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	namespaces := mocks.NewMockNamespaceLabeler(mockCtrl)
+	namespaces.EXPECT().NamespaceLabels(gomock.Any(), "labeled").Return(map[string]string{DefaultEnableLabel: "1"}, nil).AnyTimes()
+	namespaces.EXPECT().NamespaceLabels(gomock.Any(), "disabled").Return(map[string]string{"other": "1"}, nil).AnyTimes()
+	namespaces.EXPECT().NamespaceLabels(gomock.Any(), "missing").Return(nil, fmt.Errorf("not found")).AnyTimes()
+
+	checker := NewEnableChecker(DefaultEnableLabel, namespaces)
+	for namespace, enabled := range map[string]bool{
+		"labeled":  true,
+		"disabled": false,
+		"missing":  false,
+	} {
+		require.Equal(t, enabled, checker.IsEnabled(context.Background(), namespace, map[string]string{"app": "web"}, logger), namespace)
+	}
+
+	// pods can opt out of enabled namespaces.
+	require.False(t, checker.IsEnabled(context.Background(), "labeled", map[string]string{DefaultEnableLabel: "0"}, logger))
+	// and opt in regardless of namespace.
+	require.True(t, checker.IsEnabled(context.Background(), "disabled", map[string]string{DefaultEnableLabel: "1"}, logger))
+}
+
+func TestEnableCheckerNamespaceLabelOnly(t *testing.T) {
+	logger := testr.New(t)
+
+	// admission webhooks only select namespaces by label, so namespaces
+	// are not enabled by annotation.
+	client := fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "labeled",
+			Labels: map[string]string{DefaultEnableLabel: "1"},
+		}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "annotated",
+			Annotations: map[string]string{DefaultEnableLabel: "1"},
+		}},
+	).Build()
+
+	checker := NewEnableChecker(DefaultEnableLabel, NewNamespaceLabeler(client))
+	require.True(t, checker.IsEnabled(context.Background(), "labeled", nil, logger))
+	require.False(t, checker.IsEnabled(context.Background(), "annotated", nil, logger))
+}
@@ -113,14 +113,18 @@ func (f *flightTracker) WaitOne(ctx context.Context, key string, object metav1.O
 	if len == 1 {
 		// no previous flight, return immediately
 		logger.V(1).Info("no previous flight, returning immediately")
+		flightWaitDuration.WithLabelValues("immediate").Observe(0)
 		return nil
 	}
 
 	logger.V(1).Info("awaiting a flight to land", "inflight", len)
+	start := time.Now()
 	select {
 	case <-waitChan:
+		flightWaitDuration.WithLabelValues("landed").Observe(time.Since(start).Seconds())
 		return nil
 	case <-ctx.Done():
+		flightWaitDuration.WithLabelValues("timeout").Observe(time.Since(start).Seconds())
 		return ctx.Err()
 	}
 }
@@ -234,6 +238,7 @@ func (f *flightTracker) startEvicter(logger logr.Logger) {
 					flight := el.Value.(*flight)
 					if time.Since(flight.timestamp) > f.maxFlightDuration {
 						logger.Info("force landing flight", "flight", flight.object, "key", key)
+						flightForceLandingsTotal.Inc()
 						go func() {
 							logger.Info("writing flight chan", "flight", flight.object, "key", key)
 							flightList.landedChan <- nil
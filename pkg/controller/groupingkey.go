@@ -0,0 +1,202 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	celtypes "github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+	"github.com/ohler55/ojg/jp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	celPodVariable = "pod"
+)
+
+// Evaluates pods to their grouping keys.
+type groupingKeyEvaluator interface {
+	// Get sorted unique non-empty grouping keys of pod. No keys are returned
+	// if pod cannot be grouped.
+	Keys(pod *evaluationPod) ([]string, error)
+}
+
+// A pod being evaluated with its unstructured form lazily converted once
+// for all evaluators.
+type evaluationPod struct {
+	corev1.Pod
+
+	unstructured map[string]interface{}
+}
+
+func (p *evaluationPod) getUnstructured() (map[string]interface{}, error) {
+	if p.unstructured != nil {
+		return p.unstructured, nil
+	}
+	unstructured, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&p.Pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert pod: %v", err)
+	}
+	p.unstructured = unstructured
+
+	return unstructured, nil
+}
+
+type jsonPathEvaluator struct {
+	expr jp.Expr
+}
+
+func newJSONPathEvaluator(expression string) (*jsonPathEvaluator, error) {
+	expr, err := jp.ParseString(expression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse jsonpath %s: %v", expression, err)
+	}
+
+	return &jsonPathEvaluator{expr: expr}, nil
+}
+
+func (e *jsonPathEvaluator) Keys(pod *evaluationPod) ([]string, error) {
+	keys := make([]string, 0)
+	for _, result := range e.expr.Get(pod.Pod) {
+		keys = append(keys, fmt.Sprintf("%v", result))
+	}
+
+	return normalizeKeys(keys), nil
+}
+
+type celEvaluator struct {
+	program cel.Program
+	// ids of field selection expressions, such as pod.metadata.name or
+	// pod.metadata.labels["app"].
+	fieldSelections map[int64]struct{}
+}
+
+// Create a new CEL grouping key evaluator. The expression gets the pod as
+// variable pod and must return a string or a list of strings.
+func newCELEvaluator(expression string) (*celEvaluator, error) {
+	env, err := cel.NewEnv(
+		cel.Variable(celPodVariable, cel.DynType),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cel environment: %v", err)
+	}
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, fmt.Errorf("failed to compile cel expression %s: %v", expression, issues.Err())
+	}
+	outputType := ast.OutputType()
+	if !outputType.IsAssignableType(cel.StringType) && !outputType.IsAssignableType(cel.ListType(cel.StringType)) {
+		return nil, fmt.Errorf("cel expression %s must return a string or a list of strings, got %v", expression, outputType)
+	}
+	// evaluation state is tracked to find failed field selections.
+	program, err := env.Program(ast, cel.EvalOptions(cel.OptTrackState))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cel program %s: %v", expression, err)
+	}
+
+	return &celEvaluator{
+		program:         program,
+		fieldSelections: celFieldSelections(ast),
+	}, nil
+}
+
+// Get ids of expressions in ast that select fields by name. Presence tests
+// using has() are not included.
+func celFieldSelections(ast *cel.Ast) map[int64]struct{} {
+	selections := make(map[int64]struct{})
+	matcher := func(expr celast.NavigableExpr) bool {
+		switch expr.Kind() {
+		case celast.SelectKind:
+			return !expr.AsSelect().IsTestOnly()
+		case celast.CallKind:
+			call := expr.AsCall()
+			if call.FunctionName() != operators.Index || len(call.Args()) != 2 {
+				return false
+			}
+			key := call.Args()[1]
+			return key.Kind() == celast.LiteralKind && key.AsLiteral().Type() == celtypes.StringType
+		}
+		return false
+	}
+	for _, expr := range celast.MatchDescendants(celast.NavigateAST(ast.NativeRep()), matcher) {
+		selections[expr.ID()] = struct{}{}
+	}
+
+	return selections
+}
+
+// Check that expression is a valid CEL grouping expression.
+func ValidateCELGroupingExpression(expression string) error {
+	_, err := newCELEvaluator(expression)
+	return err
+}
+
+func (e *celEvaluator) Keys(pod *evaluationPod) ([]string, error) {
+	unstructured, err := pod.getUnstructured()
+	if err != nil {
+		return nil, err
+	}
+	value, details, err := e.program.Eval(map[string]interface{}{
+		celPodVariable: unstructured,
+	})
+	if err != nil {
+		// missing fields are treated as no keys similar to jsonpath.
+		if e.failedFieldSelection(details) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to evaluate cel expression: %v", err)
+	}
+
+	return celValueKeys(value)
+}
+
+// Check if any field selection failed during evaluation. Field selections
+// only fail if fields are missing.
+func (e *celEvaluator) failedFieldSelection(details *cel.EvalDetails) bool {
+	if details == nil {
+		return false
+	}
+	for id := range e.fieldSelections {
+		if value, ok := details.State().Value(id); ok && celtypes.IsError(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Convert a CEL string or list of strings result to keys.
+func celValueKeys(value ref.Val) ([]string, error) {
+	switch v := value.Value().(type) {
+	case string:
+		return normalizeKeys([]string{v}), nil
+	}
+
+	native, err := value.ConvertToNative(reflect.TypeOf([]string{}))
+	if err != nil {
+		return nil, fmt.Errorf("cel expression must return a string or a list of strings, got %v", value.Type())
+	}
+
+	return normalizeKeys(native.([]string)), nil
+}
+
+// Sort keys and remove empty and duplicate ones.
+func normalizeKeys(keys []string) []string {
+	normalized := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if len(key) > 0 {
+			normalized = append(normalized, key)
+		}
+	}
+	slices.Sort(normalized)
+
+	return slices.Compact(normalized)
+}
@@ -0,0 +1,92 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newGroupingTestPod() *evaluationPod {
+	return &evaluationPod{
+		Pod: corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test",
+				Labels:    map[string]string{"app": "web"},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "web", Image: "registry.example.com/web:1"},
+					{Name: "sidecar", Image: "docker.io/sidecar:1"},
+					{Name: "web2", Image: "registry.example.com/web:1"},
+				},
+			},
+		},
+	}
+}
+
+func TestJSONPathEvaluatorKeys(t *testing.T) {
+	evaluator, err := newJSONPathEvaluator(".spec.containers[*].image")
+	require.NoError(t, err)
+	keys, err := evaluator.Keys(newGroupingTestPod())
+	require.NoError(t, err)
+	require.Equal(t, []string{"docker.io/sidecar:1", "registry.example.com/web:1"}, keys)
+
+	evaluator, err = newJSONPathEvaluator(".metadata.labels.missing")
+	require.NoError(t, err)
+	keys, err = evaluator.Keys(newGroupingTestPod())
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	_, err = newJSONPathEvaluator("[[[")
+	require.Error(t, err)
+}
+
+func TestCELEvaluatorKeys(t *testing.T) {
+	testCases := []struct {
+		expression string
+		keys       []string
+	}{
+		{`pod.metadata.namespace`, []string{"test"}},
+		{`pod.spec.containers.map(c, c.image.split("/")[0])`, []string{"docker.io", "registry.example.com"}},
+		{`pod.metadata.namespace + "/" + pod.metadata.labels.app`, []string{"test/web"}},
+		// missing fields yield no keys.
+		{`pod.metadata.labels.missing`, nil},
+		{`pod.spec.missing.name`, nil},
+		{`pod.metadata.labels["missing"]`, nil},
+		{`pod.spec.containers.map(c, c.missing)`, nil},
+		{`has(pod.metadata.labels.missing) ? pod.metadata.labels.missing : ""`, []string{}},
+	}
+	for _, testCase := range testCases {
+		evaluator, err := newCELEvaluator(testCase.expression)
+		require.NoError(t, err, testCase.expression)
+		keys, err := evaluator.Keys(newGroupingTestPod())
+		require.NoError(t, err, testCase.expression)
+		require.Equal(t, testCase.keys, keys, testCase.expression)
+	}
+
+	// dynamic results of other types are rejected.
+	evaluator, err := newCELEvaluator(`pod.spec.containers`)
+	require.NoError(t, err)
+	_, err = evaluator.Keys(newGroupingTestPod())
+	require.Error(t, err)
+
+	// other runtime errors are not treated as missing fields.
+	for _, expression := range []string{
+		`pod.spec.containers.map(c, c.image)[5]`,
+		`string(int(pod.metadata.labels.app))`,
+		`pod.spec.containers[5]`,
+	} {
+		evaluator, err := newCELEvaluator(expression)
+		require.NoError(t, err, expression)
+		_, err = evaluator.Keys(newGroupingTestPod())
+		require.Error(t, err, expression)
+	}
+
+	for _, expression := range []string{`pod.metadata.`, `1 + 2`, `[1, 2]`} {
+		require.Error(t, ValidateCELGroupingExpression(expression), expression)
+	}
+}
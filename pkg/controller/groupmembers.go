@@ -0,0 +1,44 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"straggler/pkg/controller/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Store group members in object annotations. Nothing is stored if there
+// are no members.
+func setGroupMembers(objectMeta *metav1.ObjectMeta, members []types.GroupMember) error {
+	if len(members) == 0 {
+		return nil
+	}
+	bytes, err := json.Marshal(members)
+	if err != nil {
+		return fmt.Errorf("failed to marshal group members: %v", err)
+	}
+	if objectMeta.Annotations == nil {
+		objectMeta.Annotations = make(map[string]string)
+	}
+	objectMeta.Annotations[DefaultGroupMembersAnnotation] = string(bytes)
+
+	return nil
+}
+
+// Get group members stored in object annotations, or nil if there are none.
+func getGroupMembers(objectMeta *metav1.ObjectMeta) ([]types.GroupMember, error) {
+	value, ok := objectMeta.Annotations[DefaultGroupMembersAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	members := make([]types.GroupMember, 0)
+	if err := json.Unmarshal([]byte(value), &members); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal group members: %v", err)
+	}
+
+	return members, nil
+}
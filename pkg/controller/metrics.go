@@ -0,0 +1,107 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package controller
+
+import (
+	"time"
+
+	"straggler/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	admissionOutcomeAdmitted = "admitted"
+	admissionOutcomeBlocked  = "blocked"
+	admissionOutcomeBypassed = "bypassed"
+	admissionOutcomeErrored  = "errored"
+
+	unblockResultSuccess = "success"
+	unblockResultError   = "error"
+
+	releaseReasonPaced              = "paced"
+	releaseReasonMaxBlockedDuration = "maxBlockedDuration"
+)
+
+var (
+	admissionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "admission",
+			Name:      "pods_total",
+			Help:      "number of admitted pods by policy and outcome",
+		},
+		[]string{"policy", "outcome"})
+	unblocksTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "reconciler",
+			Name:      "unblocks_total",
+			Help:      "number of pod unblocking attempts by unblocker and result",
+		},
+		[]string{"unblocker", "result"})
+	unblockDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "reconciler",
+			Name:      "unblock_duration_seconds",
+			Help:      "latency of pod unblocking by unblocker",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"unblocker"})
+	blockedDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "reconciler",
+			Name:      "pod_blocked_duration_seconds",
+			Help:      "time pods spent blocked from creation until release by release reason",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 14),
+		},
+		[]string{"reason"})
+	flightWaitDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "flight_tracker",
+			Name:      "wait_duration_seconds",
+			Help:      "time admission waited for in flight pods to land by result",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 10),
+		},
+		[]string{"result"})
+	flightForceLandingsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "flight_tracker",
+			Name:      "force_landings_total",
+			Help:      "number of flights that exceeded max flight duration and were assumed landed",
+		})
+	validationViolationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "validation",
+			Name:      "violations_total",
+			Help:      "number of pod validation violations by reason and mode",
+		},
+		[]string{"reason", "mode"})
+)
+
+// Record an admission outcome once for each of policies.
+func recordAdmission(policies []string, outcome string) {
+	if len(policies) == 0 {
+		admissionsTotal.WithLabelValues("", outcome).Inc()
+		return
+	}
+	for _, policy := range policies {
+		admissionsTotal.WithLabelValues(policy, outcome).Inc()
+	}
+}
+
+// Record an unblocking attempt by unblocker that started at start.
+func recordUnblock(unblocker string, start time.Time, err error) {
+	result := unblockResultSuccess
+	if err != nil {
+		result = unblockResultError
+	}
+	unblocksTotal.WithLabelValues(unblocker, result).Inc()
+	unblockDuration.WithLabelValues(unblocker).Observe(time.Since(start).Seconds())
+}
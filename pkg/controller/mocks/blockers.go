@@ -14,9 +14,11 @@ import (
 	reflect "reflect"
 	types "straggler/pkg/config/types"
 	types0 "straggler/pkg/controller/types"
+	types1 "straggler/pkg/pacer/types"
 
 	logr "github.com/go-logr/logr"
 	gomock "go.uber.org/mock/gomock"
+	v11 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	v10 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
@@ -119,6 +121,20 @@ func (m *MockObjectRecorderFactory) EXPECT() *MockObjectRecorderFactoryMockRecor
 	return m.recorder
 }
 
+// AsyncRecorderForRootController mocks base method.
+func (m *MockObjectRecorderFactory) AsyncRecorderForRootController(object runtime.Object, logger logr.Logger) types0.ObjectRecorder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AsyncRecorderForRootController", object, logger)
+	ret0, _ := ret[0].(types0.ObjectRecorder)
+	return ret0
+}
+
+// AsyncRecorderForRootController indicates an expected call of AsyncRecorderForRootController.
+func (mr *MockObjectRecorderFactoryMockRecorder) AsyncRecorderForRootController(object, logger any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AsyncRecorderForRootController", reflect.TypeOf((*MockObjectRecorderFactory)(nil).AsyncRecorderForRootController), object, logger)
+}
+
 // RecorderForRootController mocks base method.
 func (m *MockObjectRecorderFactory) RecorderForRootController(ctx context.Context, object runtime.Object, logger logr.Logger) (types0.ObjectRecorder, error) {
 	m.ctrl.T.Helper()
@@ -186,6 +202,21 @@ func (mr *MockPodClassifierMockRecorder) Classify(podMeta, podSpec, logger any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Classify", reflect.TypeOf((*MockPodClassifier)(nil).Classify), podMeta, podSpec, logger)
 }
 
+// GetGroupID mocks base method.
+func (m *MockPodClassifier) GetGroupID(podMeta v10.ObjectMeta, podSpec v1.PodSpec, logger logr.Logger) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupID", podMeta, podSpec, logger)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroupID indicates an expected call of GetGroupID.
+func (mr *MockPodClassifierMockRecorder) GetGroupID(podMeta, podSpec, logger any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupID", reflect.TypeOf((*MockPodClassifier)(nil).GetGroupID), podMeta, podSpec, logger)
+}
+
 // ClassifyByGroupID mocks base method.
 func (m *MockPodClassifier) ClassifyByGroupID(groupID string, logger logr.Logger) (*types0.PodClassification, error) {
 	m.ctrl.T.Helper()
@@ -201,6 +232,21 @@ func (mr *MockPodClassifierMockRecorder) ClassifyByGroupID(groupID, logger any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClassifyByGroupID", reflect.TypeOf((*MockPodClassifier)(nil).ClassifyByGroupID), groupID, logger)
 }
 
+// RestoreGroup mocks base method.
+func (m *MockPodClassifier) RestoreGroup(groupID string, members []types0.GroupMember, logger logr.Logger) (*types0.PodClassification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreGroup", groupID, members, logger)
+	ret0, _ := ret[0].(*types0.PodClassification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreGroup indicates an expected call of RestoreGroup.
+func (mr *MockPodClassifierMockRecorder) RestoreGroup(groupID, members, logger any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreGroup", reflect.TypeOf((*MockPodClassifier)(nil).RestoreGroup), groupID, members, logger)
+}
+
 // MockPodGroupStandingClassifier is a mock of PodGroupStandingClassifier interface.
 type MockPodGroupStandingClassifier struct {
 	ctrl     *gomock.Controller
@@ -225,20 +271,131 @@ func (m *MockPodGroupStandingClassifier) EXPECT() *MockPodGroupStandingClassifie
 }
 
 // ClassifyPodGroup mocks base method.
-func (m *MockPodGroupStandingClassifier) ClassifyPodGroup(ctx context.Context, groupID string, logger logr.Logger) ([]v1.Pod, []v1.Pod, []v1.Pod, error) {
+func (m *MockPodGroupStandingClassifier) ClassifyPodGroup(ctx context.Context, groupID string, readiness types.Readiness, logger logr.Logger) (types1.PodClassification, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ClassifyPodGroup", ctx, groupID, logger)
-	ret0, _ := ret[0].([]v1.Pod)
-	ret1, _ := ret[1].([]v1.Pod)
-	ret2, _ := ret[2].([]v1.Pod)
-	ret3, _ := ret[3].(error)
-	return ret0, ret1, ret2, ret3
+	ret := m.ctrl.Call(m, "ClassifyPodGroup", ctx, groupID, readiness, logger)
+	ret0, _ := ret[0].(types1.PodClassification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // ClassifyPodGroup indicates an expected call of ClassifyPodGroup.
-func (mr *MockPodGroupStandingClassifierMockRecorder) ClassifyPodGroup(ctx, groupID, logger any) *gomock.Call {
+func (mr *MockPodGroupStandingClassifierMockRecorder) ClassifyPodGroup(ctx, groupID, readiness, logger any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClassifyPodGroup", reflect.TypeOf((*MockPodGroupStandingClassifier)(nil).ClassifyPodGroup), ctx, groupID, readiness, logger)
+}
+
+// MockNamespaceLabeler is a mock of NamespaceLabeler interface.
+type MockNamespaceLabeler struct {
+	ctrl     *gomock.Controller
+	recorder *MockNamespaceLabelerMockRecorder
+}
+
+// MockNamespaceLabelerMockRecorder is the mock recorder for MockNamespaceLabeler.
+type MockNamespaceLabelerMockRecorder struct {
+	mock *MockNamespaceLabeler
+}
+
+// NewMockNamespaceLabeler creates a new mock instance.
+func NewMockNamespaceLabeler(ctrl *gomock.Controller) *MockNamespaceLabeler {
+	mock := &MockNamespaceLabeler{ctrl: ctrl}
+	mock.recorder = &MockNamespaceLabelerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNamespaceLabeler) EXPECT() *MockNamespaceLabelerMockRecorder {
+	return m.recorder
+}
+
+// NamespaceLabels mocks base method.
+func (m *MockNamespaceLabeler) NamespaceLabels(ctx context.Context, namespace string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NamespaceLabels", ctx, namespace)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NamespaceLabels indicates an expected call of NamespaceLabels.
+func (mr *MockNamespaceLabelerMockRecorder) NamespaceLabels(ctx, namespace any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NamespaceLabels", reflect.TypeOf((*MockNamespaceLabeler)(nil).NamespaceLabels), ctx, namespace)
+}
+
+// MockEnableChecker is a mock of EnableChecker interface.
+type MockEnableChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockEnableCheckerMockRecorder
+}
+
+// MockEnableCheckerMockRecorder is the mock recorder for MockEnableChecker.
+type MockEnableCheckerMockRecorder struct {
+	mock *MockEnableChecker
+}
+
+// NewMockEnableChecker creates a new mock instance.
+func NewMockEnableChecker(ctrl *gomock.Controller) *MockEnableChecker {
+	mock := &MockEnableChecker{ctrl: ctrl}
+	mock.recorder = &MockEnableCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEnableChecker) EXPECT() *MockEnableCheckerMockRecorder {
+	return m.recorder
+}
+
+// IsEnabled mocks base method.
+func (m *MockEnableChecker) IsEnabled(ctx context.Context, namespace string, labels map[string]string, logger logr.Logger) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEnabled", ctx, namespace, labels, logger)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsEnabled indicates an expected call of IsEnabled.
+func (mr *MockEnableCheckerMockRecorder) IsEnabled(ctx, namespace, labels, logger any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEnabled", reflect.TypeOf((*MockEnableChecker)(nil).IsEnabled), ctx, namespace, labels, logger)
+}
+
+// MockStatefulSetGetter is a mock of StatefulSetGetter interface.
+type MockStatefulSetGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatefulSetGetterMockRecorder
+}
+
+// MockStatefulSetGetterMockRecorder is the mock recorder for MockStatefulSetGetter.
+type MockStatefulSetGetterMockRecorder struct {
+	mock *MockStatefulSetGetter
+}
+
+// NewMockStatefulSetGetter creates a new mock instance.
+func NewMockStatefulSetGetter(ctrl *gomock.Controller) *MockStatefulSetGetter {
+	mock := &MockStatefulSetGetter{ctrl: ctrl}
+	mock.recorder = &MockStatefulSetGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatefulSetGetter) EXPECT() *MockStatefulSetGetterMockRecorder {
+	return m.recorder
+}
+
+// GetStatefulSet mocks base method.
+func (m *MockStatefulSetGetter) GetStatefulSet(ctx context.Context, namespace, name string) (*v11.StatefulSet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatefulSet", ctx, namespace, name)
+	ret0, _ := ret[0].(*v11.StatefulSet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatefulSet indicates an expected call of GetStatefulSet.
+func (mr *MockStatefulSetGetterMockRecorder) GetStatefulSet(ctx, namespace, name any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClassifyPodGroup", reflect.TypeOf((*MockPodGroupStandingClassifier)(nil).ClassifyPodGroup), ctx, groupID, logger)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatefulSet", reflect.TypeOf((*MockStatefulSetGetter)(nil).GetStatefulSet), ctx, namespace, name)
 }
 
 // MockPodClassifierConfigurator is a mock of PodClassifierConfigurator interface.
@@ -306,6 +463,131 @@ func (mr *MockPodClassifierConfiguratorMockRecorder) UpdateConfig(config, logger
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConfig", reflect.TypeOf((*MockPodClassifierConfigurator)(nil).UpdateConfig), config, logger)
 }
 
+// MockConfigurablePodClassifier is a mock of ConfigurablePodClassifier interface.
+type MockConfigurablePodClassifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockConfigurablePodClassifierMockRecorder
+}
+
+// MockConfigurablePodClassifierMockRecorder is the mock recorder for MockConfigurablePodClassifier.
+type MockConfigurablePodClassifierMockRecorder struct {
+	mock *MockConfigurablePodClassifier
+}
+
+// NewMockConfigurablePodClassifier creates a new mock instance.
+func NewMockConfigurablePodClassifier(ctrl *gomock.Controller) *MockConfigurablePodClassifier {
+	mock := &MockConfigurablePodClassifier{ctrl: ctrl}
+	mock.recorder = &MockConfigurablePodClassifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConfigurablePodClassifier) EXPECT() *MockConfigurablePodClassifierMockRecorder {
+	return m.recorder
+}
+
+// AddConfig mocks base method.
+func (m *MockConfigurablePodClassifier) AddConfig(config types.StaggerGroup, logger logr.Logger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddConfig", config, logger)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddConfig indicates an expected call of AddConfig.
+func (mr *MockConfigurablePodClassifierMockRecorder) AddConfig(config, logger any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddConfig", reflect.TypeOf((*MockConfigurablePodClassifier)(nil).AddConfig), config, logger)
+}
+
+// Classify mocks base method.
+func (m *MockConfigurablePodClassifier) Classify(podMeta v10.ObjectMeta, podSpec v1.PodSpec, logger logr.Logger) (*types0.PodClassification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Classify", podMeta, podSpec, logger)
+	ret0, _ := ret[0].(*types0.PodClassification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Classify indicates an expected call of Classify.
+func (mr *MockConfigurablePodClassifierMockRecorder) Classify(podMeta, podSpec, logger any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Classify", reflect.TypeOf((*MockConfigurablePodClassifier)(nil).Classify), podMeta, podSpec, logger)
+}
+
+// GetGroupID mocks base method.
+func (m *MockConfigurablePodClassifier) GetGroupID(podMeta v10.ObjectMeta, podSpec v1.PodSpec, logger logr.Logger) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupID", podMeta, podSpec, logger)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroupID indicates an expected call of GetGroupID.
+func (mr *MockConfigurablePodClassifierMockRecorder) GetGroupID(podMeta, podSpec, logger any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupID", reflect.TypeOf((*MockConfigurablePodClassifier)(nil).GetGroupID), podMeta, podSpec, logger)
+}
+
+// ClassifyByGroupID mocks base method.
+func (m *MockConfigurablePodClassifier) ClassifyByGroupID(groupID string, logger logr.Logger) (*types0.PodClassification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClassifyByGroupID", groupID, logger)
+	ret0, _ := ret[0].(*types0.PodClassification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClassifyByGroupID indicates an expected call of ClassifyByGroupID.
+func (mr *MockConfigurablePodClassifierMockRecorder) ClassifyByGroupID(groupID, logger any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClassifyByGroupID", reflect.TypeOf((*MockConfigurablePodClassifier)(nil).ClassifyByGroupID), groupID, logger)
+}
+
+// RemoveConfig mocks base method.
+func (m *MockConfigurablePodClassifier) RemoveConfig(name string, logger logr.Logger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveConfig", name, logger)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveConfig indicates an expected call of RemoveConfig.
+func (mr *MockConfigurablePodClassifierMockRecorder) RemoveConfig(name, logger any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveConfig", reflect.TypeOf((*MockConfigurablePodClassifier)(nil).RemoveConfig), name, logger)
+}
+
+// RestoreGroup mocks base method.
+func (m *MockConfigurablePodClassifier) RestoreGroup(groupID string, members []types0.GroupMember, logger logr.Logger) (*types0.PodClassification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreGroup", groupID, members, logger)
+	ret0, _ := ret[0].(*types0.PodClassification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreGroup indicates an expected call of RestoreGroup.
+func (mr *MockConfigurablePodClassifierMockRecorder) RestoreGroup(groupID, members, logger any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreGroup", reflect.TypeOf((*MockConfigurablePodClassifier)(nil).RestoreGroup), groupID, members, logger)
+}
+
+// UpdateConfig mocks base method.
+func (m *MockConfigurablePodClassifier) UpdateConfig(config types.StaggerGroup, logger logr.Logger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateConfig", config, logger)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateConfig indicates an expected call of UpdateConfig.
+func (mr *MockConfigurablePodClassifierMockRecorder) UpdateConfig(config, logger any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConfig", reflect.TypeOf((*MockConfigurablePodClassifier)(nil).UpdateConfig), config, logger)
+}
+
 // MockAdmissionFlightTracker is a mock of AdmissionFlightTracker interface.
 type MockAdmissionFlightTracker struct {
 	ctrl     *gomock.Controller
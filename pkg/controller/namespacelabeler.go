@@ -0,0 +1,35 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"straggler/pkg/controller/types"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ types.NamespaceLabeler = &namespaceLabeler{}
+
+type namespaceLabeler struct {
+	reader client.Reader
+}
+
+// Create a new namespace labeler that gets namespaces from reader.
+func NewNamespaceLabeler(reader client.Reader) types.NamespaceLabeler {
+	return &namespaceLabeler{
+		reader: reader,
+	}
+}
+
+func (n *namespaceLabeler) NamespaceLabels(ctx context.Context, namespace string) (map[string]string, error) {
+	ns := &corev1.Namespace{}
+	if err := n.reader.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		return nil, fmt.Errorf("failed to get namespace %s: %v", namespace, err)
+	}
+
+	return ns.Labels, nil
+}
@@ -4,9 +4,14 @@ package controller
 
 import (
 	"context"
+	"time"
+
+	configtypes "straggler/pkg/config/types"
 	"straggler/pkg/controller/types"
 
 	blocker "straggler/pkg/blocker/types"
+	pacertypes "straggler/pkg/pacer/types"
+	"straggler/pkg/unblocker"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
@@ -29,7 +34,7 @@ func NewPodGroupStandingClassifier(client client.Client, blocker blocker.PodBloc
 	}
 }
 
-func (p *podGroupStandingClassifier) ClassifyPodGroup(ctx context.Context, groupID string, logger logr.Logger) (ready []corev1.Pod, starting []corev1.Pod, blocked []corev1.Pod, err error) {
+func (p *podGroupStandingClassifier) ClassifyPodGroup(ctx context.Context, groupID string, readiness configtypes.Readiness, logger logr.Logger) (classification pacertypes.PodClassification, err error) {
 	logger.Info("classifying pod group", "groupID", groupID)
 
 	podList := &corev1.PodList{}
@@ -41,29 +46,105 @@ func (p *podGroupStandingClassifier) ClassifyPodGroup(ctx context.Context, group
 
 	if err := p.client.List(ctx, podList, listOptions...); err != nil {
 		logger.Error(err, "failed to list pods")
-		return nil, nil, nil, err
+		return pacertypes.PodClassification{}, err
 	}
 
+	now := time.Now()
 	for _, pod := range podList.Items {
+		ready, _ := isPodReadyFor(pod, readiness, now)
 		switch {
-		case p.blocker.IsBlocked(&pod.Spec):
-			blocked = append(blocked, pod)
-		case isPodReady(pod):
-			ready = append(ready, pod)
+		// pods released by annotation are handled by other systems.
+		case p.blocker.IsBlocked(&pod.Spec) && !unblocker.IsReleased(&pod):
+			classification.Blocked = append(classification.Blocked, pod)
+		case ready:
+			classification.Ready = append(classification.Ready, pod)
+		case isPodFailed(pod):
+			classification.Failed = append(classification.Failed, pod)
 		default:
-			starting = append(starting, pod)
+			classification.Starting = append(classification.Starting, pod)
 		}
 	}
 
-	logger.Info("pod group classification complete", "groupID", groupID, "ready", len(ready), "starting", len(starting), "blocked", len(blocked))
+	logger.Info("pod group classification complete",
+		"groupID", groupID,
+		"ready", len(classification.Ready),
+		"starting", len(classification.Starting),
+		"blocked", len(classification.Blocked),
+		"failed", len(classification.Failed))
 
-	return ready, starting, blocked, nil
+	return classification, nil
 }
 
 // Helper function to check if the Pod is Ready
 func isPodReady(pod corev1.Pod) bool {
-	for _, condition := range pod.Status.Conditions {
-		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+	ready, _ := isPodReadyFor(pod, configtypes.Readiness{}, time.Now())
+	return ready
+}
+
+// Check if the pod is ready according to readiness. If the pod is ready but
+// not for the minimum duration yet then the remaining duration is returned.
+func isPodReadyFor(pod corev1.Pod, readiness configtypes.Readiness, now time.Time) (bool, time.Duration) {
+	if readiness.Succeeded && pod.Status.Phase == corev1.PodSucceeded {
+		return true, 0
+	}
+
+	var readySince time.Time
+	if readiness.ContainersRunning {
+		if len(pod.Status.ContainerStatuses) == 0 {
+			return false, 0
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Running == nil {
+				return false, 0
+			}
+			if status.State.Running.StartedAt.After(readySince) {
+				readySince = status.State.Running.StartedAt.Time
+			}
+		}
+	} else {
+		conditionType := readiness.ConditionType
+		if len(conditionType) == 0 {
+			conditionType = corev1.PodReady
+		}
+		found := false
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+				readySince = condition.LastTransitionTime.Time
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, 0
+		}
+	}
+
+	if remaining := readiness.MinReadyDuration - now.Sub(readySince); readiness.MinReadyDuration > 0 && remaining > 0 {
+		return false, remaining
+	}
+
+	return true, 0
+}
+
+// Container waiting reasons of pods that are failing to start.
+var failedWaitingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+}
+
+// Helper function to check if the Pod failed or is failing to start.
+func isPodFailed(pod corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodFailed {
+		return true
+	}
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if status.State.Waiting != nil && failedWaitingReasons[status.State.Waiting.Reason] {
 			return true
 		}
 	}
@@ -6,8 +6,10 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	blockermocks "straggler/pkg/blocker/mocks"
+	configtypes "straggler/pkg/config/types"
 
 	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/assert"
@@ -26,6 +28,19 @@ func newPodCondition(conditionType corev1.PodConditionType, status corev1.Condit
 	}
 }
 
+func newFailingPod(name string, status corev1.PodStatus) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels: map[string]string{
+				DefaultStaggerGroupIDLabel: "group6",
+			},
+		},
+		Status: status,
+	}
+}
+
 func TestPodGroupStandingClassifier_ClassifyPodGroup(t *testing.T) {
 	groupLabel := DefaultStaggerGroupIDLabel
 
@@ -40,6 +55,7 @@ func TestPodGroupStandingClassifier_ClassifyPodGroup(t *testing.T) {
 		expectedReady []corev1.Pod
 		expectedStart []corev1.Pod
 		expectedBlock []corev1.Pod
+		expectedFail  []corev1.Pod
 		listErr       error
 	}{
 		{
@@ -297,6 +313,61 @@ func TestPodGroupStandingClassifier_ClassifyPodGroup(t *testing.T) {
 			expectedBlock: []corev1.Pod{},
 			listErr:       nil,
 		},
+		{
+			name:    "Failing Pods",
+			groupID: "group6",
+			podList: []corev1.Pod{
+				newFailingPod("pod-crashloop", corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+					},
+				}),
+				newFailingPod("pod-imagepull", corev1.PodStatus{
+					Phase: corev1.PodPending,
+					InitContainerStatuses: []corev1.ContainerStatus{
+						{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+					},
+				}),
+				newFailingPod("pod-failed", corev1.PodStatus{
+					Phase: corev1.PodFailed,
+				}),
+				newFailingPod("pod-creating", corev1.PodStatus{
+					Phase: corev1.PodPending,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+					},
+				}),
+			},
+			blockedPods:   map[string]bool{},
+			expectedReady: []corev1.Pod{},
+			expectedStart: []corev1.Pod{
+				newFailingPod("pod-creating", corev1.PodStatus{
+					Phase: corev1.PodPending,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+					},
+				}),
+			},
+			expectedBlock: []corev1.Pod{},
+			expectedFail: []corev1.Pod{
+				newFailingPod("pod-crashloop", corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+					},
+				}),
+				newFailingPod("pod-imagepull", corev1.PodStatus{
+					Phase: corev1.PodPending,
+					InitContainerStatuses: []corev1.ContainerStatus{
+						{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+					},
+				}),
+				newFailingPod("pod-failed", corev1.PodStatus{
+					Phase: corev1.PodFailed,
+				}),
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -343,21 +414,69 @@ func TestPodGroupStandingClassifier_ClassifyPodGroup(t *testing.T) {
 			}
 
 			// Execute the method under test
-			ready, starting, blocked, err := classifier.ClassifyPodGroup(ctx, tc.groupID, logger)
+			classification, err := classifier.ClassifyPodGroup(ctx, tc.groupID, configtypes.Readiness{}, logger)
 
 			// Assertions
 			if tc.listErr != nil {
 				assert.Error(t, err)
 				assert.Equal(t, tc.listErr, err)
-				assert.Nil(t, ready)
-				assert.Nil(t, starting)
-				assert.Nil(t, blocked)
+				assert.Nil(t, classification.Ready)
+				assert.Nil(t, classification.Starting)
+				assert.Nil(t, classification.Blocked)
+				assert.Nil(t, classification.Failed)
 			} else {
 				assert.NoError(t, err)
-				assert.ElementsMatch(t, tc.expectedReady, ready, "Ready pods do not match")
-				assert.ElementsMatch(t, tc.expectedStart, starting, "Starting pods do not match")
-				assert.ElementsMatch(t, tc.expectedBlock, blocked, "Blocked pods do not match")
+				assert.ElementsMatch(t, tc.expectedReady, classification.Ready, "Ready pods do not match")
+				assert.ElementsMatch(t, tc.expectedStart, classification.Starting, "Starting pods do not match")
+				assert.ElementsMatch(t, tc.expectedBlock, classification.Blocked, "Blocked pods do not match")
+				assert.ElementsMatch(t, tc.expectedFail, classification.Failed, "Failed pods do not match")
 			}
 		})
 	}
 }
+
+func TestIsPodReadyFor(t *testing.T) {
+	now := time.Now()
+	readyPod := corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(now.Add(-10 * time.Second))},
+				{Type: "example.com/warm", Status: corev1.ConditionFalse},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(now.Add(-time.Minute))}}},
+				{State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(now.Add(-20 * time.Second))}}},
+			},
+		},
+	}
+	succeededPod := corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+		},
+	}
+
+	tests := []struct {
+		name              string
+		pod               corev1.Pod
+		readiness         configtypes.Readiness
+		expectedReady     bool
+		expectedRemaining time.Duration
+	}{
+		{"default", readyPod, configtypes.Readiness{}, true, 0},
+		{"min ready", readyPod, configtypes.Readiness{MinReadyDuration: 30 * time.Second}, false, 20 * time.Second},
+		{"custom condition", readyPod, configtypes.Readiness{ConditionType: "example.com/warm"}, false, 0},
+		{"containers running", readyPod, configtypes.Readiness{ContainersRunning: true}, true, 0},
+		{"containers running min ready", readyPod, configtypes.Readiness{ContainersRunning: true, MinReadyDuration: 30 * time.Second}, false, 10 * time.Second},
+		{"succeeded default", succeededPod, configtypes.Readiness{}, false, 0},
+		{"succeeded", succeededPod, configtypes.Readiness{Succeeded: true, MinReadyDuration: time.Hour}, true, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ready, remaining := isPodReadyFor(tc.pod, tc.readiness, now)
+			assert.Equal(t, tc.expectedReady, ready)
+			assert.Equal(t, tc.expectedRemaining, remaining)
+		})
+	}
+}
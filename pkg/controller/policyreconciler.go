@@ -0,0 +1,193 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"straggler/pkg/apis/v1alpha1"
+	configtypes "straggler/pkg/config/types"
+	"straggler/pkg/controller/types"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Prefix of classifier config names of cluster scoped policies.
+const ClusterPolicyConfigPrefix = "cluster:"
+
+// Creates a classifier config from a policy spec. namespace is empty for
+// cluster scoped policies.
+type StaggerGroupFactory func(name, namespace string, spec v1alpha1.StaggeringPolicySpec, logger logr.Logger) (configtypes.StaggerGroup, error)
+
+// Called with all currently applied policy specs by config name whenever
+// they change.
+type PolicyChangeHandler func(specs map[string]v1alpha1.StaggeringPolicySpec, logger logr.Logger)
+
+var _ reconcile.Reconciler = &PolicyReconciler{}
+
+// Reconciles StaggeringPolicy or ClusterStaggeringPolicy objects into
+// a classifier configurator and reports back their status.
+type PolicyReconciler struct {
+	sync.Mutex
+
+	client        client.Client
+	configurator  types.PodClassifierConfigurator
+	groupFactory  StaggerGroupFactory
+	changeHandler PolicyChangeHandler
+	clusterScoped bool
+
+	appliedSpecs map[string]v1alpha1.StaggeringPolicySpec
+}
+
+// Create a new policy reconciler. If clusterScoped is true then it reconciles
+// ClusterStaggeringPolicy objects, otherwise StaggeringPolicy objects.
+// changeHandler is optional.
+func NewPolicyReconciler(
+	client client.Client,
+	configurator types.PodClassifierConfigurator,
+	groupFactory StaggerGroupFactory,
+	changeHandler PolicyChangeHandler,
+	clusterScoped bool,
+) *PolicyReconciler {
+	return &PolicyReconciler{
+		client:        client,
+		configurator:  configurator,
+		groupFactory:  groupFactory,
+		changeHandler: changeHandler,
+		clusterScoped: clusterScoped,
+		appliedSpecs:  make(map[string]v1alpha1.StaggeringPolicySpec),
+	}
+}
+
+func (r *PolicyReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	r.Lock()
+	defer r.Unlock()
+
+	object, spec, status := r.newObject()
+	name := PolicyConfigName(request.Namespace, request.Name)
+	err := r.client.Get(ctx, request.NamespacedName, object)
+	if err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			logger.Error(err, "failed to get policy")
+			return reconcile.Result{}, err
+		}
+		logger.Info("policy not found, removing", "policy", name)
+		return reconcile.Result{}, r.removeLocked(name, logger)
+	}
+
+	if !object.GetDeletionTimestamp().IsZero() {
+		logger.Info("policy is being deleted, removing", "policy", name)
+		return reconcile.Result{}, r.removeLocked(name, logger)
+	}
+
+	applyErr := r.applyLocked(name, request.Namespace, *spec, logger)
+
+	condition := metav1.Condition{
+		Type:               v1alpha1.PolicyConditionAccepted,
+		Status:             metav1.ConditionTrue,
+		Reason:             v1alpha1.PolicyReasonAccepted,
+		Message:            "policy is applied",
+		ObservedGeneration: object.GetGeneration(),
+	}
+	if applyErr != nil {
+		logger.Info("failed to apply policy", "policy", name, "error", applyErr)
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = v1alpha1.PolicyReasonInvalid
+		condition.Message = applyErr.Error()
+	}
+
+	newStatus := status.DeepCopy()
+	newStatus.ObservedGeneration = object.GetGeneration()
+	meta.SetStatusCondition(&newStatus.Conditions, condition)
+	if !equality.Semantic.DeepEqual(status, newStatus) {
+		*status = *newStatus
+		if err := r.client.Status().Update(ctx, object); err != nil {
+			return reconcile.Result{}, fmt.Errorf("failed to update policy status: %v", err)
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+func (r *PolicyReconciler) newObject() (client.Object, *v1alpha1.StaggeringPolicySpec, *v1alpha1.StaggeringPolicyStatus) {
+	if r.clusterScoped {
+		policy := &v1alpha1.ClusterStaggeringPolicy{}
+		return policy, &policy.Spec, &policy.Status
+	}
+	policy := &v1alpha1.StaggeringPolicy{}
+	return policy, &policy.Spec, &policy.Status
+}
+
+func (r *PolicyReconciler) applyLocked(name, namespace string, spec v1alpha1.StaggeringPolicySpec, logger logr.Logger) error {
+	applied, ok := r.appliedSpecs[name]
+	if ok && reflect.DeepEqual(applied, spec) {
+		logger.V(1).Info("policy is unchanged", "policy", name)
+		return nil
+	}
+
+	group, err := r.groupFactory(name, namespace, spec, logger)
+	if err != nil {
+		return err
+	}
+	if ok {
+		logger.Info("updating policy", "policy", name)
+		err = r.configurator.UpdateConfig(group, logger)
+	} else {
+		logger.Info("adding policy", "policy", name)
+		err = r.configurator.AddConfig(group, logger)
+	}
+	if err != nil {
+		return err
+	}
+
+	r.appliedSpecs[name] = spec
+	r.notifyLocked(logger)
+	return nil
+}
+
+func (r *PolicyReconciler) removeLocked(name string, logger logr.Logger) error {
+	if _, ok := r.appliedSpecs[name]; !ok {
+		return nil
+	}
+	if err := r.configurator.RemoveConfig(name, logger); err != nil {
+		return err
+	}
+
+	delete(r.appliedSpecs, name)
+	r.notifyLocked(logger)
+	return nil
+}
+
+func (r *PolicyReconciler) notifyLocked(logger logr.Logger) {
+	if r.changeHandler == nil {
+		return
+	}
+	specs := make(map[string]v1alpha1.StaggeringPolicySpec)
+	for name, spec := range r.appliedSpecs {
+		specs[name] = spec
+	}
+	r.changeHandler(specs, logger)
+}
+
+// Get classifier config name for a policy object. Namespaced policies
+// are prefixed with their namespaces and cluster policies with
+// ClusterPolicyConfigPrefix. Since object names contain neither '/' nor
+// ':', these never clash with each other. Policies from the file with names
+// of either form are rejected by config validation.
+func PolicyConfigName(namespace, name string) string {
+	if len(namespace) == 0 {
+		return ClusterPolicyConfigPrefix + name
+	}
+	return namespace + "/" + name
+}
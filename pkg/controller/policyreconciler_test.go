@@ -0,0 +1,108 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"straggler/pkg/apis/v1alpha1"
+	configtypes "straggler/pkg/config/types"
+	"straggler/pkg/controller/mocks"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestPolicyReconcilerLifecycle(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	policy := &v1alpha1.StaggeringPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "testnamespace",
+			Name:       "policy",
+			Generation: 1,
+		},
+		Spec: v1alpha1.StaggeringPolicySpec{
+			GroupingExpression: ".spec.containers[0].image",
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(policy).
+		WithStatusSubresource(policy).
+		Build()
+
+	groupFactory := func(name, namespace string, spec v1alpha1.StaggeringPolicySpec, logger logr.Logger) (configtypes.StaggerGroup, error) {
+		if len(spec.GroupingExpression) == 0 {
+			return configtypes.StaggerGroup{}, errors.New("invalid")
+		}
+		return configtypes.StaggerGroup{
+			Name:               name,
+			Namespace:          namespace,
+			GroupingExpression: spec.GroupingExpression,
+		}, nil
+	}
+	var changedSpecs map[string]v1alpha1.StaggeringPolicySpec
+	changeHandler := func(specs map[string]v1alpha1.StaggeringPolicySpec, logger logr.Logger) {
+		changedSpecs = specs
+	}
+
+	configurator := mocks.NewMockPodClassifierConfigurator(mockCtrl)
+	reconciler := NewPolicyReconciler(fakeClient, configurator, groupFactory, changeHandler, false)
+	request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(policy)}
+	ctx := context.Background()
+
+	// new policy is added
+	configurator.EXPECT().AddConfig(gomock.Any(), gomock.Any()).DoAndReturn(func(config configtypes.StaggerGroup, _ logr.Logger) error {
+		require.Equal(t, "testnamespace/policy", config.Name)
+		require.Equal(t, "testnamespace", config.Namespace)
+		return nil
+	})
+	_, err := reconciler.Reconcile(ctx, request)
+	require.NoError(t, err)
+	require.Contains(t, changedSpecs, "testnamespace/policy")
+	require.NoError(t, fakeClient.Get(ctx, request.NamespacedName, policy))
+	require.True(t, meta.IsStatusConditionTrue(policy.Status.Conditions, v1alpha1.PolicyConditionAccepted))
+
+	// unchanged policy is not reapplied
+	_, err = reconciler.Reconcile(ctx, request)
+	require.NoError(t, err)
+
+	// invalid update is reported in status
+	policy.Spec.GroupingExpression = ""
+	policy.Generation = 2
+	require.NoError(t, fakeClient.Update(ctx, policy))
+	_, err = reconciler.Reconcile(ctx, request)
+	require.NoError(t, err)
+	require.NoError(t, fakeClient.Get(ctx, request.NamespacedName, policy))
+	condition := meta.FindStatusCondition(policy.Status.Conditions, v1alpha1.PolicyConditionAccepted)
+	require.NotNil(t, condition)
+	require.Equal(t, metav1.ConditionFalse, condition.Status)
+	require.Equal(t, v1alpha1.PolicyReasonInvalid, condition.Reason)
+
+	// deleted policy is removed
+	require.NoError(t, fakeClient.Delete(ctx, policy))
+	configurator.EXPECT().RemoveConfig("testnamespace/policy", gomock.Any()).Return(nil)
+	_, err = reconciler.Reconcile(ctx, request)
+	require.NoError(t, err)
+	require.Empty(t, changedSpecs)
+}
+
+func TestPolicyConfigName(t *testing.T) {
+	require.Equal(t, "testnamespace/policy", PolicyConfigName("testnamespace", "policy"))
+	// cluster policies must not clash with policies from the file.
+	require.Equal(t, "cluster:policy", PolicyConfigName("", "policy"))
+}
@@ -8,15 +8,17 @@ import (
 	"math"
 	"time"
 
+	"straggler/pkg/blocker"
+	blockertypes "straggler/pkg/blocker/types"
+	configtypes "straggler/pkg/config/types"
 	"straggler/pkg/controller/types"
 	pacertypes "straggler/pkg/pacer/types"
+	unblockertypes "straggler/pkg/unblocker/types"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
-	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apitypes "k8s.io/apimachinery/pkg/types"
-	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -32,22 +34,48 @@ type Reconciler struct {
 	client                   client.Client
 	classifier               types.PodClassifier
 	podGroupClassifier       types.PodGroupStandingClassifier
+	recorderFactory          types.ObjectRecorderFactory
+	enableChecker            types.EnableChecker
+	podBlocker               blockertypes.PodBlocker
+	defaultUnblocker         unblockertypes.PodUnblocker
+	unblockers               map[string]unblockertypes.PodUnblocker
+	ownerlessUnblockers      map[string]unblockertypes.PodUnblocker
 	blockedPodResyncDuration time.Duration
 
-	enableLabel         string
 	staggerGroupIDLabel string
 }
 
 var _ reconcile.Reconciler = &Reconciler{}
 
-func NewReconciler(client client.Client, classifier types.PodClassifier, podGroupClassifier types.PodGroupStandingClassifier) *Reconciler {
+// Create a new reconciler that releases pods using defaultUnblocker unless
+// their group policies specify one of unblockers by name. Only pods enabled
+// by enableChecker are reconciled and only pods still blocked by podBlocker
+// are released after their max blocked duration. Pods without an owning controller are
+// released using ownerlessUnblockers keyed by the name of the unblocker
+// they replace, if any.
+func NewReconciler(
+	client client.Client,
+	classifier types.PodClassifier,
+	podGroupClassifier types.PodGroupStandingClassifier,
+	recorderFactory types.ObjectRecorderFactory,
+	enableChecker types.EnableChecker,
+	podBlocker blockertypes.PodBlocker,
+	defaultUnblocker unblockertypes.PodUnblocker,
+	unblockers map[string]unblockertypes.PodUnblocker,
+	ownerlessUnblockers map[string]unblockertypes.PodUnblocker,
+) *Reconciler {
 	return &Reconciler{
 		client:                   client,
 		classifier:               classifier,
 		podGroupClassifier:       podGroupClassifier,
+		recorderFactory:          recorderFactory,
+		enableChecker:            enableChecker,
+		podBlocker:               podBlocker,
+		defaultUnblocker:         defaultUnblocker,
+		unblockers:               unblockers,
+		ownerlessUnblockers:      ownerlessUnblockers,
 		blockedPodResyncDuration: DefaultBlockedPodResyncDuration,
 
-		enableLabel:         DefaultEnableLabel,
 		staggerGroupIDLabel: DefaultStaggerGroupIDLabel,
 	}
 }
@@ -67,28 +95,27 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		return reconcile.Result{}, nil
 	}
 
-	if !r.checkEnabled(&pod.ObjectMeta, logger) {
+	if !r.enableChecker.IsEnabled(ctx, pod.Namespace, pod.Labels, logger) {
 		logger.V(10).Info("skipping not enabled pod")
 		return reconcile.Result{}, nil
 	}
 
+	// original specs kept in config maps are removed along with their pods.
+	if err := blocker.AdoptOriginalSpecConfigMap(ctx, r.client, pod); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	if pod.Labels == nil {
 		pod.Labels = make(map[string]string)
 	}
 	// try to skip unnecessary reconciliations
 	// if a pod is blocked then we reconcile.
-	if _, ok := pod.Labels[DefaultStaggeredPodLabel]; !ok {
-		logger.V(1).Info("pod is not staggered")
-		// if a pod is not ready then we are not interested in this event.
-		// only ready pods will have an effect on pacing.
-		if !isPodReady(*pod) {
-			logger.V(1).Info("pod is not ready")
-			return reconcile.Result{}, nil
-		}
-	}
+	_, staggered := pod.Labels[blocker.DefaultStaggeredPodLabel]
 	groupID, ok := pod.Labels[r.staggerGroupIDLabel]
 	if !ok {
-		logger.Info("pod does not have group ID label")
+		if staggered {
+			logger.Info("pod does not have group ID label")
+		}
 		return reconcile.Result{}, nil
 	}
 	if len(groupID) == 0 {
@@ -100,35 +127,73 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	if err != nil {
 		return reconcile.Result{}, err
 	}
+	if group == nil {
+		// groups are not known after restarts until restored from their pods.
+		group, err = r.restoreGroup(pod, groupID, logger)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	readiness := configtypes.Readiness{}
+	if group != nil {
+		readiness = group.GroupPolicies.Readiness
+	}
+	if !staggered {
+		logger.V(1).Info("pod is not staggered")
+		// if a pod is not ready then we are not interested in this event.
+		// only ready pods will have an effect on pacing.
+		ready, remaining := isPodReadyFor(*pod, readiness, time.Now())
+		if !ready {
+			logger.V(1).Info("pod is not ready")
+			// pod will be ready after its minimum ready duration.
+			if remaining > 0 {
+				return reconcile.Result{RequeueAfter: remaining}, nil
+			}
+			return reconcile.Result{}, nil
+		}
+	}
 	if group == nil {
 		return reconcile.Result{}, fmt.Errorf("pod group ID not found: %v", groupID)
 	}
 
 	logger.V(1).Info("staggering group", "id", group.ID, "pacer", group.Pacer)
 
-	ready, starting, blocked, err := r.podGroupClassifier.ClassifyPodGroup(ctx, group.ID, logger)
+	classification, err := r.podGroupClassifier.ClassifyPodGroup(ctx, group.ID, group.GroupPolicies.Readiness, logger)
 	if err != nil {
 		return reconcile.Result{}, fmt.Errorf("failed to classify pod group: %v", err)
 	}
-	logger.V(1).Info("pod group break down", "ready", len(ready), "starting", len(starting), "blocked", len(blocked))
+	logger.V(1).Info("pod group break down",
+		"ready", len(classification.Ready),
+		"starting", len(classification.Starting),
+		"blocked", len(classification.Blocked),
+		"failed", len(classification.Failed))
 
-	unblocked, err := group.Pacer.Pace(pacertypes.PodClassification{
-		Ready:    ready,
-		Starting: starting,
-		Blocked:  blocked,
-	}, logger)
+	unblocked, err := group.Pacer.Pace(classification, logger)
 	if err != nil {
 		return reconcile.Result{}, fmt.Errorf("failed to pace pod: %v", err)
 	}
 
+	groupUnblocker, err := r.getUnblocker(group.GroupPolicies)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
 	unblockedPods := map[apitypes.NamespacedName]bool{}
-	// evict all the unblocked pods
+	// release all the unblocked pods
 	for _, unblockedPod := range unblocked {
-		logger.V(1).Info("evicting pod to unblock it")
-		if err := evictPod(ctx, r.client, &unblockedPod); client.IgnoreNotFound(err) != nil {
-			logger.Error(err, "failed to evict pod", "pod", unblockedPod.Name, "namespace", unblockedPod.Namespace)
+		unblocker := r.getPodUnblocker(groupUnblocker, &unblockedPod)
+		if err := r.unblockPod(ctx, unblocker, &unblockedPod, releaseReasonPaced, logger); client.IgnoreNotFound(err) != nil {
+			logger.Error(err, "failed to unblock pod", "pod", unblockedPod.Name, "namespace", unblockedPod.Namespace)
+			r.recordReleaseFailure(ctx, unblocker, &unblockedPod, err, logger)
 		} else {
 			unblockedPods[client.ObjectKeyFromObject(&unblockedPod)] = true
+			r.recorderFactory.RecorderForRootControllerOrNull(ctx, &unblockedPod, logger).Normalf(
+				EventReasonReleased,
+				"pod %s released by %s after %d ready pods in group %s",
+				unblockedPod.Name,
+				unblocker.Name(),
+				len(classification.Ready),
+				group.ID)
 		}
 	}
 
@@ -138,17 +203,26 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 
 	// if our pod wasn't unblocked then check for policies.
 	policyMaxDuration := group.GroupPolicies.MaxBlockedDuration
-	// apply max blocked policy.
+	// apply max blocked policy only to pods that are still blocked.
 	var durationUntilUnblock time.Duration
-	if policyMaxDuration > 0 && !pod.CreationTimestamp.IsZero() {
+	blocked := staggered && r.podBlocker.IsBlocked(&pod.Spec)
+	if blocked && policyMaxDuration > 0 && !pod.CreationTimestamp.IsZero() {
 		timeSinceCreation := time.Since(pod.CreationTimestamp.Time)
 		logger.V(1).Info("checking MaxBlockedDuration", "maxDuration", policyMaxDuration, "creationDuration", timeSinceCreation)
 		durationUntilUnblock = policyMaxDuration - timeSinceCreation
 		if durationUntilUnblock <= 0 {
 			logger.Info("blocked pod exceeded policy duration", "maxDuration", policyMaxDuration)
-			if err := evictPod(ctx, r.client, pod); client.IgnoreNotFound(err) != nil {
-				logger.Error(err, "failed to evict pod", "pod", pod.Name, "namespace", pod.Namespace)
+			unblocker := r.getPodUnblocker(groupUnblocker, pod)
+			if err := r.unblockPod(ctx, unblocker, pod, releaseReasonMaxBlockedDuration, logger); client.IgnoreNotFound(err) != nil {
+				logger.Error(err, "failed to unblock pod", "pod", pod.Name, "namespace", pod.Namespace)
+				r.recordReleaseFailure(ctx, unblocker, pod, err, logger)
 			} else {
+				r.recorderFactory.RecorderForRootControllerOrNull(ctx, pod, logger).Normalf(
+					EventReasonReleasedMaxBlockedDuration,
+					"pod %s released by %s after exceeding max blocked duration %v",
+					pod.Name,
+					unblocker.Name(),
+					policyMaxDuration)
 				// success, return default
 				return reconcile.Result{}, nil
 			}
@@ -162,31 +236,83 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 			float64(resync),
 			float64(durationUntilUnblock)))
 	}
+	// timed pacers may allow more pods without any pod changes.
+	if timed, ok := group.Pacer.(pacertypes.TimedPacer); ok {
+		if nextPace := timed.NextPace(); nextPace > 0 && nextPace < resync {
+			logger.V(1).Info("requeueing for next pace", "nextPace", nextPace)
+			resync = nextPace
+		}
+	}
 	return reconcile.Result{
 		RequeueAfter: resync,
 	}, nil
 
 }
 
-func (r *Reconciler) checkEnabled(objectMeta *metav1.ObjectMeta, logger logr.Logger) bool {
-	if len(objectMeta.Labels) == 0 {
-		return false
+// Restore the group of pod from its group members annotation. Nil is returned
+// if pod has no group members.
+func (r *Reconciler) restoreGroup(pod *corev1.Pod, groupID string, logger logr.Logger) (*types.PodClassification, error) {
+	members, err := getGroupMembers(&pod.ObjectMeta)
+	if err != nil {
+		logger.Info("failed to get pod group members", "error", err)
+		return nil, nil
 	}
-	if value, ok := objectMeta.Labels[r.enableLabel]; ok {
-		if value != "1" {
-			logger.Info("found enable label %s but has unexpected value %s", r.enableLabel, value)
-			return false
-		}
-		return true
+	if len(members) == 0 {
+		return nil, nil
 	}
 
-	return false
+	return r.classifier.RestoreGroup(groupID, members, logger)
 }
 
-func evictPod(ctx context.Context, cl client.Client, pod *corev1.Pod) error {
-	eviction := &policyv1.Eviction{
-		DeleteOptions: &metav1.DeleteOptions{GracePeriodSeconds: ptr.To(int64(0))},
+// Get the unblocker to use for a group based on its policies.
+func (r *Reconciler) getUnblocker(policies types.StaggeringGroupPolicies) (unblockertypes.PodUnblocker, error) {
+	if len(policies.Unblocker) == 0 {
+		return r.defaultUnblocker, nil
+	}
+	unblocker, ok := r.unblockers[policies.Unblocker]
+	if !ok {
+		return nil, fmt.Errorf("unknown unblocker: %s", policies.Unblocker)
+	}
+
+	return unblocker, nil
+}
+
+// Get the unblocker to release pod with instead of unblocker. Pods without
+// an owning controller are not recreated when evicted or deleted so they
+// need to be released by an ownerless unblocker.
+func (r *Reconciler) getPodUnblocker(unblocker unblockertypes.PodUnblocker, pod *corev1.Pod) unblockertypes.PodUnblocker {
+	if metav1.GetControllerOf(pod) != nil {
+		return unblocker
+	}
+	if ownerless, ok := r.ownerlessUnblockers[unblocker.Name()]; ok {
+		return ownerless
+	}
+
+	return unblocker
+}
+
+func (r *Reconciler) recordReleaseFailure(ctx context.Context, unblocker unblockertypes.PodUnblocker, pod *corev1.Pod, err error, logger logr.Logger) {
+	r.recorderFactory.RecorderForRootControllerOrNull(ctx, pod, logger).Warnf(
+		EventReasonReleaseFailed,
+		"failed to release pod %s by %s: %v",
+		pod.Name,
+		unblocker.Name(),
+		err)
+}
+
+// Release a blocked pod using unblocker for reason.
+func (r *Reconciler) unblockPod(ctx context.Context, unblocker unblockertypes.PodUnblocker, pod *corev1.Pod, reason string, logger logr.Logger) error {
+	logger.V(1).Info("unblocking pod", "pod", pod.Name, "namespace", pod.Namespace, "unblocker", unblocker.Name())
+	start := time.Now()
+	err := unblocker.Unblock(ctx, pod, logger)
+	recordUnblock(unblocker.Name(), start, err)
+	if err != nil {
+		return err
+	}
+	logger.Info("unblocked pod", "pod", pod.Name, "namespace", pod.Namespace, "unblocker", unblocker.Name())
+	if !pod.CreationTimestamp.IsZero() {
+		blockedDuration.WithLabelValues(reason).Observe(time.Since(pod.CreationTimestamp.Time).Seconds())
 	}
 
-	return cl.SubResource("eviction").Create(ctx, pod, eviction, &client.SubResourceCreateOptions{})
+	return nil
 }
@@ -8,20 +8,28 @@ import (
 	"testing"
 	"time"
 
+	"straggler/pkg/blocker"
 	"straggler/pkg/controller/mocks"
 	"straggler/pkg/controller/types"
 	pacertypes "straggler/pkg/pacer/types"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	pacermockes "straggler/pkg/pacer/mocks"
+	"straggler/pkg/unblocker"
+	unblockermocks "straggler/pkg/unblocker/mocks"
+	unblockertypes "straggler/pkg/unblocker/types"
 )
 
 func setupTest(t *testing.T) (*Reconciler, *mocks.MockClient, *mocks.MockPodClassifier, *mocks.MockPodGroupStandingClassifier, *gomock.Controller) {
@@ -30,7 +38,13 @@ func setupTest(t *testing.T) (*Reconciler, *mocks.MockClient, *mocks.MockPodClas
 	mockClassifier := mocks.NewMockPodClassifier(ctrl)
 	mockGroupClassifier := mocks.NewMockPodGroupStandingClassifier(ctrl)
 
-	reconciler := NewReconciler(mockClient, mockClassifier, mockGroupClassifier)
+	// stub pods can only be unblocked by eviction
+	evict := unblocker.NewEvict(mockClient)
+	unblockers := map[string]unblockertypes.PodUnblocker{
+		evict.Name(): evict,
+	}
+
+	reconciler := NewReconciler(mockClient, mockClassifier, mockGroupClassifier, NewRecorderFactory(mockClient, record.NewFakeRecorder(100)), NewEnableChecker(DefaultEnableLabel, nil), blocker.NewSchedulingGatesPodBlocker(), evict, unblockers, nil)
 	return reconciler, mockClient, mockClassifier, mockGroupClassifier, ctrl
 }
 
@@ -115,9 +129,9 @@ func TestReconcile_PodClassificationFailure(t *testing.T) {
 			Namespace: "default",
 			Name:      "error-pod",
 			Labels: map[string]string{
-				DefaultEnableLabel:         "1",
-				DefaultStaggerGroupIDLabel: "groupid",
-				DefaultStaggeredPodLabel:   "1",
+				DefaultEnableLabel:               "1",
+				DefaultStaggerGroupIDLabel:       "groupid",
+				blocker.DefaultStaggeredPodLabel: "1",
 			},
 		},
 		Spec: corev1.PodSpec{},
@@ -165,9 +179,9 @@ func TestReconcile_PodNotInAnyGroup(t *testing.T) {
 			Namespace: "default",
 			Name:      "ungrouped-pod",
 			Labels: map[string]string{
-				DefaultEnableLabel:         "1",
-				DefaultStaggerGroupIDLabel: "groupid",
-				DefaultStaggeredPodLabel:   "1",
+				DefaultEnableLabel:               "1",
+				DefaultStaggerGroupIDLabel:       "groupid",
+				blocker.DefaultStaggeredPodLabel: "1",
 			},
 		},
 		Spec: corev1.PodSpec{},
@@ -199,6 +213,66 @@ func TestReconcile_PodNotInAnyGroup(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestReconcile_RestoreGroup(t *testing.T) {
+	reconciler, mockClient, mockClassifier, mockGroupClassifier, ctrl := setupTest(t)
+	defer ctrl.Finish()
+
+	mockPacer := pacermockes.NewMockPacer(ctrl)
+
+	req := reconcile.Request{
+		NamespacedName: client.ObjectKey{
+			Namespace: "default",
+			Name:      "restored-pod",
+		},
+	}
+
+	members := []types.GroupMember{{Policy: "policy", Key: "key"}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "restored-pod",
+			Labels: map[string]string{
+				DefaultEnableLabel:               "1",
+				DefaultStaggerGroupIDLabel:       "groupid",
+				blocker.DefaultStaggeredPodLabel: "1",
+			},
+		},
+	}
+	require.NoError(t, setGroupMembers(&pod.ObjectMeta, members))
+
+	mockClient.
+		EXPECT().
+		Get(gomock.Any(), req.NamespacedName, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			*obj.(*corev1.Pod) = *pod
+			return nil
+		})
+
+	// group is not known after a restart and is restored from the pod.
+	mockClassifier.
+		EXPECT().
+		ClassifyByGroupID("groupid", gomock.Any()).
+		Return(nil, nil)
+	mockClassifier.
+		EXPECT().
+		RestoreGroup("groupid", members, gomock.Any()).
+		Return(&types.PodClassification{ID: "groupid", Pacer: mockPacer}, nil)
+
+	mockGroupClassifier.
+		EXPECT().
+		ClassifyPodGroup(gomock.Any(), "groupid", gomock.Any(), gomock.Any()).
+		Return(pacertypes.PodClassification{Blocked: []corev1.Pod{*pod}}, nil)
+	mockPacer.
+		EXPECT().
+		Pace(gomock.Any(), gomock.Any()).
+		Return(nil, nil)
+
+	res, err := reconciler.Reconcile(context.TODO(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{RequeueAfter: DefaultBlockedPodResyncDuration}, res)
+}
+
 func TestReconcile_SuccessfulReconciliation(t *testing.T) {
 	reconciler, mockClient, mockClassifier, mockGroupClassifier, ctrl := setupTest(t)
 	defer ctrl.Finish()
@@ -217,9 +291,9 @@ func TestReconcile_SuccessfulReconciliation(t *testing.T) {
 			Namespace: "default",
 			Name:      "grouped-pod",
 			Labels: map[string]string{
-				DefaultEnableLabel:         "1",
-				DefaultStaggerGroupIDLabel: "groupid",
-				DefaultStaggeredPodLabel:   "1",
+				DefaultEnableLabel:               "1",
+				DefaultStaggerGroupIDLabel:       "groupid",
+				blocker.DefaultStaggeredPodLabel: "1",
 			},
 		},
 		Spec: corev1.PodSpec{},
@@ -263,8 +337,8 @@ func TestReconcile_SuccessfulReconciliation(t *testing.T) {
 	// Set expectation: ClassifyPodGroup
 	mockGroupClassifier.
 		EXPECT().
-		ClassifyPodGroup(gomock.Any(), "groupid", gomock.Any()).
-		Return(readyPods, startingPods, blockedPods, nil)
+		ClassifyPodGroup(gomock.Any(), "groupid", gomock.Any(), gomock.Any()).
+		Return(pacertypes.PodClassification{Ready: readyPods, Starting: startingPods, Blocked: blockedPods}, nil)
 
 	// Set expectation: Pacer.Pace
 	mockPacer.
@@ -330,13 +404,17 @@ func TestReconcile_MaxBlockedDuration(t *testing.T) {
 			Namespace: "default",
 			Name:      "blocked-pod",
 			Labels: map[string]string{
-				DefaultEnableLabel:         "1",
-				DefaultStaggerGroupIDLabel: "groupid",
-				DefaultStaggeredPodLabel:   "1",
+				DefaultEnableLabel:               "1",
+				DefaultStaggerGroupIDLabel:       "groupid",
+				blocker.DefaultStaggeredPodLabel: "1",
 			},
 			CreationTimestamp: metav1.NewTime(time.Now().Add(-100 * time.Millisecond)),
 		},
-		Spec: corev1.PodSpec{},
+		Spec: corev1.PodSpec{
+			SchedulingGates: []corev1.PodSchedulingGate{
+				{Name: blocker.DefaultSchedulingGateName},
+			},
+		},
 	}
 
 	group := &types.PodClassification{
@@ -380,8 +458,8 @@ func TestReconcile_MaxBlockedDuration(t *testing.T) {
 	// Set expectation: ClassifyPodGroup
 	mockGroupClassifier.
 		EXPECT().
-		ClassifyPodGroup(gomock.Any(), "groupid", gomock.Any()).
-		Return(readyPods, startingPods, blockedPods, nil)
+		ClassifyPodGroup(gomock.Any(), "groupid", gomock.Any(), gomock.Any()).
+		Return(pacertypes.PodClassification{Ready: readyPods, Starting: startingPods, Blocked: blockedPods}, nil)
 
 	// Set expectation: Pacer.Pace
 	mockPacer.
@@ -419,3 +497,292 @@ func TestReconcile_MaxBlockedDuration(t *testing.T) {
 	// should not be requeued
 	assert.Equal(t, reconcile.Result{}, res)
 }
+
+func TestReconcile_MaxBlockedDurationNotStaggered(t *testing.T) {
+	reconciler, mockClient, mockClassifier, mockGroupClassifier, ctrl := setupTest(t)
+	defer ctrl.Finish()
+
+	mockPacer := pacermockes.NewMockPacer(ctrl)
+
+	req := reconcile.Request{
+		NamespacedName: client.ObjectKey{
+			Namespace: "default",
+			Name:      "ready-pod",
+		},
+	}
+
+	// old ready pod that was released or never blocked.
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "ready-pod",
+			Labels: map[string]string{
+				DefaultEnableLabel:         "1",
+				DefaultStaggerGroupIDLabel: "groupid",
+			},
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+
+	mockClient.
+		EXPECT().
+		Get(gomock.Any(), req.NamespacedName, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			*obj.(*corev1.Pod) = *pod
+			return nil
+		})
+	mockClassifier.
+		EXPECT().
+		ClassifyByGroupID("groupid", gomock.Any()).
+		Return(&types.PodClassification{
+			ID:    "groupid",
+			Pacer: mockPacer,
+			GroupPolicies: types.StaggeringGroupPolicies{
+				MaxBlockedDuration: 100 * time.Millisecond,
+			},
+		}, nil)
+	mockGroupClassifier.
+		EXPECT().
+		ClassifyPodGroup(gomock.Any(), "groupid", gomock.Any(), gomock.Any()).
+		Return(pacertypes.PodClassification{Ready: []corev1.Pod{*pod}}, nil)
+	mockPacer.
+		EXPECT().
+		Pace(gomock.Any(), gomock.Any()).
+		Return(nil, nil)
+
+	// pod is not released, no eviction expected.
+	res, err := reconciler.Reconcile(context.TODO(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{RequeueAfter: DefaultBlockedPodResyncDuration}, res)
+}
+
+func TestReconcile_UnblockInPlace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mocks.NewMockClient(ctrl)
+	mockClassifier := mocks.NewMockPodClassifier(ctrl)
+	mockGroupClassifier := mocks.NewMockPodGroupStandingClassifier(ctrl)
+	mockPacer := pacermockes.NewMockPacer(ctrl)
+	evict := unblocker.NewEvict(mockClient)
+	patchRemoveGate := unblocker.NewPatchRemoveGate(mockClient, blocker.NewSchedulingGatesPodBlocker(), evict)
+	unblockers := map[string]unblockertypes.PodUnblocker{
+		evict.Name():           evict,
+		patchRemoveGate.Name(): patchRemoveGate,
+	}
+	reconciler := NewReconciler(mockClient, mockClassifier, mockGroupClassifier, NewRecorderFactory(mockClient, record.NewFakeRecorder(100)), NewEnableChecker(DefaultEnableLabel, nil), blocker.NewSchedulingGatesPodBlocker(), evict, unblockers, nil)
+
+	req := reconcile.Request{
+		NamespacedName: client.ObjectKey{
+			Namespace: "default",
+			Name:      "gated-pod",
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "gated-pod",
+			Labels: map[string]string{
+				DefaultEnableLabel:               "1",
+				DefaultStaggerGroupIDLabel:       "groupid",
+				blocker.DefaultStaggeredPodLabel: "1",
+			},
+		},
+		Spec: corev1.PodSpec{
+			SchedulingGates: []corev1.PodSchedulingGate{
+				{Name: blocker.DefaultSchedulingGateName},
+			},
+		},
+	}
+
+	mockClient.
+		EXPECT().
+		Get(gomock.Any(), req.NamespacedName, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			*obj.(*corev1.Pod) = *pod
+			return nil
+		})
+	mockClassifier.
+		EXPECT().
+		ClassifyByGroupID("groupid", gomock.Any()).
+		Return(&types.PodClassification{
+			ID:    "groupid",
+			Pacer: mockPacer,
+			GroupPolicies: types.StaggeringGroupPolicies{
+				Unblocker: unblocker.PatchRemoveGate,
+			},
+		}, nil)
+	mockGroupClassifier.
+		EXPECT().
+		ClassifyPodGroup(gomock.Any(), "groupid", gomock.Any(), gomock.Any()).
+		Return(pacertypes.PodClassification{Blocked: []corev1.Pod{*pod}}, nil)
+	mockPacer.
+		EXPECT().
+		Pace(gomock.Any(), gomock.Any()).
+		Return([]corev1.Pod{*pod}, nil)
+
+	// policy unblocker patches the pod instead of evicting it
+	mockClient.
+		EXPECT().
+		Patch(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, obj client.Object, patch client.Patch, _ ...client.PatchOption) error {
+			patched, ok := obj.(*corev1.Pod)
+			assert.True(t, ok)
+			assert.Equal(t, "gated-pod", patched.Name)
+			assert.Empty(t, patched.Spec.SchedulingGates)
+			assert.NotContains(t, patched.Labels, blocker.DefaultStaggeredPodLabel)
+			assert.Equal(t, unblocker.PatchRemoveGate, patched.Annotations[unblocker.DefaultUnblockedByAnnotation])
+			return nil
+		})
+
+	res, err := reconciler.Reconcile(context.TODO(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{}, res)
+}
+
+func TestReconcile_OwnerlessPod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mocks.NewMockClient(ctrl)
+	mockClassifier := mocks.NewMockPodClassifier(ctrl)
+	mockGroupClassifier := mocks.NewMockPodGroupStandingClassifier(ctrl)
+	mockPacer := pacermockes.NewMockPacer(ctrl)
+	evict := unblockermocks.NewMockPodUnblocker(ctrl)
+	evict.EXPECT().Name().Return(unblocker.Evict).AnyTimes()
+	recreate := unblockermocks.NewMockPodUnblocker(ctrl)
+	recreate.EXPECT().Name().Return(unblocker.Recreate).AnyTimes()
+	unblockers := map[string]unblockertypes.PodUnblocker{
+		unblocker.Evict: evict,
+	}
+	ownerlessUnblockers := map[string]unblockertypes.PodUnblocker{
+		unblocker.Evict: recreate,
+	}
+	recorder := record.NewFakeRecorder(100)
+	reconciler := NewReconciler(mockClient, mockClassifier, mockGroupClassifier, NewRecorderFactory(mockClient, recorder), NewEnableChecker(DefaultEnableLabel, nil), blocker.NewSchedulingGatesPodBlocker(), evict, unblockers, ownerlessUnblockers)
+
+	newPod := func(name string, owners []metav1.OwnerReference) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       "default",
+				Name:            name,
+				UID:             apitypes.UID(name),
+				OwnerReferences: owners,
+				Labels: map[string]string{
+					DefaultEnableLabel:               "1",
+					DefaultStaggerGroupIDLabel:       "groupid",
+					blocker.DefaultStaggeredPodLabel: "1",
+				},
+			},
+		}
+	}
+	ownerless := newPod("ownerless-pod", nil)
+	owned := newPod("owned-pod", []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs", Controller: ptr.To(true)}})
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&ownerless)}
+
+	mockClient.
+		EXPECT().
+		Get(gomock.Any(), req.NamespacedName, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			*obj.(*corev1.Pod) = ownerless
+			return nil
+		})
+	mockClassifier.
+		EXPECT().
+		ClassifyByGroupID("groupid", gomock.Any()).
+		Return(&types.PodClassification{
+			ID:    "groupid",
+			Pacer: mockPacer,
+		}, nil)
+	mockGroupClassifier.
+		EXPECT().
+		ClassifyPodGroup(gomock.Any(), "groupid", gomock.Any(), gomock.Any()).
+		Return(pacertypes.PodClassification{Blocked: []corev1.Pod{ownerless, owned}}, nil)
+	mockPacer.
+		EXPECT().
+		Pace(gomock.Any(), gomock.Any()).
+		Return([]corev1.Pod{ownerless, owned}, nil)
+
+	// pods without a controller owner are recreated and failures are
+	// recorded as events.
+	recreate.EXPECT().Unblock(gomock.Any(), &ownerless, gomock.Any()).Return(errors.New("test error"))
+	evict.EXPECT().Unblock(gomock.Any(), &owned, gomock.Any()).Return(nil)
+	// owner of released pod is looked up to record events.
+	mockClient.
+		EXPECT().
+		Get(gomock.Any(), client.ObjectKey{Namespace: "default", Name: "rs"}, gomock.Any(), gomock.Any()).
+		Return(errors.New("not found"))
+
+	_, err := reconciler.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	events := []string{}
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	assert.Contains(t, events, "Warning ReleaseFailed failed to release pod ownerless-pod by recreate: test error")
+}
+
+func TestReconcile_TimedPacerRequeue(t *testing.T) {
+	reconciler, mockClient, mockClassifier, mockGroupClassifier, ctrl := setupTest(t)
+	defer ctrl.Finish()
+
+	mockPacer := pacermockes.NewMockTimedPacer(ctrl)
+
+	req := reconcile.Request{
+		NamespacedName: client.ObjectKey{
+			Namespace: "default",
+			Name:      "blocked-pod",
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "blocked-pod",
+			Labels: map[string]string{
+				DefaultEnableLabel:               "1",
+				DefaultStaggerGroupIDLabel:       "groupid",
+				blocker.DefaultStaggeredPodLabel: "1",
+			},
+		},
+	}
+
+	mockClient.
+		EXPECT().
+		Get(gomock.Any(), req.NamespacedName, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			*obj.(*corev1.Pod) = *pod
+			return nil
+		})
+	mockClassifier.
+		EXPECT().
+		ClassifyByGroupID("groupid", gomock.Any()).
+		Return(&types.PodClassification{ID: "groupid", Pacer: mockPacer}, nil)
+	mockGroupClassifier.
+		EXPECT().
+		ClassifyPodGroup(gomock.Any(), "groupid", gomock.Any(), gomock.Any()).
+		Return(pacertypes.PodClassification{Blocked: []corev1.Pod{*pod}}, nil)
+	// nothing allowed until next token
+	mockPacer.
+		EXPECT().
+		Pace(gomock.Any(), gomock.Any()).
+		Return(nil, nil)
+	mockPacer.
+		EXPECT().
+		NextPace().
+		Return(5 * time.Second)
+
+	res, err := reconciler.Reconcile(context.TODO(), req)
+
+	assert.NoError(t, err)
+	// should be requeued when the pacer may allow more pods
+	assert.Equal(t, reconcile.Result{RequeueAfter: 5 * time.Second}, res)
+}
@@ -3,12 +3,39 @@
 package controller
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"straggler/pkg/controller/types"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Event reasons.
+	EventReasonStaggered                  = "Staggered"
+	EventReasonReleased                   = "Released"
+	EventReasonReleasedMaxBlockedDuration = "ReleasedMaxBlockedDuration"
+	EventReasonReleaseFailed              = "ReleaseFailed"
+	EventReasonRecreateFailed             = "RecreateFailed"
+	EventReasonJobPatched                 = "JobPatched"
+	EventReasonJobBypassed                = "JobBypassed"
+)
+
+var (
+	// Maximum number of owner references to follow looking for the root
+	// controller.
+	DefaultMaxOwnerDepth = 8
+	// Timeout of looking up root controllers in the background.
+	DefaultRootControllerTimeout = 10 * time.Second
 )
 
 type Recorder struct {
@@ -50,3 +77,183 @@ func (r *Recorder) Logf(logger logr.Logger, v int, reason, format string, args .
 			}
 	*/
 }
+
+var (
+	_ types.ObjectRecorder        = &multiRecorder{}
+	_ types.ObjectRecorder        = &nullRecorder{}
+	_ types.ObjectRecorder        = &asyncRecorder{}
+	_ types.ObjectRecorderFactory = &recorderFactory{}
+)
+
+type recorderFactory struct {
+	client   client.Client
+	recorder record.EventRecorder
+}
+
+// Create a recorder factory that emits events using recorder. client is used
+// to fetch metadata of owners while walking controller references.
+func NewRecorderFactory(client client.Client, recorder record.EventRecorder) types.ObjectRecorderFactory {
+	return &recorderFactory{
+		client:   client,
+		recorder: recorder,
+	}
+}
+
+// Same as RecorderForRootController but returns a recorder that does nothing
+// on errors.
+func (f *recorderFactory) RecorderForRootControllerOrNull(ctx context.Context, object runtime.Object, logger logr.Logger) types.ObjectRecorder {
+	recorder, err := f.RecorderForRootController(ctx, object, logger)
+	if err != nil {
+		logger.Info("failed to create recorder for root controller", "error", err)
+		return &nullRecorder{}
+	}
+
+	return recorder
+}
+
+// Same as RecorderForRootControllerOrNull but the root controller is looked
+// up in the background when events are emitted, such that callers, like
+// admission webhooks, are not blocked by fetching owners.
+func (f *recorderFactory) AsyncRecorderForRootController(object runtime.Object, logger logr.Logger) types.ObjectRecorder {
+	return &asyncRecorder{
+		factory: f,
+		object:  object.DeepCopyObject(),
+		logger:  logger,
+	}
+}
+
+// Create a recorder that emits events on object's root controller, found by
+// following controller owner references. If object exists, that is it has
+// a UID, events are also emitted on it. Objects without a root controller
+// that have neither a name nor a UID cannot be referenced by events and
+// none are emitted.
+func (f *recorderFactory) RecorderForRootController(ctx context.Context, object runtime.Object, logger logr.Logger) (types.ObjectRecorder, error) {
+	objectMeta, err := meta.Accessor(object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object metadata: %v", err)
+	}
+
+	recorders := make([]types.ObjectRecorder, 0)
+	if len(objectMeta.GetUID()) > 0 {
+		recorders = append(recorders, NewRecorderForObject(f.recorder, object))
+	}
+
+	root, err := f.getRootController(ctx, objectMeta.GetNamespace(), objectMeta.GetOwnerReferences(), logger)
+	if err != nil {
+		return nil, err
+	}
+	if root != nil {
+		recorders = append(recorders, NewRecorderForObject(f.recorder, root))
+	} else if len(recorders) == 0 && len(objectMeta.GetName()) > 0 {
+		// no root controller and object does not exist yet.
+		recorders = append(recorders, NewRecorderForObject(f.recorder, object))
+	}
+
+	return &multiRecorder{recorders: recorders}, nil
+}
+
+// Follow controller references starting at ownerRefs and return the
+// metadata of the top most controller, or nil if there is none.
+func (f *recorderFactory) getRootController(ctx context.Context, namespace string, ownerRefs []metav1.OwnerReference, logger logr.Logger) (*metav1.PartialObjectMetadata, error) {
+	var root *metav1.PartialObjectMetadata
+	for depth := 0; depth < DefaultMaxOwnerDepth; depth++ {
+		ref := getControllerRef(ownerRefs)
+		if ref == nil {
+			break
+		}
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse owner api version: %v", err)
+		}
+		gvk := gv.WithKind(ref.Kind)
+		owner := &metav1.PartialObjectMetadata{}
+		owner.SetGroupVersionKind(gvk)
+		err = f.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, owner)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get owner %s %s: %v", ref.Kind, ref.Name, err)
+		}
+		// type is needed to create event references.
+		owner.SetGroupVersionKind(gvk)
+		logger.V(10).Info("found owner controller", "kind", ref.Kind, "name", ref.Name)
+		root = owner
+		ownerRefs = owner.OwnerReferences
+	}
+
+	return root, nil
+}
+
+func getControllerRef(ownerRefs []metav1.OwnerReference) *metav1.OwnerReference {
+	for i := range ownerRefs {
+		if ownerRefs[i].Controller != nil && *ownerRefs[i].Controller {
+			return &ownerRefs[i]
+		}
+	}
+	return nil
+}
+
+// Recorder that emits events to a set of recorders.
+type multiRecorder struct {
+	recorders []types.ObjectRecorder
+}
+
+func (r *multiRecorder) Normalf(reason, format string, args ...interface{}) {
+	for _, recorder := range r.recorders {
+		recorder.Normalf(reason, format, args...)
+	}
+}
+
+func (r *multiRecorder) Warnf(reason, format string, args ...interface{}) {
+	for _, recorder := range r.recorders {
+		recorder.Warnf(reason, format, args...)
+	}
+}
+
+func (r *multiRecorder) Logf(logger logr.Logger, v int, reason, format string, args ...interface{}) {
+	for _, recorder := range r.recorders {
+		recorder.Logf(logger, v, reason, format, args...)
+	}
+}
+
+// Recorder that looks up the root controller of object in the background
+// when events are emitted.
+type asyncRecorder struct {
+	factory *recorderFactory
+	object  runtime.Object
+	logger  logr.Logger
+}
+
+func (r *asyncRecorder) Normalf(reason, format string, args ...interface{}) {
+	r.record(func(recorder types.ObjectRecorder) {
+		recorder.Normalf(reason, format, args...)
+	})
+}
+
+func (r *asyncRecorder) Warnf(reason, format string, args ...interface{}) {
+	r.record(func(recorder types.ObjectRecorder) {
+		recorder.Warnf(reason, format, args...)
+	})
+}
+
+func (r *asyncRecorder) Logf(logger logr.Logger, v int, reason, format string, args ...interface{}) {
+	r.record(func(recorder types.ObjectRecorder) {
+		recorder.Logf(logger, v, reason, format, args...)
+	})
+}
+
+func (r *asyncRecorder) record(emit func(recorder types.ObjectRecorder)) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultRootControllerTimeout)
+		defer cancel()
+
+		emit(r.factory.RecorderForRootControllerOrNull(ctx, r.object, r.logger))
+	}()
+}
+
+// Recorder that discards all events.
+type nullRecorder struct{}
+
+func (r *nullRecorder) Normalf(reason, format string, args ...interface{}) {}
+
+func (r *nullRecorder) Warnf(reason, format string, args ...interface{}) {}
+
+func (r *nullRecorder) Logf(logger logr.Logger, v int, reason, format string, args ...interface{}) {}
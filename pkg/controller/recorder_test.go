@@ -0,0 +1,101 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRecorderForRootController(t *testing.T) {
+	logger := testr.New(t)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "deployment",
+			UID:       "deployment-uid",
+		},
+	}
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "replicaset",
+			UID:       "replicaset-uid",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "Deployment", Name: "deployment", UID: "deployment-uid", Controller: ptr.To(true)},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "pod",
+			UID:       "pod-uid",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "replicaset", UID: "replicaset-uid", Controller: ptr.To(true)},
+			},
+		},
+	}
+	cl := fake.NewClientBuilder().
+		WithScheme(clientgoscheme.Scheme).
+		WithObjects(deployment, replicaSet, pod).
+		Build()
+	fakeRecorder := record.NewFakeRecorder(10)
+	fakeRecorder.IncludeObject = true
+	factory := NewRecorderFactory(cl, fakeRecorder)
+
+	// existing pod: events on both the pod and the deployment
+	recorder, err := factory.RecorderForRootController(context.TODO(), pod, logger)
+	require.NoError(t, err)
+	recorder.Normalf(EventReasonStaggered, "test %d", 1)
+	require.Len(t, fakeRecorder.Events, 2)
+	assert.Contains(t, <-fakeRecorder.Events, "kind=Pod")
+	event := <-fakeRecorder.Events
+	assert.Contains(t, event, "kind=Deployment")
+	assert.Contains(t, event, fmt.Sprintf("%s test 1", EventReasonStaggered))
+
+	// pod in admission: events on the deployment only
+	admittedPod := pod.DeepCopy()
+	admittedPod.UID = ""
+	recorder = factory.RecorderForRootControllerOrNull(context.TODO(), admittedPod, logger)
+	recorder.Warnf(EventReasonStaggered, "test")
+	require.Len(t, fakeRecorder.Events, 1)
+	assert.Contains(t, <-fakeRecorder.Events, "kind=Deployment")
+
+	// resolved in the background
+	recorder = factory.AsyncRecorderForRootController(admittedPod, logger)
+	recorder.Normalf(EventReasonStaggered, "test")
+	require.Eventually(t, func() bool { return len(fakeRecorder.Events) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Contains(t, <-fakeRecorder.Events, "kind=Deployment")
+
+	// pod in admission without owner, name or uid cannot be referenced
+	admittedPod.OwnerReferences = nil
+	admittedPod.Name = ""
+	admittedPod.GenerateName = "pod-"
+	recorder = factory.RecorderForRootControllerOrNull(context.TODO(), admittedPod, logger)
+	recorder.Normalf(EventReasonStaggered, "test")
+	assert.Len(t, fakeRecorder.Events, 0)
+
+	// missing owner
+	pod.OwnerReferences[0].Name = "missing"
+	_, err = factory.RecorderForRootController(context.TODO(), pod, logger)
+	assert.Error(t, err)
+	recorder = factory.RecorderForRootControllerOrNull(context.TODO(), pod, logger)
+	recorder.Normalf(EventReasonStaggered, "test")
+	assert.Len(t, fakeRecorder.Events, 0)
+}
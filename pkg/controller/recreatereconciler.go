@@ -0,0 +1,117 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"straggler/pkg/controller/types"
+	"straggler/pkg/unblocker"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	// Interval to check if pods to be recreated are removed.
+	DefaultRecreatePollInterval = 1 * time.Second
+	// Time allowed for pods to be removed on top of their termination grace
+	// period.
+	DefaultRecreateRemovalMargin = 30 * time.Second
+)
+
+var _ reconcile.Reconciler = &RecreateReconciler{}
+
+// Reconciles config maps created by the recreate unblocker into the pods
+// they keep. Config maps are only deleted once their pods are created, or
+// if their original pods turn out not to be removed.
+type RecreateReconciler struct {
+	client          client.Client
+	recorderFactory types.ObjectRecorderFactory
+	pollInterval    time.Duration
+	removalMargin   time.Duration
+}
+
+// Create a new recreate reconciler.
+func NewRecreateReconciler(client client.Client, recorderFactory types.ObjectRecorderFactory) *RecreateReconciler {
+	return &RecreateReconciler{
+		client:          client,
+		recorderFactory: recorderFactory,
+		pollInterval:    DefaultRecreatePollInterval,
+		removalMargin:   DefaultRecreateRemovalMargin,
+	}
+}
+
+func (r *RecreateReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.client.Get(ctx, request.NamespacedName, configMap); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	pod, err := unblocker.GetRecreatedPod(configMap)
+	if err != nil {
+		// keep it around for inspection since there is nothing to retry.
+		logger.Error(err, "failed to get pod to recreate", "configMap", configMap.Name)
+		return reconcile.Result{}, nil
+	}
+	uid := pod.Annotations[unblocker.DefaultRecreatedAnnotation]
+
+	current := &corev1.Pod{}
+	err = r.client.Get(ctx, client.ObjectKeyFromObject(pod), current)
+	switch {
+	case k8serrors.IsNotFound(err):
+		logger.Info("recreating pod", "pod", pod.Name, "namespace", pod.Namespace)
+		if err := r.client.Create(ctx, pod); err != nil {
+			r.recorderFactory.RecorderForRootControllerOrNull(ctx, pod, logger).Warnf(
+				EventReasonRecreateFailed,
+				"failed to recreate pod %s: %v",
+				pod.Name,
+				err)
+			return reconcile.Result{}, fmt.Errorf("failed to recreate pod: %v", err)
+		}
+	case err != nil:
+		return reconcile.Result{}, err
+	case current.Annotations[unblocker.DefaultRecreatedAnnotation] == uid:
+		logger.V(1).Info("pod is already recreated", "pod", pod.Name, "namespace", pod.Namespace)
+	case string(current.UID) == uid:
+		overdue := time.Now().After(configMap.CreationTimestamp.Add(r.removalTimeout(current)))
+		if overdue && current.DeletionTimestamp.IsZero() {
+			// the pod is still there to be released again.
+			logger.Info("pod to recreate was not removed", "pod", pod.Name, "namespace", pod.Namespace)
+			break
+		}
+		if overdue {
+			logger.Info("waiting for pod to be removed", "pod", pod.Name, "namespace", pod.Namespace)
+		}
+		return reconcile.Result{RequeueAfter: r.pollInterval}, nil
+	default:
+		r.recorderFactory.RecorderForRootControllerOrNull(ctx, pod, logger).Warnf(
+			EventReasonRecreateFailed,
+			"failed to recreate pod %s: name is used by pod %s",
+			pod.Name,
+			current.UID)
+		return reconcile.Result{}, fmt.Errorf("pod name is used by another pod: %s", current.UID)
+	}
+
+	if err := r.client.Delete(ctx, configMap); client.IgnoreNotFound(err) != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to delete pod manifest: %v", err)
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// Get the time allowed for pod to be removed.
+func (r *RecreateReconciler) removalTimeout(pod *corev1.Pod) time.Duration {
+	gracePeriod := int64(corev1.DefaultTerminationGracePeriodSeconds)
+	if pod.Spec.TerminationGracePeriodSeconds != nil {
+		gracePeriod = *pod.Spec.TerminationGracePeriodSeconds
+	}
+
+	return time.Duration(gracePeriod)*time.Second + r.removalMargin
+}
@@ -0,0 +1,131 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"straggler/pkg/unblocker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newTestRecreateConfigMap(t *testing.T, created time.Time) *corev1.ConfigMap {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "pod",
+			Annotations: map[string]string{
+				unblocker.DefaultRecreatedAnnotation: "uid",
+			},
+		},
+	}
+	raw, err := json.Marshal(pod)
+	require.NoError(t, err)
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "default",
+			Name:              unblocker.DefaultRecreateConfigMapPrefix + "uid",
+			CreationTimestamp: metav1.NewTime(created),
+			Labels:            map[string]string{unblocker.DefaultRecreatePodLabel: "uid"},
+		},
+		Data: map[string]string{"pod": string(raw)},
+	}
+}
+
+func TestRecreateReconciler(t *testing.T) {
+	ctx := context.TODO()
+	original := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "pod",
+			UID:       "uid",
+		},
+	}
+	configMap := newTestRecreateConfigMap(t, time.Now())
+	cl := fake.NewClientBuilder().WithObjects(original, configMap).Build()
+	recorder := record.NewFakeRecorder(100)
+	reconciler := NewRecreateReconciler(cl, NewRecorderFactory(cl, recorder))
+	request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(configMap)}
+
+	// wait for the original pod to be removed
+	result, err := reconciler.Reconcile(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, reconcile.Result{RequeueAfter: DefaultRecreatePollInterval}, result)
+
+	require.NoError(t, cl.Delete(ctx, original))
+	result, err = reconciler.Reconcile(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, reconcile.Result{}, result)
+	recreated := &corev1.Pod{}
+	require.NoError(t, cl.Get(ctx, client.ObjectKeyFromObject(original), recreated))
+	assert.Equal(t, "uid", recreated.Annotations[unblocker.DefaultRecreatedAnnotation])
+	err = cl.Get(ctx, request.NamespacedName, &corev1.ConfigMap{})
+	assert.True(t, k8serrors.IsNotFound(err))
+
+	// manifests of pods that are already recreated are deleted
+	require.NoError(t, cl.Create(ctx, newTestRecreateConfigMap(t, time.Now())))
+	_, err = reconciler.Reconcile(ctx, request)
+	require.NoError(t, err)
+	err = cl.Get(ctx, request.NamespacedName, &corev1.ConfigMap{})
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+func TestRecreateReconcilerNotRemoved(t *testing.T) {
+	ctx := context.TODO()
+	original := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "pod",
+			UID:       "uid",
+		},
+	}
+	configMap := newTestRecreateConfigMap(t, time.Now().Add(-time.Hour))
+	cl := fake.NewClientBuilder().WithObjects(original, configMap).Build()
+	recorder := record.NewFakeRecorder(100)
+	reconciler := NewRecreateReconciler(cl, NewRecorderFactory(cl, recorder))
+	request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(configMap)}
+
+	// pods that are not removed long after their grace period can be
+	// released again so their manifests are not needed.
+	_, err := reconciler.Reconcile(ctx, request)
+	require.NoError(t, err)
+	err = cl.Get(ctx, request.NamespacedName, &corev1.ConfigMap{})
+	assert.True(t, k8serrors.IsNotFound(err))
+	require.NoError(t, cl.Get(ctx, client.ObjectKeyFromObject(original), &corev1.Pod{}))
+}
+
+func TestRecreateReconcilerNameUsed(t *testing.T) {
+	ctx := context.TODO()
+	other := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "pod",
+			UID:       "other",
+		},
+	}
+	configMap := newTestRecreateConfigMap(t, time.Now())
+	cl := fake.NewClientBuilder().WithObjects(other, configMap).Build()
+	recorder := record.NewFakeRecorder(100)
+	reconciler := NewRecreateReconciler(cl, NewRecorderFactory(cl, recorder))
+	request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(configMap)}
+
+	// manifest is kept until the pod can be created.
+	_, err := reconciler.Reconcile(ctx, request)
+	require.Error(t, err)
+	require.NoError(t, cl.Get(ctx, request.NamespacedName, &corev1.ConfigMap{}))
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "Warning RecreateFailed")
+}
@@ -0,0 +1,35 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"straggler/pkg/controller/types"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ types.StatefulSetGetter = &statefulSetGetter{}
+
+type statefulSetGetter struct {
+	reader client.Reader
+}
+
+// Create a new StatefulSet getter that gets StatefulSets from reader.
+func NewStatefulSetGetter(reader client.Reader) types.StatefulSetGetter {
+	return &statefulSetGetter{
+		reader: reader,
+	}
+}
+
+func (s *statefulSetGetter) GetStatefulSet(ctx context.Context, namespace, name string) (*appsv1.StatefulSet, error) {
+	statefulSet := &appsv1.StatefulSet{}
+	if err := s.reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, statefulSet); err != nil {
+		return nil, fmt.Errorf("failed to get statefulset %s/%s: %v", namespace, name, err)
+	}
+
+	return statefulSet, nil
+}
@@ -10,6 +10,7 @@ import (
 	pacertypes "straggler/pkg/pacer/types"
 
 	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -27,12 +28,26 @@ type ObjectRecorder interface {
 type ObjectRecorderFactory interface {
 	RecorderForRootControllerOrNull(ctx context.Context, object runtime.Object, logger logr.Logger) ObjectRecorder
 	RecorderForRootController(ctx context.Context, object runtime.Object, logger logr.Logger) (ObjectRecorder, error)
+	// Same as RecorderForRootControllerOrNull but the root controller is
+	// looked up in the background when events are emitted.
+	AsyncRecorderForRootController(object runtime.Object, logger logr.Logger) ObjectRecorder
 }
 
 // Policies applied to a staggering group, which may be compoised
 // of multiple staggering policies configs.
 type StaggeringGroupPolicies struct {
 	MaxBlockedDuration time.Duration
+	// Strategy used to release blocked pods. Empty for default.
+	Unblocker string
+	// Definition of ready pods.
+	Readiness configtypes.Readiness
+}
+
+// A policy and grouping key pair. Staggering groups are made of one or more
+// members.
+type GroupMember struct {
+	Policy string `json:"policy"`
+	Key    string `json:"key"`
 }
 
 // Pod classification result.
@@ -46,6 +61,10 @@ type PodClassification struct {
 	// staggering group based on the underlying one or more
 	// matched policies.
 	GroupPolicies StaggeringGroupPolicies
+	// Names of the staggering policies that matched this pod.
+	Policies []string
+	// Members of the staggering group that can be used to restore it.
+	Members []GroupMember
 }
 
 // Classify a pod to a staggering pacer.
@@ -53,12 +72,35 @@ type PodClassifier interface {
 	// Classify a pod to a staggering group. If pod does not belong to any group
 	// nil is returned.
 	Classify(podMeta metav1.ObjectMeta, podSpec corev1.PodSpec, logger logr.Logger) (*PodClassification, error)
+	// Get the ID of the staggering group a pod belongs to without creating
+	// or refreshing the group. Empty if pod does not belong to any group.
+	GetGroupID(podMeta metav1.ObjectMeta, podSpec corev1.PodSpec, logger logr.Logger) (string, error)
 	ClassifyByGroupID(groupID string, logger logr.Logger) (*PodClassification, error)
+	// Restore a staggering group that is no longer known, for example after a
+	// restart, from its members. If members no longer make up groupID, for
+	// example due to removed policies, nil is returned.
+	RestoreGroup(groupID string, members []GroupMember, logger logr.Logger) (*PodClassification, error)
 }
 
 // Interface to provide classification of all pods within a staggering group.
 type PodGroupStandingClassifier interface {
-	ClassifyPodGroup(ctx context.Context, groupID string, logger logr.Logger) (ready, starting, blocked []corev1.Pod, err error)
+	ClassifyPodGroup(ctx context.Context, groupID string, readiness configtypes.Readiness, logger logr.Logger) (pacertypes.PodClassification, error)
+}
+
+// Interface to get labels of namespaces.
+type NamespaceLabeler interface {
+	NamespaceLabels(ctx context.Context, namespace string) (map[string]string, error)
+}
+
+// Interface to check if staggering is enabled for pods, or pod templates, by
+// their labels and namespace.
+type EnableChecker interface {
+	IsEnabled(ctx context.Context, namespace string, labels map[string]string, logger logr.Logger) bool
+}
+
+// Interface to get StatefulSets owning pods.
+type StatefulSetGetter interface {
+	GetStatefulSet(ctx context.Context, namespace, name string) (*appsv1.StatefulSet, error)
 }
 
 // Configuration interface for a pod classifier.
@@ -68,6 +110,12 @@ type PodClassifierConfigurator interface {
 	UpdateConfig(config configtypes.StaggerGroup, logger logr.Logger) error
 }
 
+// A pod classifier that can be reconfigured at runtime.
+type ConfigurablePodClassifier interface {
+	PodClassifier
+	PodClassifierConfigurator
+}
+
 // An implementation that is used by the admission controller to minimize
 // race admitted pods and committed pods.
 // It is assumed that it is best effort.
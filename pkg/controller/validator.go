@@ -0,0 +1,191 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"straggler/pkg/blocker"
+	blockertypes "straggler/pkg/blocker/types"
+	"straggler/pkg/controller/types"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	ValidationModeWarn = "warn"
+	ValidationModeDeny = "deny"
+
+	violationNoPolicy          = "noPolicy"
+	violationGroupMismatch     = "groupMismatch"
+	violationStaggeredMismatch = "staggeredMismatch"
+)
+
+var _ admission.CustomValidator = &Validator{}
+
+// Validates pods at creation, after admission, for straggler labels that are
+// inconsistent with policies and pod blocking. Violations are returned as
+// admission warnings, or deny pod creation in deny mode.
+type Validator struct {
+	classifier    types.PodClassifier
+	podBlocker    blockertypes.PodBlocker
+	enableChecker types.EnableChecker
+
+	staggerGroupIDLabel string
+	jobPodLabel         string
+
+	mode string
+}
+
+func NewValidator(
+	classifier types.PodClassifier,
+	podBlocker blockertypes.PodBlocker,
+	enableChecker types.EnableChecker,
+	mode string,
+) *Validator {
+	return &Validator{
+		classifier:          classifier,
+		podBlocker:          podBlocker,
+		enableChecker:       enableChecker,
+		staggerGroupIDLabel: DefaultStaggerGroupIDLabel,
+		jobPodLabel:         DefaultJobPodLabel,
+		mode:                mode,
+	}
+}
+
+func (v *Validator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	logger := logf.FromContext(ctx)
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+	// pods created without an explicit namespace get it from the request.
+	if len(pod.Namespace) == 0 {
+		if req, err := admission.RequestFromContext(ctx); err == nil {
+			pod.Namespace = req.Namespace
+		}
+	}
+
+	violations := v.validatePod(ctx, pod, logger)
+	if len(violations) == 0 {
+		return nil, nil
+	}
+
+	messages := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		validationViolationsTotal.WithLabelValues(violation.reason, v.mode).Inc()
+		messages = append(messages, violation.message)
+	}
+	logger.Info("pod failed staggering validation", "violations", messages, "mode", v.mode)
+	if v.mode == ValidationModeDeny {
+		return nil, fmt.Errorf("pod %s is misconfigured for staggering: %s", podName(pod), strings.Join(messages, "; "))
+	}
+
+	return admission.Warnings(messages), nil
+}
+
+func (v *Validator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *Validator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+type violation struct {
+	reason  string
+	message string
+}
+
+// Get violations of pod in a stable order. Pods are classified without
+// creating or refreshing their groups, except for blocked pods whose groups
+// are restored from their members annotation when not known to this
+// replica.
+func (v *Validator) validatePod(ctx context.Context, pod *corev1.Pod, logger logr.Logger) []violation {
+	violations := make([]violation, 0)
+	// job pods are blocked without classification and are handled by the
+	// reconciler.
+	if _, ok := pod.Labels[v.jobPodLabel]; ok {
+		return violations
+	}
+
+	_, staggered := pod.Labels[blocker.DefaultStaggeredPodLabel]
+	blocked := v.podBlocker.IsBlocked(&pod.Spec)
+	if staggered != blocked {
+		violations = append(violations, violation{violationStaggeredMismatch, fmt.Sprintf(
+			"label %s is set to %v but pod blocked is %v",
+			blocker.DefaultStaggeredPodLabel,
+			staggered,
+			blocked)})
+	}
+
+	groupID, hasGroup := pod.Labels[v.staggerGroupIDLabel]
+	switch {
+	case hasGroup && blocked:
+		// blocked pod specs are modified so they cannot be classified again.
+		group, err := v.classifier.ClassifyByGroupID(groupID, logger)
+		if err != nil {
+			logger.Info("failed to classify pod by group", "id", groupID, "error", err)
+			break
+		}
+		if group == nil {
+			// groups are only known to the replica that admitted the pod and
+			// expire, so restore it the same way the reconciler does.
+			group, err = v.restoreGroup(pod, groupID, logger)
+			if err != nil {
+				logger.Info("failed to restore pod group", "id", groupID, "error", err)
+				break
+			}
+		}
+		if group == nil {
+			violations = append(violations, violation{violationGroupMismatch, fmt.Sprintf(
+				"label %s refers to unknown group %s",
+				v.staggerGroupIDLabel,
+				groupID)})
+		}
+	case hasGroup:
+		id, err := v.classifier.GetGroupID(pod.ObjectMeta, pod.Spec, logger)
+		if err != nil {
+			logger.Info("failed to classify pod", "error", err)
+			break
+		}
+		if id != groupID {
+			violations = append(violations, violation{violationGroupMismatch, fmt.Sprintf(
+				"label %s is set to %s which is not the group of the pod",
+				v.staggerGroupIDLabel,
+				groupID)})
+		}
+	case v.enableChecker.IsEnabled(ctx, pod.Namespace, pod.Labels, logger):
+		id, err := v.classifier.GetGroupID(pod.ObjectMeta, pod.Spec, logger)
+		if err != nil {
+			logger.Info("failed to classify pod", "error", err)
+			break
+		}
+		if len(id) == 0 {
+			violations = append(violations, violation{violationNoPolicy,
+				"pod is enabled for staggering but does not match any staggering policy"})
+		}
+	}
+
+	return violations
+}
+
+// Restore the group of pod from its members annotation.
+func (v *Validator) restoreGroup(pod *corev1.Pod, groupID string, logger logr.Logger) (*types.PodClassification, error) {
+	members, err := getGroupMembers(&pod.ObjectMeta)
+	if err != nil {
+		logger.Info("failed to get pod group members", "error", err)
+		return nil, nil
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	return v.classifier.RestoreGroup(groupID, members, logger)
+}
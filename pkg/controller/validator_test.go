@@ -0,0 +1,206 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"straggler/pkg/blocker"
+	blockermocks "straggler/pkg/blocker/mocks"
+	configtypes "straggler/pkg/config/types"
+	"straggler/pkg/controller/mocks"
+	"straggler/pkg/controller/types"
+	pacermocks "straggler/pkg/pacer/mocks"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidatorNoPolicy(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				DefaultEnableLabel: "1",
+			},
+		},
+	}
+
+	classifier := mocks.NewMockPodClassifier(mockCtrl)
+	classifier.EXPECT().GetGroupID(pod.ObjectMeta, pod.Spec, gomock.Any()).Return("", nil).Times(2)
+	podBlocker := blockermocks.NewMockPodBlocker(mockCtrl)
+	podBlocker.EXPECT().IsBlocked(gomock.Any()).Return(false).Times(2)
+
+	validator := NewValidator(classifier, podBlocker, NewEnableChecker(DefaultEnableLabel, nil), ValidationModeWarn)
+	warnings, err := validator.ValidateCreate(context.Background(), &pod)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+
+	validator = NewValidator(classifier, podBlocker, NewEnableChecker(DefaultEnableLabel, nil), ValidationModeDeny)
+	warnings, err = validator.ValidateCreate(context.Background(), &pod)
+	require.Error(t, err)
+	require.Empty(t, warnings)
+}
+
+func TestValidatorNoPolicyNamespace(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "enabled",
+		},
+	}
+
+	classifier := mocks.NewMockPodClassifier(mockCtrl)
+	classifier.EXPECT().GetGroupID(pod.ObjectMeta, pod.Spec, gomock.Any()).Return("", nil)
+	podBlocker := blockermocks.NewMockPodBlocker(mockCtrl)
+	podBlocker.EXPECT().IsBlocked(gomock.Any()).Return(false).Times(2)
+	namespaces := mocks.NewMockNamespaceLabeler(mockCtrl)
+	namespaces.EXPECT().NamespaceLabels(gomock.Any(), "enabled").Return(map[string]string{DefaultEnableLabel: "1"}, nil)
+
+	// pods in enabled namespaces must match a policy
+	validator := NewValidator(classifier, podBlocker, NewEnableChecker(DefaultEnableLabel, namespaces), ValidationModeDeny)
+	_, err := validator.ValidateCreate(context.Background(), &pod)
+	require.Error(t, err)
+
+	// unless they opted out
+	pod.Labels = map[string]string{DefaultEnableLabel: "0"}
+	_, err = validator.ValidateCreate(context.Background(), &pod)
+	require.NoError(t, err)
+}
+
+func TestValidatorGroupHijack(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				DefaultStaggerGroupIDLabel: "othergroup",
+			},
+		},
+	}
+
+	classifier := mocks.NewMockPodClassifier(mockCtrl)
+	classifier.EXPECT().GetGroupID(pod.ObjectMeta, pod.Spec, gomock.Any()).Return("testid", nil)
+	podBlocker := blockermocks.NewMockPodBlocker(mockCtrl)
+	podBlocker.EXPECT().IsBlocked(gomock.Any()).Return(false)
+
+	validator := NewValidator(classifier, podBlocker, NewEnableChecker(DefaultEnableLabel, nil), ValidationModeDeny)
+	_, err := validator.ValidateCreate(context.Background(), &pod)
+	require.Error(t, err)
+}
+
+func TestValidatorStaggeredMismatch(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				blocker.DefaultStaggeredPodLabel: "1",
+			},
+		},
+	}
+
+	classifier := mocks.NewMockPodClassifier(mockCtrl)
+	podBlocker := blockermocks.NewMockPodBlocker(mockCtrl)
+	podBlocker.EXPECT().IsBlocked(gomock.Any()).Return(false)
+
+	validator := NewValidator(classifier, podBlocker, NewEnableChecker(DefaultEnableLabel, nil), ValidationModeWarn)
+	warnings, err := validator.ValidateCreate(context.Background(), &pod)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+}
+
+func TestValidatorAdmittedPods(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	admitted := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				DefaultEnableLabel:         "1",
+				DefaultStaggerGroupIDLabel: "testid",
+			},
+		},
+	}
+	blocked := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				DefaultEnableLabel:               "1",
+				DefaultStaggerGroupIDLabel:       "testid",
+				blocker.DefaultStaggeredPodLabel: "1",
+			},
+		},
+	}
+
+	classifier := mocks.NewMockPodClassifier(mockCtrl)
+	classifier.EXPECT().GetGroupID(admitted.ObjectMeta, admitted.Spec, gomock.Any()).Return("testid", nil)
+	classifier.EXPECT().ClassifyByGroupID("testid", gomock.Any()).Return(&types.PodClassification{ID: "testid"}, nil)
+	podBlocker := blockermocks.NewMockPodBlocker(mockCtrl)
+	podBlocker.EXPECT().IsBlocked(&admitted.Spec).Return(false)
+	podBlocker.EXPECT().IsBlocked(&blocked.Spec).Return(true)
+
+	validator := NewValidator(classifier, podBlocker, NewEnableChecker(DefaultEnableLabel, nil), ValidationModeDeny)
+	warnings, err := validator.ValidateCreate(context.Background(), &admitted)
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+	warnings, err = validator.ValidateCreate(context.Background(), &blocked)
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+}
+
+func TestValidatorBlockedPodRestoresGroup(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	testNamespace := "testnamespace"
+	pacer := pacermocks.NewMockPacer(mockCtrl)
+	pacer.EXPECT().ID().Return("pacer").AnyTimes()
+	pacerFactory := pacermocks.NewMockPacerFactory(mockCtrl)
+	pacerFactory.EXPECT().New(testNamespace).Return(pacer).AnyTimes()
+	config := configtypes.StaggerGroup{
+		Name:               "config1",
+		GroupingExpression: ".metadata.namespace",
+		PacerFactory:       pacerFactory,
+	}
+
+	// the pod is admitted by another replica.
+	admitting := NewPodClassifier(nil)
+	require.NoError(t, admitting.AddConfig(config, logr.Discard()))
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+		},
+	}
+	group, err := admitting.Classify(pod.ObjectMeta, pod.Spec, logr.Discard())
+	require.NoError(t, err)
+	require.NotNil(t, group)
+	pod.Labels = map[string]string{
+		DefaultStaggerGroupIDLabel:       group.ID,
+		blocker.DefaultStaggeredPodLabel: "1",
+	}
+
+	classifier := NewPodClassifier(nil)
+	require.NoError(t, classifier.AddConfig(config, logr.Discard()))
+	podBlocker := blockermocks.NewMockPodBlocker(mockCtrl)
+	podBlocker.EXPECT().IsBlocked(gomock.Any()).Return(true).AnyTimes()
+	validator := NewValidator(classifier, podBlocker, NewEnableChecker(DefaultEnableLabel, nil), ValidationModeDeny)
+
+	// without group members the group cannot be restored.
+	_, err = validator.ValidateCreate(context.Background(), pod.DeepCopy())
+	require.Error(t, err)
+
+	require.NoError(t, setGroupMembers(&pod.ObjectMeta, group.Members))
+	warnings, err := validator.ValidateCreate(context.Background(), &pod)
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+}
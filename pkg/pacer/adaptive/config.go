@@ -0,0 +1,20 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package adaptive
+
+import "time"
+
+type Config struct {
+	// Initial number of pods allowed to be starting at once.
+	InitialWindow int
+	// Minimum number of pods allowed to be starting at once.
+	MinWindow int
+	// Maximum number of pods allowed to be starting at once.
+	MaxWindow int
+	// Startup latency under which the window is widened.
+	TargetLatency time.Duration
+	// Number of pods added to the window for each pod started within target.
+	Increase float64
+	// Multiplier applied to the window when startup latency exceeds target.
+	DecreaseFactor float64
+}
@@ -0,0 +1,146 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package adaptive
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"straggler/pkg/pacer/types"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+)
+
+var (
+	_ types.Pacer = &pacer{}
+)
+
+// Adaptive pacer limits the number of starting pods to a window that is
+// adjusted based on observed startup latency, similar to AIMD congestion
+// control. The window grows additively for each pod that becomes ready
+// within target latency, and shrinks multiplicatively once per pacing
+// decision when a pod exceeds it.
+// Startup latency is measured from pod scheduling, or creation if not known,
+// until it becomes ready. Failing pods are considered slow.
+type pacer struct {
+	sync.Mutex
+
+	key    string
+	config Config
+	clock  clock.PassiveClock
+
+	window float64
+	// pods whose startup latency was already accounted for.
+	observed map[apitypes.UID]bool
+	// pods found on first pacing decision are not accounted for.
+	seeded bool
+}
+
+func New(key string, config Config, clock clock.PassiveClock) *pacer {
+	return &pacer{
+		key:      key,
+		config:   config,
+		clock:    clock,
+		window:   float64(config.InitialWindow),
+		observed: make(map[apitypes.UID]bool),
+	}
+}
+
+func (p *pacer) Pace(podClassifications types.PodClassification, logger logr.Logger) ([]corev1.Pod, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	now := p.clock.Now()
+	observed := make(map[apitypes.UID]bool)
+	fast, slow := 0, 0
+	for _, pod := range podClassifications.Ready {
+		startedAt, readyAt := startTime(&pod), readyTime(&pod)
+		if startedAt.IsZero() || readyAt.IsZero() {
+			continue
+		}
+		observed[pod.UID] = true
+		if p.observed[pod.UID] {
+			continue
+		}
+		if readyAt.Sub(startedAt) <= p.config.TargetLatency {
+			fast++
+		} else {
+			slow++
+		}
+	}
+	// starting pods that already exceeded target signal degradation early.
+	for _, pod := range podClassifications.Starting {
+		startedAt := startTime(&pod)
+		if startedAt.IsZero() || now.Sub(startedAt) <= p.config.TargetLatency {
+			continue
+		}
+		observed[pod.UID] = true
+		if !p.observed[pod.UID] {
+			slow++
+		}
+	}
+	// failing pods signal degradation regardless of their latency.
+	for _, pod := range podClassifications.Failed {
+		observed[pod.UID] = true
+		if !p.observed[pod.UID] {
+			slow++
+		}
+	}
+	// forget pods that are gone.
+	p.observed = observed
+
+	switch {
+	case !p.seeded:
+		p.seeded = true
+	case slow > 0:
+		p.window = math.Max(float64(p.config.MinWindow), p.window*p.config.DecreaseFactor)
+	default:
+		p.window = math.Min(float64(p.config.MaxWindow), p.window+float64(fast)*p.config.Increase)
+	}
+
+	// failing pods hold back pacing as if they were starting.
+	startingCount := len(podClassifications.Starting) + len(podClassifications.Failing())
+	blockedCount := len(podClassifications.Blocked)
+	allowedCount := min(max(0, int(p.window)-startingCount), blockedCount)
+	allowPods := podClassifications.Blocked[:allowedCount]
+
+	logger.Info("pacing decision",
+		"ready", len(podClassifications.Ready),
+		"starting", startingCount,
+		"blocked", blockedCount,
+		"admitted", len(allowPods),
+		"window", p.window,
+		"fast", fast,
+		"slow", slow,
+	)
+	return allowPods, nil
+}
+
+func (p *pacer) ID() string {
+	return fmt.Sprintf("%T[%s]", p, p.key)
+}
+
+func startTime(pod *corev1.Pod) time.Time {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled &&
+			condition.Status == corev1.ConditionTrue &&
+			!condition.LastTransitionTime.IsZero() {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	return pod.CreationTimestamp.Time
+}
+
+func readyTime(pod *corev1.Pod) time.Time {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,207 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package adaptive
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"straggler/pkg/pacer/types"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func newPod(name string, created time.Time) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			UID:               apitypes.UID(name),
+			CreationTimestamp: metav1.NewTime(created),
+		},
+	}
+}
+
+func newReadyPod(name string, created time.Time, latency time.Duration) corev1.Pod {
+	pod := newPod(name, created)
+	pod.Status.Conditions = []corev1.PodCondition{
+		{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(created.Add(latency))},
+	}
+	return pod
+}
+
+func newBlockedPods(count int) []corev1.Pod {
+	pods := make([]corev1.Pod, 0, count)
+	for i := 0; i < count; i++ {
+		pods = append(pods, newPod(fmt.Sprintf("blocked%d", i), time.Time{}))
+	}
+	return pods
+}
+
+func TestAdaptivePacer(t *testing.T) {
+	start := time.Now()
+	clock := clocktesting.NewFakeClock(start)
+	pacer := New(
+		"key",
+		Config{
+			InitialWindow:  2,
+			MinWindow:      1,
+			MaxWindow:      8,
+			TargetLatency:  time.Minute,
+			Increase:       1,
+			DecreaseFactor: 0.5,
+		},
+		clock)
+
+	// initial window
+	allowed, err := pacer.Pace(types.PodClassification{
+		Blocked: newBlockedPods(20),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 2)
+
+	// both started within target, window grows to 4
+	clock.Step(30 * time.Second)
+	ready := []corev1.Pod{
+		newReadyPod("a", start, 20*time.Second),
+		newReadyPod("b", start, 30*time.Second),
+	}
+	allowed, err = pacer.Pace(types.PodClassification{
+		Ready:   ready,
+		Blocked: newBlockedPods(18),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 4)
+
+	// same pods are not accounted for twice, starting pods count against window
+	allowed, err = pacer.Pace(types.PodClassification{
+		Ready:    ready,
+		Starting: []corev1.Pod{newPod("c", clock.Now())},
+		Blocked:  newBlockedPods(18),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 3)
+
+	// a slow pod halves the window to 2
+	clock.Step(2 * time.Minute)
+	ready = append(ready, newReadyPod("c", start.Add(30*time.Second), 2*time.Minute))
+	allowed, err = pacer.Pace(types.PodClassification{
+		Ready:   ready,
+		Blocked: newBlockedPods(18),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 2)
+
+	// a starting pod exceeding target shrinks the window down to min once
+	starting := []corev1.Pod{newPod("d", clock.Now())}
+	clock.Step(2 * time.Minute)
+	for i := 0; i < 2; i++ {
+		allowed, err = pacer.Pace(types.PodClassification{
+			Ready:    ready,
+			Starting: starting,
+			Blocked:  newBlockedPods(18),
+		}, logr.Discard())
+		require.NoError(t, err)
+		require.Len(t, allowed, 0)
+	}
+	allowed, err = pacer.Pace(types.PodClassification{
+		Ready:   ready,
+		Blocked: newBlockedPods(18),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 1)
+}
+
+func TestAdaptivePacerSeed(t *testing.T) {
+	start := time.Now()
+	clock := clocktesting.NewFakeClock(start)
+	pacer := New(
+		"key",
+		Config{
+			InitialWindow:  2,
+			MinWindow:      1,
+			MaxWindow:      100,
+			TargetLatency:  time.Minute,
+			Increase:       1,
+			DecreaseFactor: 0.5,
+		},
+		clock)
+
+	// pods that were ready before pacer was created do not change the window.
+	ready := make([]corev1.Pod, 0)
+	for i := 0; i < 10; i++ {
+		ready = append(ready, newReadyPod(fmt.Sprintf("ready%d", i), start.Add(-time.Hour), time.Second))
+	}
+	allowed, err := pacer.Pace(types.PodClassification{
+		Ready:   ready,
+		Blocked: newBlockedPods(20),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 2)
+
+	// startup latency is measured from scheduling when known.
+	pod := newReadyPod("gated", start.Add(-time.Hour), time.Hour+10*time.Second)
+	pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{
+		Type:               corev1.PodScheduled,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(start),
+	})
+	allowed, err = pacer.Pace(types.PodClassification{
+		Ready:   append(ready, pod),
+		Blocked: newBlockedPods(20),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 3)
+}
+
+func TestAdaptivePacerFailed(t *testing.T) {
+	start := time.Now()
+	clock := clocktesting.NewFakeClock(start)
+	pacer := New(
+		"key",
+		Config{
+			InitialWindow:  4,
+			MinWindow:      1,
+			MaxWindow:      8,
+			TargetLatency:  time.Minute,
+			Increase:       1,
+			DecreaseFactor: 0.5,
+		},
+		clock)
+
+	allowed, err := pacer.Pace(types.PodClassification{
+		Blocked: newBlockedPods(20),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 4)
+
+	// a terminal failed pod halves the window once but does not count as starting
+	failed := newPod("failed", start)
+	failed.Status.Phase = corev1.PodFailed
+	for i := 0; i < 2; i++ {
+		allowed, err = pacer.Pace(types.PodClassification{
+			Blocked: newBlockedPods(20),
+			Failed:  []corev1.Pod{failed},
+		}, logr.Discard())
+		require.NoError(t, err)
+		require.Len(t, allowed, 2)
+	}
+
+	// a crash looping pod still counts as starting
+	crashLooping := newPod("crashlooping", start)
+	crashLooping.Status.Phase = corev1.PodRunning
+	crashLooping.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+	}
+	allowed, err = pacer.Pace(types.PodClassification{
+		Blocked: newBlockedPods(20),
+		Failed:  []corev1.Pod{failed, crashLooping},
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 0)
+}
@@ -0,0 +1,22 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package breaker
+
+import "time"
+
+var (
+	// Annotation set on any pod in a group to resume a paused group. Each
+	// new value resumes it once.
+	DefaultResumeAnnotation = "v1.straggler.technicianted/resume"
+)
+
+type Config struct {
+	// Number of failing pods at which the group is paused. Zero to disable.
+	FailureThreshold int
+	// Ratio of failing to released pods at which the group is paused. Zero
+	// to disable.
+	FailureRatio float64
+	// Duration after which a paused group is resumed. Zero to resume only
+	// manually.
+	Cooldown time.Duration
+}
@@ -0,0 +1,172 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package breaker
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"straggler/pkg/pacer/types"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+)
+
+var (
+	_ types.TimedPacer = &pacer{}
+)
+
+// Breaker pacer is a circuit breaker that pauses an inner pacer, allowing
+// no pods, once too many released pods are failing. It is resumed after a
+// cooldown or by annotating a pod in the group. Pods that were failing when
+// resumed no longer count as failures.
+type pacer struct {
+	sync.Mutex
+
+	key    string
+	config Config
+	inner  types.Pacer
+	clock  clock.PassiveClock
+
+	paused   bool
+	pausedAt time.Time
+	// failing pods acknowledged by resuming.
+	acknowledged map[apitypes.UID]bool
+	// resume annotation values already seen.
+	resumeValues map[string]bool
+	seeded       bool
+}
+
+func New(key string, config Config, inner types.Pacer, clock clock.PassiveClock) *pacer {
+	return &pacer{
+		key:          key,
+		config:       config,
+		inner:        inner,
+		clock:        clock,
+		acknowledged: make(map[apitypes.UID]bool),
+		resumeValues: make(map[string]bool),
+	}
+}
+
+func (p *pacer) Pace(podClassifications types.PodClassification, logger logr.Logger) ([]corev1.Pod, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	now := p.clock.Now()
+	resumeRequested := p.checkResumeLocked(podClassifications)
+	if p.paused {
+		switch {
+		case resumeRequested:
+			logger.Info("resuming paused group by annotation", "key", p.key)
+			p.resumeLocked(podClassifications.Failed)
+		case p.config.Cooldown > 0 && now.Sub(p.pausedAt) >= p.config.Cooldown:
+			logger.Info("resuming paused group after cooldown", "key", p.key, "cooldown", p.config.Cooldown)
+			p.resumeLocked(podClassifications.Failed)
+		}
+	}
+
+	failures := 0
+	acknowledged := make(map[apitypes.UID]bool)
+	for _, pod := range podClassifications.Failed {
+		if p.acknowledged[pod.UID] {
+			acknowledged[pod.UID] = true
+			continue
+		}
+		failures++
+	}
+	// forget pods that are no longer failing.
+	p.acknowledged = acknowledged
+	released := len(podClassifications.Ready) + len(podClassifications.Starting) + failures
+
+	if !p.paused && p.shouldPause(failures, released) {
+		logger.Info("pausing group due to failing pods", "key", p.key, "failures", failures, "released", released)
+		p.paused = true
+		p.pausedAt = now
+	}
+	if p.paused {
+		logger.Info("pacing decision",
+			"ready", len(podClassifications.Ready),
+			"starting", len(podClassifications.Starting),
+			"blocked", len(podClassifications.Blocked),
+			"failed", len(podClassifications.Failed),
+			"admitted", 0,
+			"paused", true,
+		)
+		return nil, nil
+	}
+
+	return p.inner.Pace(podClassifications, logger)
+}
+
+// NextPace returns the remaining cooldown of a paused group, otherwise
+// defers to the inner pacer.
+func (p *pacer) NextPace() time.Duration {
+	p.Lock()
+	paused, pausedAt := p.paused, p.pausedAt
+	p.Unlock()
+
+	if paused {
+		if p.config.Cooldown <= 0 {
+			return 0
+		}
+		return max(time.Nanosecond, p.config.Cooldown-p.clock.Since(pausedAt))
+	}
+	if timed, ok := p.inner.(types.TimedPacer); ok {
+		return timed.NextPace()
+	}
+	return 0
+}
+
+func (p *pacer) ID() string {
+	return fmt.Sprintf("%T[%s]:%s", p, p.key, p.inner.ID())
+}
+
+func (p *pacer) shouldPause(failures, released int) bool {
+	if failures == 0 {
+		return false
+	}
+	if p.config.FailureThreshold > 0 && failures >= p.config.FailureThreshold {
+		return true
+	}
+	if p.config.FailureRatio > 0 && released > 0 &&
+		float64(failures)/float64(released) >= p.config.FailureRatio {
+		return true
+	}
+	return false
+}
+
+func (p *pacer) resumeLocked(failed []corev1.Pod) {
+	p.paused = false
+	for _, pod := range failed {
+		p.acknowledged[pod.UID] = true
+	}
+}
+
+// Check for new resume annotation values. Values found on first pacing
+// decision are considered old.
+func (p *pacer) checkResumeLocked(podClassifications types.PodClassification) bool {
+	found := false
+	for _, pods := range [][]corev1.Pod{
+		podClassifications.Ready,
+		podClassifications.Starting,
+		podClassifications.Blocked,
+		podClassifications.Failed,
+	} {
+		for _, pod := range pods {
+			value, ok := pod.Annotations[DefaultResumeAnnotation]
+			if !ok || p.resumeValues[value] {
+				continue
+			}
+			p.resumeValues[value] = true
+			if p.seeded {
+				found = true
+			}
+		}
+	}
+	p.seeded = true
+
+	return found
+}
@@ -0,0 +1,145 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package breaker
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"straggler/pkg/pacer/concurrency"
+	"straggler/pkg/pacer/types"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func newPods(prefix string, count int) []corev1.Pod {
+	pods := make([]corev1.Pod, 0, count)
+	for i := 0; i < count; i++ {
+		pods = append(pods, corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				UID: apitypes.UID(fmt.Sprintf("%s%d", prefix, i)),
+			},
+		})
+	}
+	return pods
+}
+
+func newTestPacer(config Config) (*pacer, *clocktesting.FakeClock) {
+	clock := clocktesting.NewFakeClock(time.Now())
+	return New("key", config, concurrency.New("key", concurrency.Config{MaxStarting: 10}), clock), clock
+}
+
+func TestBreakerThreshold(t *testing.T) {
+	pacer, clock := newTestPacer(Config{
+		FailureThreshold: 2,
+		Cooldown:         time.Minute,
+	})
+
+	// one failure is tolerated
+	allowed, err := pacer.Pace(types.PodClassification{
+		Starting: newPods("starting", 3),
+		Failed:   newPods("failed", 1),
+		Blocked:  newPods("blocked", 10),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 6)
+	require.Equal(t, time.Duration(0), pacer.NextPace())
+
+	// second failure pauses the group
+	allowed, err = pacer.Pace(types.PodClassification{
+		Starting: newPods("starting", 2),
+		Failed:   newPods("failed", 2),
+		Blocked:  newPods("blocked", 10),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 0)
+	require.Equal(t, time.Minute, pacer.NextPace())
+
+	// still paused even if pods recover
+	clock.Step(30 * time.Second)
+	allowed, err = pacer.Pace(types.PodClassification{
+		Ready:   newPods("ready", 4),
+		Blocked: newPods("blocked", 10),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 0)
+	require.Equal(t, 30*time.Second, pacer.NextPace())
+
+	// resumed after cooldown, existing failures are acknowledged
+	clock.Step(30 * time.Second)
+	allowed, err = pacer.Pace(types.PodClassification{
+		Failed:  newPods("failed", 2),
+		Blocked: newPods("blocked", 10),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 8)
+
+	// new failures pause it again
+	allowed, err = pacer.Pace(types.PodClassification{
+		Failed:  append(newPods("failed", 2), newPods("new", 2)...),
+		Blocked: newPods("blocked", 10),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 0)
+}
+
+func TestBreakerRatio(t *testing.T) {
+	pacer, _ := newTestPacer(Config{
+		FailureRatio: 0.5,
+	})
+
+	allowed, err := pacer.Pace(types.PodClassification{
+		Ready:   newPods("ready", 3),
+		Failed:  newPods("failed", 2),
+		Blocked: newPods("blocked", 10),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 8)
+
+	allowed, err = pacer.Pace(types.PodClassification{
+		Ready:   newPods("ready", 2),
+		Failed:  newPods("failed", 2),
+		Blocked: newPods("blocked", 10),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 0)
+	// no cooldown
+	require.Equal(t, time.Duration(0), pacer.NextPace())
+}
+
+func TestBreakerManualResume(t *testing.T) {
+	pacer, _ := newTestPacer(Config{
+		FailureThreshold: 1,
+	})
+
+	blocked := newPods("blocked", 10)
+	blocked[0].Annotations = map[string]string{DefaultResumeAnnotation: "old"}
+	allowed, err := pacer.Pace(types.PodClassification{
+		Failed:  newPods("failed", 1),
+		Blocked: blocked,
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 0)
+
+	// values seen before do not resume
+	allowed, err = pacer.Pace(types.PodClassification{
+		Failed:  newPods("failed", 1),
+		Blocked: blocked,
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 0)
+
+	blocked[1].Annotations = map[string]string{DefaultResumeAnnotation: "1"}
+	allowed, err = pacer.Pace(types.PodClassification{
+		Failed:  newPods("failed", 1),
+		Blocked: blocked,
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 9)
+}
@@ -6,25 +6,30 @@ import (
 	"fmt"
 	"straggler/pkg/pacer/types"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 )
 
 var (
-	_ types.Pacer = &composite{}
+	_ types.TimedPacer = &composite{}
 )
 
 type composite struct {
 	id     string
 	pacers []types.Pacer
+	// whether pacing metrics are recorded with id as the group.
+	record bool
 }
 
-// Create a composite pacer that allow pods allowed by all pacers.
+// Create a composite pacer that allow pods allowed by all pacers. Pacing
+// metrics are recorded for the group id.
 func NewComposite(id string, pacers []types.Pacer) types.Pacer {
 	return &composite{
 		id:     id,
 		pacers: pacers,
+		record: true,
 	}
 }
 
@@ -46,10 +51,30 @@ func (p *composite) Pace(podClassifications types.PodClassification, logger logr
 			allowPods = append(allowPods, pod)
 		}
 	}
+	if p.record {
+		recordPace(p.id, podClassifications, len(allowPods))
+	}
 
 	return
 }
 
+// NextPace returns the shortest duration after which any of the timed
+// pacers may allow more pods.
+func (p *composite) NextPace() time.Duration {
+	var next time.Duration
+	for _, inner := range p.pacers {
+		timed, ok := inner.(types.TimedPacer)
+		if !ok {
+			continue
+		}
+		if d := timed.NextPace(); d > 0 && (next == 0 || d < next) {
+			next = d
+		}
+	}
+
+	return next
+}
+
 func (p *composite) ID() string {
 	s := fmt.Sprintf("composite(%s)[%d]:", p.id, len(p.pacers))
 	inners := make([]string, 0)
@@ -58,3 +83,31 @@ func (p *composite) ID() string {
 	}
 	return s + strings.Join(inners, ",")
 }
+
+var (
+	_ types.PacerFactory = &compositeFactory{}
+)
+
+type compositeFactory struct {
+	factories []types.PacerFactory
+}
+
+// Create a factory of composite pacers made of pacers created by factories.
+func NewCompositeFactory(factories []types.PacerFactory) types.PacerFactory {
+	return &compositeFactory{
+		factories: factories,
+	}
+}
+
+func (f *compositeFactory) New(key string) types.Pacer {
+	pacers := make([]types.Pacer, 0, len(f.factories))
+	for _, factory := range f.factories {
+		pacers = append(pacers, factory.New(key))
+	}
+	// pacers are created per policy grouping key and are part of a group
+	// composite that records metrics for the group.
+	return &composite{
+		id:     key,
+		pacers: pacers,
+	}
+}
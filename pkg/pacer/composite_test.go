@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/go-logr/zapr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
@@ -57,3 +58,73 @@ func TestCompositePacerSimple(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, allowedPods, 0)
 }
+
+func TestCompositePacerMetrics(t *testing.T) {
+	zlog, _ := zap.NewDevelopment()
+	logger := zapr.NewLogger(zlog)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	blockedPods := []corev1.Pod{
+		{ObjectMeta: v1.ObjectMeta{UID: "uid0"}},
+		{ObjectMeta: v1.ObjectMeta{UID: "uid1"}},
+	}
+	pacer1 := mocks.NewMockPacer(mockCtrl)
+	pacer1.EXPECT().Pace(gomock.Any(), gomock.Any()).Return([]corev1.Pod{blockedPods[0]}, nil)
+
+	composite := NewComposite(t.Name(), []types.Pacer{pacer1})
+	_, err := composite.Pace(types.PodClassification{
+		Ready:   []corev1.Pod{{}},
+		Blocked: blockedPods,
+	}, logger)
+	require.NoError(t, err)
+	require.Equal(t, float64(1), testutil.ToFloat64(groupPods.WithLabelValues(t.Name(), "ready")))
+	require.Equal(t, float64(0), testutil.ToFloat64(groupPods.WithLabelValues(t.Name(), "starting")))
+	require.Equal(t, float64(2), testutil.ToFloat64(groupPods.WithLabelValues(t.Name(), "blocked")))
+	require.Equal(t, float64(1), testutil.ToFloat64(groupAllowedPods.WithLabelValues(t.Name())))
+
+	count := testutil.CollectAndCount(groupAllowedPods)
+	DeleteGroupMetrics(t.Name())
+	require.Equal(t, count-1, testutil.CollectAndCount(groupAllowedPods))
+}
+
+func TestCompositePacerFactory(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pacer1 := mocks.NewMockPacer(mockCtrl)
+	pacer1.EXPECT().ID().Return("pacer1")
+	factory1 := mocks.NewMockPacerFactory(mockCtrl)
+	factory1.EXPECT().New("key").Return(pacer1)
+	pacer2 := mocks.NewMockPacer(mockCtrl)
+	pacer2.EXPECT().ID().Return("pacer2")
+	factory2 := mocks.NewMockPacerFactory(mockCtrl)
+	factory2.EXPECT().New("key").Return(pacer2)
+
+	factory := NewCompositeFactory([]types.PacerFactory{factory1, factory2})
+	composite := factory.New("key")
+	require.Equal(t, "composite(key)[2]:pacer1,pacer2", composite.ID())
+}
+
+func TestCompositePacerFactoryNoMetrics(t *testing.T) {
+	zlog, _ := zap.NewDevelopment()
+	logger := zapr.NewLogger(zlog)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pacer1 := mocks.NewMockPacer(mockCtrl)
+	pacer1.EXPECT().Pace(gomock.Any(), gomock.Any()).Return(nil, nil)
+	factory1 := mocks.NewMockPacerFactory(mockCtrl)
+	factory1.EXPECT().New(t.Name()).Return(pacer1)
+
+	// composites created per grouping key are not groups.
+	count := testutil.CollectAndCount(groupPods)
+	composite := NewCompositeFactory([]types.PacerFactory{factory1}).New(t.Name())
+	_, err := composite.Pace(types.PodClassification{
+		Blocked: []corev1.Pod{{ObjectMeta: v1.ObjectMeta{UID: "uid0"}}},
+	}, logger)
+	require.NoError(t, err)
+	require.Equal(t, count, testutil.CollectAndCount(groupPods))
+}
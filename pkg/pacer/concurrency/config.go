@@ -0,0 +1,8 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package concurrency
+
+type Config struct {
+	// Maximum number of pods starting at any time.
+	MaxStarting int
+}
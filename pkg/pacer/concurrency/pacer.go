@@ -0,0 +1,52 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package concurrency
+
+import (
+	"fmt"
+	"straggler/pkg/pacer/types"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	_ types.Pacer = &pacer{}
+)
+
+// Concurrency pacer allows at most a fixed number of pods to be starting at
+// any time. A pod is allowed as soon as a starting one becomes ready.
+// For example with 3: 3 starting => 1 ready, 2 starting => 3 starting => ...
+type pacer struct {
+	key    string
+	config Config
+}
+
+func New(key string, config Config) *pacer {
+	return &pacer{
+		key:    key,
+		config: config,
+	}
+}
+
+func (p *pacer) Pace(podClassifications types.PodClassification, logger logr.Logger) ([]corev1.Pod, error) {
+	// failing pods hold back pacing as if they were starting.
+	startingCount := len(podClassifications.Starting) + len(podClassifications.Failing())
+	blockedCount := len(podClassifications.Blocked)
+
+	allowedCount := min(max(0, p.config.MaxStarting-startingCount), blockedCount)
+
+	allowPods := podClassifications.Blocked[:allowedCount]
+
+	logger.Info("pacing decision",
+		"ready", len(podClassifications.Ready),
+		"starting", startingCount,
+		"blocked", blockedCount,
+		"admitted", len(allowPods),
+	)
+	return allowPods, nil
+}
+
+func (p *pacer) ID() string {
+	return fmt.Sprintf("%T[%s]", p, p.key)
+}
@@ -0,0 +1,104 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package concurrency
+
+import (
+	"straggler/pkg/pacer/types"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestConcurrencyPacerAllow(t *testing.T) {
+	pacer := New(
+		"key",
+		Config{
+			MaxStarting: 3,
+		})
+
+	// initial case, should allow max starting
+	allowed, err := pacer.Pace(types.PodClassification{
+		Blocked: []corev1.Pod{{}, {}, {}, {}, {}},
+	},
+		logr.Discard(),
+	)
+	require.NoError(t, err)
+	require.Len(t, allowed, 3)
+
+	// window is full, readiness does not matter
+	allowed, err = pacer.Pace(types.PodClassification{
+		Ready:    []corev1.Pod{{}, {}, {}, {}, {}, {}, {}, {}},
+		Starting: []corev1.Pod{{}, {}, {}},
+		Blocked:  []corev1.Pod{{}, {}},
+	},
+		logr.Discard(),
+	)
+	require.NoError(t, err)
+	require.Len(t, allowed, 0)
+
+	// one turned ready
+	allowed, err = pacer.Pace(types.PodClassification{
+		Ready:    []corev1.Pod{{}},
+		Starting: []corev1.Pod{{}, {}},
+		Blocked:  []corev1.Pod{{}, {}},
+	},
+		logr.Discard(),
+	)
+	require.NoError(t, err)
+	require.Len(t, allowed, 1)
+
+	// less blocked than window
+	allowed, err = pacer.Pace(types.PodClassification{
+		Blocked: []corev1.Pod{{}},
+	},
+		logr.Discard(),
+	)
+	require.NoError(t, err)
+	require.Len(t, allowed, 1)
+}
+
+func TestConcurrencyPacerFailed(t *testing.T) {
+	pacer := New(
+		"key",
+		Config{
+			MaxStarting: 3,
+		})
+
+	crashLooping := corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+			},
+		},
+	}
+	terminated := corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+		},
+	}
+
+	// failing pods hold back pacing
+	allowed, err := pacer.Pace(types.PodClassification{
+		Starting: []corev1.Pod{{}},
+		Blocked:  []corev1.Pod{{}, {}, {}},
+		Failed:   []corev1.Pod{crashLooping},
+	},
+		logr.Discard(),
+	)
+	require.NoError(t, err)
+	require.Len(t, allowed, 1)
+
+	// terminal failed pods will not start, they do not hold back pacing
+	allowed, err = pacer.Pace(types.PodClassification{
+		Starting: []corev1.Pod{{}},
+		Blocked:  []corev1.Pod{{}, {}, {}},
+		Failed:   []corev1.Pod{terminated, terminated},
+	},
+		logr.Discard(),
+	)
+	require.NoError(t, err)
+	require.Len(t, allowed, 2)
+}
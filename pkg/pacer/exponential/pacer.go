@@ -5,7 +5,6 @@ package exponential
 import (
 	"fmt"
 	"math"
-	"sort"
 	"straggler/pkg/pacer/types"
 
 	"github.com/go-logr/logr"
@@ -37,17 +36,13 @@ func (p *pacer) Pace(podClassifications types.PodClassification, logger logr.Log
 	}
 
 	readyCount := len(podClassifications.Ready)
-	startingCount := len(podClassifications.Starting)
+	// failing pods hold back pacing as if they were starting.
+	startingCount := len(podClassifications.Starting) + len(podClassifications.Failing())
 	blockedCount := len(podClassifications.Blocked)
 
 	allowedCount := calculateAllowedCount(readyCount, startingCount, blockedCount, p.config.MinInitial, p.config.Multiplier)
 
-	// Sort the not admitted pods by creation timestamp in ascending order (earlier pods first)
-	sort.Slice(podClassifications.Blocked, func(i, j int) bool {
-		return podClassifications.Blocked[i].CreationTimestamp.Time.Before(podClassifications.Blocked[j].CreationTimestamp.Time)
-	})
-
-	// Slice the sorted pending pods to allow the determined number of pods
+	// Blocked pods are ordered by the ordering stage, allow from the head.
 	allowPods := podClassifications.Blocked[:allowedCount]
 	totalAdmittedAfterPacing := readyCount + startingCount + len(allowPods)
 
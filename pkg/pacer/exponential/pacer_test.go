@@ -5,14 +5,12 @@ package exponential
 import (
 	"fmt"
 	"testing"
-	"time"
 
 	"straggler/pkg/pacer/types"
 
 	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Helper function to generate dummy pods with unique names.
@@ -24,56 +22,6 @@ func generatePods(count int, prefix string) []v1.Pod {
 	return pods
 }
 
-func TestPace_SortsByCreationTimestamp(t *testing.T) {
-	config := Config{
-		MinInitial: 1,
-		Multiplier: 2,
-		MaxStagger: 100,
-	}
-
-	p := New("test-key", config)
-
-	// Create pods with different creation timestamps
-	now := metav1.Now()
-	earlier := metav1.NewTime(now.Add(-10 * time.Minute))
-	later := metav1.NewTime(now.Add(10 * time.Minute))
-
-	notAdmittedPods := []v1.Pod{
-		{
-			ObjectMeta: metav1.ObjectMeta{Name: "pod-later", CreationTimestamp: later},
-		},
-		{
-			ObjectMeta: metav1.ObjectMeta{Name: "pod-earlier", CreationTimestamp: earlier},
-		},
-		{
-			ObjectMeta: metav1.ObjectMeta{Name: "pod-now", CreationTimestamp: now},
-		},
-	}
-
-	podClassifications := types.PodClassification{
-		Ready:    []v1.Pod{},      // No admitted pods initially
-		Starting: []v1.Pod{},      // No admitted, not ready pods
-		Blocked:  notAdmittedPods, // 3 pending pods with different timestamps
-	}
-
-	// Invoke Pace method
-	allowedPods, err := p.Pace(podClassifications, logr.Discard())
-	if err != nil {
-		t.Fatalf("Pace returned unexpected error: %v", err)
-	}
-
-	// We expect only the first pod (with the earliest creation timestamp) to be admitted
-	expectedPodName := "pod-earlier"
-
-	if len(allowedPods) != 1 {
-		t.Errorf("Expected 1 pod to be admitted, but got %d", len(allowedPods))
-	}
-
-	if allowedPods[0].ObjectMeta.Name != expectedPodName {
-		t.Errorf("Expected admitted pod to be %s, but got %s", expectedPodName, allowedPods[0].ObjectMeta.Name)
-	}
-}
-
 func TestPace(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -41,7 +41,8 @@ func (p *pacer) Pace(podClassifications types.PodClassification, logger logr.Log
 	}
 
 	readyCount := len(podClassifications.Ready)
-	startingCount := len(podClassifications.Starting)
+	// failing pods hold back pacing as if they were starting.
+	startingCount := len(podClassifications.Starting) + len(podClassifications.Failing())
 	blockedCount := len(podClassifications.Blocked)
 
 	remainder := readyCount % p.config.Step
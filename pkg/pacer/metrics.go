@@ -0,0 +1,45 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package pacer
+
+import (
+	"straggler/pkg/metrics"
+	"straggler/pkg/pacer/types"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	groupPods = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "pacer",
+			Name:      "group_pods",
+			Help:      "number of pods in a staggering group by state as of last pacing decision",
+		},
+		[]string{"group", "state"})
+	groupAllowedPods = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "pacer",
+			Name:      "group_allowed_pods",
+			Help:      "number of blocked pods allowed to start by last pacing decision of a staggering group",
+		},
+		[]string{"group"})
+)
+
+func recordPace(group string, podClassifications types.PodClassification, allowed int) {
+	groupPods.WithLabelValues(group, "ready").Set(float64(len(podClassifications.Ready)))
+	groupPods.WithLabelValues(group, "starting").Set(float64(len(podClassifications.Starting)))
+	groupPods.WithLabelValues(group, "blocked").Set(float64(len(podClassifications.Blocked)))
+	groupPods.WithLabelValues(group, "failed").Set(float64(len(podClassifications.Failed)))
+	groupAllowedPods.WithLabelValues(group).Set(float64(allowed))
+}
+
+// Delete all recorded metrics of a staggering group. It should be called
+// once a group is no longer in use.
+func DeleteGroupMetrics(group string) {
+	groupPods.DeletePartialMatch(prometheus.Labels{"group": group})
+	groupAllowedPods.DeleteLabelValues(group)
+}
@@ -12,6 +12,7 @@ package mocks
 import (
 	reflect "reflect"
 	types "straggler/pkg/pacer/types"
+	time "time"
 
 	logr "github.com/go-logr/logr"
 	gomock "go.uber.org/mock/gomock"
@@ -70,6 +71,72 @@ func (mr *MockPacerMockRecorder) Pace(podClassifications, logger any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pace", reflect.TypeOf((*MockPacer)(nil).Pace), podClassifications, logger)
 }
 
+// MockTimedPacer is a mock of TimedPacer interface.
+type MockTimedPacer struct {
+	ctrl     *gomock.Controller
+	recorder *MockTimedPacerMockRecorder
+}
+
+// MockTimedPacerMockRecorder is the mock recorder for MockTimedPacer.
+type MockTimedPacerMockRecorder struct {
+	mock *MockTimedPacer
+}
+
+// NewMockTimedPacer creates a new mock instance.
+func NewMockTimedPacer(ctrl *gomock.Controller) *MockTimedPacer {
+	mock := &MockTimedPacer{ctrl: ctrl}
+	mock.recorder = &MockTimedPacerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTimedPacer) EXPECT() *MockTimedPacerMockRecorder {
+	return m.recorder
+}
+
+// ID mocks base method.
+func (m *MockTimedPacer) ID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ID indicates an expected call of ID.
+func (mr *MockTimedPacerMockRecorder) ID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ID", reflect.TypeOf((*MockTimedPacer)(nil).ID))
+}
+
+// NextPace mocks base method.
+func (m *MockTimedPacer) NextPace() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NextPace")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// NextPace indicates an expected call of NextPace.
+func (mr *MockTimedPacerMockRecorder) NextPace() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextPace", reflect.TypeOf((*MockTimedPacer)(nil).NextPace))
+}
+
+// Pace mocks base method.
+func (m *MockTimedPacer) Pace(podClassifications types.PodClassification, logger logr.Logger) ([]v1.Pod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pace", podClassifications, logger)
+	ret0, _ := ret[0].([]v1.Pod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pace indicates an expected call of Pace.
+func (mr *MockTimedPacerMockRecorder) Pace(podClassifications, logger any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pace", reflect.TypeOf((*MockTimedPacer)(nil).Pace), podClassifications, logger)
+}
+
 // MockPacerFactory is a mock of PacerFactory interface.
 type MockPacerFactory struct {
 	ctrl     *gomock.Controller
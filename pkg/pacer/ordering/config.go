@@ -0,0 +1,77 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package ordering
+
+const (
+	// Earlier pods first.
+	FIFO = "fifo"
+	// Higher priority pods first, then earlier pods.
+	Priority = "priority"
+)
+
+const (
+	// Take pods from each fairness key in turns.
+	RoundRobin = "round-robin"
+	// Take pods from the fairness key with the lowest weighted share of
+	// ready and starting pods.
+	DRF = "drf"
+
+	// Share by pod namespace.
+	ByNamespace = "namespace"
+	// Share by pod owner controller.
+	ByOwner = "owner"
+)
+
+var (
+	// Annotation that overrides pod priority when ordering by priority.
+	DefaultPriorityAnnotation = "v1.straggler.technicianted/priority"
+)
+
+type Config struct {
+	// Ordering strategy. Default FIFO.
+	Strategy string
+	// Weights added to pod priorities by namespace.
+	NamespaceWeights map[string]int32
+	// Share released pods across namespaces or owners. Default none.
+	Fairness *FairnessConfig
+	// Release pods of StatefulSets at or above their rolling update
+	// partition first. Pods of the same StatefulSet are always released in
+	// ordinal order.
+	RespectStatefulSetPartition bool
+}
+
+type FairnessConfig struct {
+	// Fairness mode.
+	Mode string
+	// Fairness key. Default ByNamespace.
+	By string
+	// Weights of fairness keys for DRF. Default 1.
+	Weights map[string]int32
+}
+
+// Check if strategy is a known ordering strategy.
+func IsKnown(strategy string) bool {
+	switch strategy {
+	case FIFO, Priority:
+		return true
+	}
+	return false
+}
+
+// Check if mode is a known fairness mode.
+func IsKnownFairnessMode(mode string) bool {
+	switch mode {
+	case RoundRobin, DRF:
+		return true
+	}
+	return false
+}
+
+// Check if by is a known fairness key.
+func IsKnownFairnessKey(by string) bool {
+	switch by {
+	case ByNamespace, ByOwner:
+		return true
+	}
+	return false
+}
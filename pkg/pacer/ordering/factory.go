@@ -0,0 +1,23 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package ordering
+
+import "straggler/pkg/pacer/types"
+
+var _ types.PacerFactory = &factory{}
+
+type factory struct {
+	config Config
+	inner  types.PacerFactory
+}
+
+func NewFactory(config Config, inner types.PacerFactory) *factory {
+	return &factory{
+		config: config,
+		inner:  inner,
+	}
+}
+
+func (f *factory) New(key string) types.Pacer {
+	return New(key, f.config, f.inner.New(key))
+}
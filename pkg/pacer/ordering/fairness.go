@@ -0,0 +1,87 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package ordering
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Interleave ordered blocked pods across fairness keys such that any number
+// of pods taken from the head is shared fairly. Order of pods with the same
+// key is retained. Admitted pods are the ready and starting pods used as
+// current usage for DRF.
+func Fair(blocked []corev1.Pod, admitted []corev1.Pod, config FairnessConfig) []corev1.Pod {
+	keys := make([]string, 0)
+	queues := make(map[string][]corev1.Pod)
+	for _, pod := range blocked {
+		key := fairnessKey(&pod, config.By)
+		if _, ok := queues[key]; !ok {
+			keys = append(keys, key)
+		}
+		queues[key] = append(queues[key], pod)
+	}
+	if len(keys) < 2 {
+		return blocked
+	}
+
+	usage := make(map[string]int)
+	if config.Mode == DRF {
+		for _, pod := range admitted {
+			usage[fairnessKey(&pod, config.By)]++
+		}
+	}
+
+	fair := make([]corev1.Pod, 0, len(blocked))
+	for len(fair) < len(blocked) {
+		if config.Mode == DRF {
+			key := lowestShare(keys, queues, usage, config.Weights)
+			fair = append(fair, queues[key][0])
+			queues[key] = queues[key][1:]
+			usage[key]++
+			continue
+		}
+		for _, key := range keys {
+			if len(queues[key]) > 0 {
+				fair = append(fair, queues[key][0])
+				queues[key] = queues[key][1:]
+			}
+		}
+	}
+
+	return fair
+}
+
+// Get the key with pending pods with the lowest weighted usage. Ties go to
+// the key that appears first.
+func lowestShare(keys []string, queues map[string][]corev1.Pod, usage map[string]int, weights map[string]int32) string {
+	lowest := ""
+	lowestShare := 0.0
+	found := false
+	for _, key := range keys {
+		if len(queues[key]) == 0 {
+			continue
+		}
+		weight := weights[key]
+		if weight <= 0 {
+			weight = 1
+		}
+		share := float64(usage[key]) / float64(weight)
+		if !found || share < lowestShare {
+			lowest = key
+			lowestShare = share
+			found = true
+		}
+	}
+	return lowest
+}
+
+func fairnessKey(pod *corev1.Pod, by string) string {
+	if by != ByOwner {
+		return pod.Namespace
+	}
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		return pod.Namespace + "/" + owner.Kind + "/" + owner.Name
+	}
+	return pod.Namespace + "/"
+}
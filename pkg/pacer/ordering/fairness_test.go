@@ -0,0 +1,95 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package ordering
+
+import (
+	"fmt"
+	"testing"
+
+	"straggler/pkg/pacer/linear"
+	"straggler/pkg/pacer/types"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func newNamespacePods(namespace string, count int) []corev1.Pod {
+	pods := make([]corev1.Pod, 0, count)
+	for i := 0; i < count; i++ {
+		pods = append(pods, corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%d", namespace, i),
+				Namespace: namespace,
+			},
+		})
+	}
+	return pods
+}
+
+func TestFairRoundRobin(t *testing.T) {
+	blocked := append(newNamespacePods("a", 4), newNamespacePods("b", 2)...)
+	blocked = append(blocked, newNamespacePods("c", 1)...)
+
+	fair := Fair(blocked, nil, FairnessConfig{Mode: RoundRobin, By: ByNamespace})
+	require.Equal(t,
+		[]string{"a-0", "b-0", "c-0", "a-1", "b-1", "a-2", "a-3"},
+		podNames(fair))
+}
+
+func TestFairDRF(t *testing.T) {
+	blocked := append(newNamespacePods("a", 4), newNamespacePods("b", 4)...)
+	// a already has 2 pods admitted
+	admitted := newNamespacePods("a", 2)
+
+	fair := Fair(blocked, admitted, FairnessConfig{Mode: DRF, By: ByNamespace})
+	require.Equal(t,
+		[]string{"b-0", "b-1", "a-0", "b-2", "a-1", "b-3", "a-2", "a-3"},
+		podNames(fair))
+
+	// b has double the share of a
+	fair = Fair(blocked, nil, FairnessConfig{Mode: DRF, By: ByNamespace, Weights: map[string]int32{"b": 2}})
+	require.Equal(t,
+		[]string{"a-0", "b-0", "b-1", "a-1", "b-2", "b-3", "a-2", "a-3"},
+		podNames(fair))
+
+	// empty keys are shared like any other
+	blocked = append(newNamespacePods("", 2), newNamespacePods("b", 2)...)
+	fair = Fair(blocked, nil, FairnessConfig{Mode: DRF, By: ByNamespace})
+	require.Equal(t,
+		[]string{"-0", "b-0", "-1", "b-1"},
+		podNames(fair))
+}
+
+func TestFairByOwner(t *testing.T) {
+	blocked := newNamespacePods("a", 4)
+	for i := range blocked {
+		blocked[i].OwnerReferences = []metav1.OwnerReference{
+			{Kind: "ReplicaSet", Name: fmt.Sprintf("rs%d", i/3), Controller: ptr.To(true)},
+		}
+	}
+
+	fair := Fair(blocked, nil, FairnessConfig{Mode: RoundRobin, By: ByOwner})
+	require.Equal(t,
+		[]string{"a-0", "a-3", "a-1", "a-2"},
+		podNames(fair))
+	// single namespace is left as is
+	require.Equal(t,
+		podNames(blocked),
+		podNames(Fair(blocked, nil, FairnessConfig{Mode: RoundRobin, By: ByNamespace})))
+}
+
+func TestPaceFairness(t *testing.T) {
+	p := New(
+		"key",
+		Config{Fairness: &FairnessConfig{Mode: RoundRobin, By: ByNamespace}},
+		linear.New("key", linear.Config{MaxStagger: 100, Step: 4}))
+
+	// one tenant does not starve another
+	blocked := append(newNamespacePods("big", 500), newNamespacePods("small", 5)...)
+	allowed, err := p.Pace(types.PodClassification{Blocked: blocked}, logr.Discard())
+	require.NoError(t, err)
+	require.Equal(t, []string{"big-0", "small-0", "big-1", "small-1"}, podNames(allowed))
+}
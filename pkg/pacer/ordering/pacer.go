@@ -0,0 +1,104 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package ordering
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"straggler/pkg/pacer/types"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	_ types.TimedPacer = &pacer{}
+)
+
+// Ordering pacer sorts blocked pods, and optionally shares them fairly, before
+// passing them to an inner pacer such that pods allowed by it are taken from
+// the head of the list.
+type pacer struct {
+	key    string
+	config Config
+	inner  types.Pacer
+}
+
+func New(key string, config Config, inner types.Pacer) *pacer {
+	return &pacer{
+		key:    key,
+		config: config,
+		inner:  inner,
+	}
+}
+
+func (p *pacer) Pace(podClassifications types.PodClassification, logger logr.Logger) ([]corev1.Pod, error) {
+	podClassifications.Blocked = Sort(podClassifications.Blocked, p.config)
+	if p.config.Fairness != nil {
+		admitted := append(append(append([]corev1.Pod{}, podClassifications.Ready...), podClassifications.Starting...), podClassifications.Failed...)
+		podClassifications.Blocked = Fair(podClassifications.Blocked, admitted, *p.config.Fairness)
+	}
+	return p.inner.Pace(podClassifications, logger)
+}
+
+func (p *pacer) NextPace() time.Duration {
+	if timed, ok := p.inner.(types.TimedPacer); ok {
+		return timed.NextPace()
+	}
+	return 0
+}
+
+func (p *pacer) ID() string {
+	return fmt.Sprintf("%T[%s]:%s", p, p.key, p.inner.ID())
+}
+
+// Sort a copy of pods according to config.
+func Sort(pods []corev1.Pod, config Config) []corev1.Pod {
+	sorted := make([]corev1.Pod, len(pods))
+	copy(sorted, pods)
+
+	byPriority := config.Strategy == Priority
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if byPriority {
+			pi, pj := podPriority(&sorted[i], config), podPriority(&sorted[j], config)
+			if pi != pj {
+				return pi > pj
+			}
+		}
+		return createdBefore(&sorted[i], &sorted[j])
+	})
+	orderStatefulSets(sorted, config.RespectStatefulSetPartition)
+
+	return sorted
+}
+
+// Get pod priority from its annotation, or spec priority otherwise, plus
+// its namespace weight.
+func podPriority(pod *corev1.Pod, config Config) int64 {
+	priority := int64(0)
+	if value, ok := pod.Annotations[DefaultPriorityAnnotation]; ok {
+		if p, err := strconv.ParseInt(value, 10, 32); err == nil {
+			priority = p
+		}
+	} else if pod.Spec.Priority != nil {
+		priority = int64(*pod.Spec.Priority)
+	}
+
+	return priority + int64(config.NamespaceWeights[pod.Namespace])
+}
+
+// Pods without a creation timestamp are pods in admission, which are
+// created after all others and are considered latest so that they do not
+// jump ahead of pods already waiting.
+func createdBefore(a, b *corev1.Pod) bool {
+	switch {
+	case a.CreationTimestamp.IsZero():
+		return false
+	case b.CreationTimestamp.IsZero():
+		return true
+	}
+	return a.CreationTimestamp.Before(&b.CreationTimestamp)
+}
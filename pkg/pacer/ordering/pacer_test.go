@@ -0,0 +1,113 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package ordering
+
+import (
+	"testing"
+	"time"
+
+	"straggler/pkg/pacer/exponential"
+	"straggler/pkg/pacer/types"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func podNames(pods []corev1.Pod) []string {
+	names := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+func TestPaceSortsByCreationTimestamp(t *testing.T) {
+	// pods are ordered by creation time by default.
+	p := NewFactory(
+		Config{},
+		exponential.NewFactory(exponential.Config{
+			MinInitial: 1,
+			Multiplier: 2,
+			MaxStagger: 100,
+		})).New("test-key")
+
+	now := metav1.Now()
+	blocked := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-later", CreationTimestamp: metav1.NewTime(now.Add(10 * time.Minute))}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-earlier", CreationTimestamp: metav1.NewTime(now.Add(-10 * time.Minute))}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-now", CreationTimestamp: now}},
+	}
+
+	allowed, err := p.Pace(types.PodClassification{Blocked: blocked}, logr.Discard())
+	require.NoError(t, err)
+	require.Equal(t, []string{"pod-earlier"}, podNames(allowed))
+	// input is not modified
+	require.Equal(t, "pod-later", blocked[0].Name)
+}
+
+func TestSortFIFO(t *testing.T) {
+	now := time.Now()
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "newer", CreationTimestamp: metav1.NewTime(now)}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "older", CreationTimestamp: metav1.NewTime(now.Add(-time.Minute))}},
+		// pods in admission do not have a creation timestamp yet and
+		// are created last.
+		{ObjectMeta: metav1.ObjectMeta{Name: "admission"}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:              "priority",
+			CreationTimestamp: metav1.NewTime(now),
+			Annotations:       map[string]string{DefaultPriorityAnnotation: "100"},
+		}},
+	}
+
+	require.Equal(t,
+		[]string{"older", "newer", "priority", "admission"},
+		podNames(Sort(pods, Config{Strategy: FIFO})))
+	// default is fifo
+	require.Equal(t,
+		[]string{"older", "newer", "priority", "admission"},
+		podNames(Sort(pods, Config{})))
+}
+
+func TestSortPriority(t *testing.T) {
+	now := time.Now()
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "batch", CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "critical", Namespace: "batch", CreationTimestamp: metav1.NewTime(now)},
+			Spec:       corev1.PodSpec{Priority: ptr.To[int32](1000)},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "annotated",
+				Namespace:         "batch",
+				CreationTimestamp: metav1.NewTime(now),
+				// annotation overrides spec priority
+				Annotations: map[string]string{DefaultPriorityAnnotation: "2000"},
+			},
+			Spec: corev1.PodSpec{Priority: ptr.To[int32](0)},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "invalid",
+				Namespace:         "batch",
+				CreationTimestamp: metav1.NewTime(now),
+				Annotations:       map[string]string{DefaultPriorityAnnotation: "high"},
+			},
+		},
+		{ObjectMeta: metav1.ObjectMeta{Name: "online", Namespace: "online", CreationTimestamp: metav1.NewTime(now)}},
+	}
+
+	require.Equal(t,
+		[]string{"annotated", "critical", "batch", "invalid", "online"},
+		podNames(Sort(pods, Config{Strategy: Priority})))
+	require.Equal(t,
+		[]string{"annotated", "online", "critical", "batch", "invalid"},
+		podNames(Sort(pods, Config{
+			Strategy:         Priority,
+			NamespaceWeights: map[string]int32{"online": 1500},
+		})))
+}
@@ -0,0 +1,119 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package ordering
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	// Annotation with the rolling update partition of the StatefulSet of a
+	// pod at its admission. It is only set on pods created by an ongoing
+	// rolling update.
+	DefaultStatefulSetPartitionAnnotation = "v1.straggler.technicianted/statefulSetPartition"
+)
+
+type statefulSetPod struct {
+	ordinal  int
+	updating bool
+}
+
+// Reorder pods owned by the same StatefulSet by their ordinals. Pods of a
+// StatefulSet keep the positions they were sorted into such that ordering
+// against other pods is retained. If respectPartition is set, pods of a
+// rolling update at or above its partition are taken first in descending
+// order similar to the StatefulSet controller, then the rest.
+func orderStatefulSets(pods []corev1.Pod, respectPartition bool) {
+	keys := make([]string, 0)
+	positions := make(map[string][]int)
+	for i := range pods {
+		key, ok := statefulSetKey(&pods[i])
+		if !ok {
+			continue
+		}
+		if _, ok := positions[key]; !ok {
+			keys = append(keys, key)
+		}
+		positions[key] = append(positions[key], i)
+	}
+
+	for _, key := range keys {
+		if len(positions[key]) < 2 {
+			continue
+		}
+		setPods := make([]corev1.Pod, 0, len(positions[key]))
+		for _, i := range positions[key] {
+			setPods = append(setPods, pods[i])
+		}
+		sort.SliceStable(setPods, func(i, j int) bool {
+			a, b := newStatefulSetPod(&setPods[i]), newStatefulSetPod(&setPods[j])
+			if respectPartition {
+				if a.updating != b.updating {
+					return a.updating
+				}
+				if a.updating {
+					return a.ordinal > b.ordinal
+				}
+			}
+			return a.ordinal < b.ordinal
+		})
+		for n, i := range positions[key] {
+			pods[i] = setPods[n]
+		}
+	}
+}
+
+// Get a key of the StatefulSet that owns pod if any, and pod ordinal is
+// known.
+func statefulSetKey(pod *corev1.Pod) (string, bool) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "StatefulSet" {
+		return "", false
+	}
+	if _, ok := podOrdinal(pod, owner.Name); !ok {
+		return "", false
+	}
+
+	return pod.Namespace + "/" + owner.Name, true
+}
+
+func newStatefulSetPod(pod *corev1.Pod) statefulSetPod {
+	owner := metav1.GetControllerOf(pod)
+	ordinal, _ := podOrdinal(pod, owner.Name)
+	// pods without a partition are not part of a rolling update.
+	updating := false
+	if value, ok := pod.Annotations[DefaultStatefulSetPartitionAnnotation]; ok {
+		if partition, err := strconv.Atoi(value); err == nil {
+			updating = ordinal >= partition
+		}
+	}
+
+	return statefulSetPod{
+		ordinal:  ordinal,
+		updating: updating,
+	}
+}
+
+// Get ordinal of a StatefulSet pod from its index label, or from its name
+// otherwise.
+func podOrdinal(pod *corev1.Pod, statefulSetName string) (int, bool) {
+	value, ok := pod.Labels[appsv1.PodIndexLabel]
+	if !ok {
+		value, ok = strings.CutPrefix(pod.Name, statefulSetName+"-")
+		if !ok {
+			return 0, false
+		}
+	}
+	ordinal, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return ordinal, true
+}
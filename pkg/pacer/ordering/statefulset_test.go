@@ -0,0 +1,75 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package ordering
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func newTestStatefulSetPod(statefulSet string, name string, created time.Time) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "StatefulSet", Name: statefulSet, Controller: ptr.To(true)},
+			},
+		},
+	}
+}
+
+func TestSortStatefulSetOrdinals(t *testing.T) {
+	now := time.Now()
+	indexed := newTestStatefulSetPod("web", "indexed", now.Add(-4*time.Second))
+	indexed.Labels = map[string]string{appsv1.PodIndexLabel: "1"}
+	pods := []corev1.Pod{
+		newTestStatefulSetPod("web", "web-37", now.Add(-5*time.Second)),
+		{ObjectMeta: metav1.ObjectMeta{Name: "other", CreationTimestamp: metav1.NewTime(now.Add(-3 * time.Second))}},
+		newTestStatefulSetPod("web", "web-2", now.Add(-2*time.Second)),
+		newTestStatefulSetPod("db", "db-1", now.Add(-time.Second)),
+		newTestStatefulSetPod("db", "db-0", now),
+		indexed,
+	}
+
+	// pods of the same statefulset take the positions of the set in order
+	// of their ordinals.
+	sorted := Sort(pods, Config{Strategy: FIFO})
+	require.Equal(t, []string{"indexed", "web-2", "other", "web-37", "db-0", "db-1"}, podNames(sorted))
+}
+
+func TestSortStatefulSetPartition(t *testing.T) {
+	now := time.Now()
+	pods := []corev1.Pod{}
+	for i, name := range []string{"web-0", "web-1", "web-2", "web-3", "web-4"} {
+		pod := newTestStatefulSetPod("web", name, now.Add(time.Duration(i)*time.Second))
+		pod.Annotations = map[string]string{DefaultStatefulSetPartitionAnnotation: "2"}
+		pods = append(pods, pod)
+	}
+
+	sorted := Sort(pods, Config{Strategy: FIFO})
+	require.Equal(t, []string{"web-0", "web-1", "web-2", "web-3", "web-4"}, podNames(sorted))
+
+	// pods at or above the partition are taken first in descending order
+	sorted = Sort(pods, Config{Strategy: FIFO, RespectStatefulSetPartition: true})
+	require.Equal(t, []string{"web-4", "web-3", "web-2", "web-0", "web-1"}, podNames(sorted))
+}
+
+func TestSortStatefulSetScaleUp(t *testing.T) {
+	now := time.Now()
+	pods := []corev1.Pod{
+		newTestStatefulSetPod("web", "web-37", now.Add(-time.Second)),
+		newTestStatefulSetPod("web", "web-2", now),
+	}
+
+	// pods that are not part of a rolling update, such as on scale up with
+	// the default partition 0, are taken in ascending order.
+	sorted := Sort(pods, Config{Strategy: FIFO, RespectStatefulSetPartition: true})
+	require.Equal(t, []string{"web-2", "web-37"}, podNames(sorted))
+}
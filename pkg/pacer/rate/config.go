@@ -0,0 +1,14 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package rate
+
+import "time"
+
+type Config struct {
+	// Number of pods allowed to start per period.
+	Rate int
+	// Period over which rate applies.
+	Period time.Duration
+	// Maximum number of pods allowed to start at once.
+	Burst int
+}
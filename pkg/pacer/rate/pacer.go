@@ -0,0 +1,194 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package rate
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"straggler/pkg/pacer/types"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+)
+
+var (
+	_ types.TimedPacer = &pacer{}
+)
+
+var (
+	// Time during which a pod allowed in admission holds its token. Pods in
+	// admission do not have UIDs yet, so their tokens are returned after this
+	// duration unless they are seen released, such as when other pacers in a
+	// group blocked them.
+	DefaultAdmissionGrantDuration = 30 * time.Second
+)
+
+// Rate pacer is a token bucket pacer that allows pods to start at a fixed
+// rate regardless of their readiness.
+// For example: 10 pods per minute with bursts of up to 5 pods.
+// Blocked pods that are allowed keep their token until they are released,
+// since other pacers in a group may deny them. Tokens of pods deleted before
+// they are released are returned.
+type pacer struct {
+	sync.Mutex
+
+	key    string
+	config Config
+	clock  clock.PassiveClock
+
+	tokens     float64
+	lastRefill time.Time
+	// blocked pods that were allowed.
+	grants map[apitypes.UID]struct{}
+	// times at which pods were allowed in admission.
+	admissionGrants []time.Time
+	// released pods seen by the pacer.
+	released map[apitypes.UID]struct{}
+	// whether released pods were seen. Pods released before the pacer was
+	// created, such as before a restart, do not spend tokens.
+	initialized bool
+}
+
+func New(key string, config Config, clock clock.PassiveClock) *pacer {
+	return &pacer{
+		key:        key,
+		config:     config,
+		clock:      clock,
+		tokens:     float64(config.Burst),
+		lastRefill: clock.Now(),
+		grants:     make(map[apitypes.UID]struct{}),
+		released:   make(map[apitypes.UID]struct{}),
+	}
+}
+
+func (p *pacer) Pace(podClassifications types.PodClassification, logger logr.Logger) (allowPods []corev1.Pod, err error) {
+	p.Lock()
+	defer p.Unlock()
+
+	now := p.clock.Now()
+	p.refillLocked(now)
+	p.releaseGrantsLocked(podClassifications, now)
+
+	for _, pod := range podClassifications.Blocked {
+		if _, ok := p.grants[pod.UID]; ok && len(pod.UID) > 0 {
+			allowPods = append(allowPods, pod)
+			continue
+		}
+		if p.tokens < 1 {
+			continue
+		}
+		p.tokens--
+		// pods in admission do not have UIDs yet.
+		if len(pod.UID) > 0 {
+			p.grants[pod.UID] = struct{}{}
+		} else {
+			p.admissionGrants = append(p.admissionGrants, now)
+		}
+		allowPods = append(allowPods, pod)
+	}
+
+	logger.Info("pacing decision",
+		"ready", len(podClassifications.Ready),
+		"starting", len(podClassifications.Starting),
+		"blocked", len(podClassifications.Blocked),
+		"admitted", len(allowPods),
+		"tokens", p.tokens,
+		"grants", len(p.grants)+len(p.admissionGrants),
+	)
+	return allowPods, nil
+}
+
+// NextPace returns the duration until the next token is available.
+func (p *pacer) NextPace() time.Duration {
+	p.Lock()
+	defer p.Unlock()
+
+	p.refillLocked(p.clock.Now())
+	if p.tokens >= 1 || p.config.Rate <= 0 {
+		return 0
+	}
+
+	return time.Duration((1 - p.tokens) * float64(p.config.Period) / float64(p.config.Rate))
+}
+
+func (p *pacer) ID() string {
+	return fmt.Sprintf("%T[%s]", p, p.key)
+}
+
+func (p *pacer) refillLocked(now time.Time) {
+	elapsed := now.Sub(p.lastRefill)
+	p.lastRefill = now
+	if elapsed <= 0 || p.config.Period <= 0 {
+		return
+	}
+
+	p.tokens += elapsed.Seconds() * float64(p.config.Rate) / p.config.Period.Seconds()
+	if p.tokens > float64(p.config.Burst) {
+		p.tokens = float64(p.config.Burst)
+	}
+}
+
+// Remove grants of pods released since the last pacing, and return tokens of
+// granted pods that were deleted while blocked or admission grants that
+// expired.
+func (p *pacer) releaseGrantsLocked(podClassifications types.PodClassification, now time.Time) {
+	seen := make(map[apitypes.UID]struct{})
+	for _, pods := range [][]corev1.Pod{
+		podClassifications.Ready,
+		podClassifications.Starting,
+		podClassifications.Failed,
+	} {
+		for _, pod := range pods {
+			if len(pod.UID) == 0 {
+				continue
+			}
+			seen[pod.UID] = struct{}{}
+			if _, ok := p.released[pod.UID]; ok {
+				continue
+			}
+			p.released[pod.UID] = struct{}{}
+			if !p.initialized {
+				continue
+			}
+			switch _, ok := p.grants[pod.UID]; {
+			case ok:
+				delete(p.grants, pod.UID)
+			case len(p.admissionGrants) > 0:
+				// most likely allowed in admission.
+				p.admissionGrants = p.admissionGrants[1:]
+			default:
+				// released after its admission grant expired.
+				p.tokens--
+			}
+		}
+	}
+	p.initialized = true
+	for uid := range p.released {
+		if _, ok := seen[uid]; !ok {
+			delete(p.released, uid)
+		}
+	}
+
+	blocked := make(map[apitypes.UID]struct{}, len(podClassifications.Blocked))
+	for _, pod := range podClassifications.Blocked {
+		blocked[pod.UID] = struct{}{}
+	}
+	for uid := range p.grants {
+		if _, ok := blocked[uid]; !ok {
+			delete(p.grants, uid)
+			p.returnTokenLocked()
+		}
+	}
+	for len(p.admissionGrants) > 0 && now.Sub(p.admissionGrants[0]) > DefaultAdmissionGrantDuration {
+		p.admissionGrants = p.admissionGrants[1:]
+		p.returnTokenLocked()
+	}
+}
+
+func (p *pacer) returnTokenLocked() {
+	p.tokens = min(p.tokens+1, float64(p.config.Burst))
+}
@@ -0,0 +1,238 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package rate
+
+import (
+	"fmt"
+	pacerpkg "straggler/pkg/pacer"
+	"straggler/pkg/pacer/exponential"
+	"straggler/pkg/pacer/types"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func newPods(count int) []corev1.Pod {
+	pods := make([]corev1.Pod, 0, count)
+	for i := 0; i < count; i++ {
+		pods = append(pods, corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				UID: apitypes.UID(fmt.Sprintf("uid%d", i)),
+			},
+		})
+	}
+	return pods
+}
+
+func TestRatePacerAllow(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Now())
+	pacer := New(
+		"key",
+		Config{
+			Rate:   6,
+			Period: time.Minute,
+			Burst:  2,
+		},
+		clock)
+
+	// initial burst
+	allowed, err := pacer.Pace(types.PodClassification{
+		Blocked: newPods(5),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 2)
+	require.Equal(t, 10*time.Second, pacer.NextPace())
+
+	// no tokens, readiness does not matter
+	allowed, err = pacer.Pace(types.PodClassification{
+		Ready:   newPods(2),
+		Blocked: newPods(5)[2:],
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 0)
+
+	// one token after 10s
+	clock.Step(10 * time.Second)
+	require.Equal(t, time.Duration(0), pacer.NextPace())
+	allowed, err = pacer.Pace(types.PodClassification{
+		Ready:   newPods(2),
+		Blocked: newPods(5)[2:],
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 1)
+	require.Equal(t, "uid2", string(allowed[0].UID))
+
+	// tokens are capped by burst
+	clock.Step(time.Hour)
+	allowed, err = pacer.Pace(types.PodClassification{
+		Ready:   newPods(3),
+		Blocked: newPods(5)[3:],
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 2)
+}
+
+func TestRatePacerGrants(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Now())
+	pacer := New(
+		"key",
+		Config{
+			Rate:   1,
+			Period: time.Minute,
+			Burst:  1,
+		},
+		clock)
+
+	allowed, err := pacer.Pace(types.PodClassification{
+		Blocked: newPods(2),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 1)
+
+	// same pod paced again is allowed without a token until released
+	clock.Step(time.Hour)
+	allowed, err = pacer.Pace(types.PodClassification{
+		Blocked: newPods(2),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 2)
+	require.Equal(t, "uid0", string(allowed[0].UID))
+
+	// released pods do not return their tokens
+	allowed, err = pacer.Pace(types.PodClassification{
+		Starting: newPods(1),
+		Blocked:  newPods(3)[1:],
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 1)
+	require.Equal(t, "uid1", string(allowed[0].UID))
+
+	// deleted blocked pods return their tokens
+	allowed, err = pacer.Pace(types.PodClassification{
+		Starting: newPods(1),
+		Blocked:  newPods(3)[2:],
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 1)
+	require.Equal(t, "uid2", string(allowed[0].UID))
+}
+
+func TestRatePacerAdmissionGrants(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Now())
+	pacer := New(
+		"key",
+		Config{
+			Rate:   1,
+			Period: time.Hour,
+			Burst:  1,
+		},
+		clock)
+
+	admitted := corev1.Pod{ObjectMeta: metav1.ObjectMeta{GenerateName: "pod-"}}
+	allowed, err := pacer.Pace(types.PodClassification{
+		Blocked: []corev1.Pod{admitted},
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 1)
+
+	// pod was blocked by other pacers, its token is returned
+	clock.Step(DefaultAdmissionGrantDuration + time.Second)
+	allowed, err = pacer.Pace(types.PodClassification{
+		Blocked: newPods(1),
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 1)
+
+	// pod allowed in admission is released and keeps its token
+	pacer = New(
+		"key",
+		Config{
+			Rate:   1,
+			Period: time.Hour,
+			Burst:  1,
+		},
+		clock)
+	allowed, err = pacer.Pace(types.PodClassification{
+		Blocked: []corev1.Pod{admitted},
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 1)
+	clock.Step(DefaultAdmissionGrantDuration + time.Second)
+	allowed, err = pacer.Pace(types.PodClassification{
+		Starting: newPods(1),
+		Blocked:  newPods(2)[1:],
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 0)
+}
+
+func TestRatePacerWithExponential(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Now())
+	composite := pacerpkg.NewComposite(t.Name(), []types.Pacer{
+		New(
+			"key",
+			Config{
+				Rate:   1,
+				Period: 10 * time.Minute,
+				Burst:  1,
+			},
+			clock),
+		exponential.New(
+			"key",
+			exponential.Config{
+				MinInitial: 1,
+				MaxStagger: 100,
+				Multiplier: 2,
+			}),
+	})
+	pods := newPods(5)
+
+	allowed, err := composite.Pace(types.PodClassification{
+		Blocked: pods,
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Equal(t, pods[:1], allowed)
+
+	// rate allows the next pod but exponential waits for the first one
+	clock.Step(10 * time.Minute)
+	allowed, err = composite.Pace(types.PodClassification{
+		Starting: pods[:1],
+		Blocked:  pods[1:],
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Empty(t, allowed)
+
+	// the token is kept until exponential allows the pod
+	clock.Step(2 * time.Minute)
+	allowed, err = composite.Pace(types.PodClassification{
+		Ready:   pods[:1],
+		Blocked: pods[1:],
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Equal(t, pods[1:2], allowed)
+
+	clock.Step(2 * time.Minute)
+	allowed, err = composite.Pace(types.PodClassification{
+		Ready:    pods[:1],
+		Starting: pods[1:2],
+		Blocked:  pods[2:],
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Empty(t, allowed)
+
+	// next token is available one period after the second pod was allowed
+	clock.Step(6 * time.Minute)
+	allowed, err = composite.Pace(types.PodClassification{
+		Ready:    pods[:1],
+		Starting: pods[1:2],
+		Blocked:  pods[2:],
+	}, logr.Discard())
+	require.NoError(t, err)
+	require.Equal(t, pods[2:3], allowed)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"straggler/pkg/pacer/types"
+)
+
+type Window struct {
+	// Days of week this window applies to. Empty for all days.
+	Days []time.Weekday
+	// Start time of day in minutes.
+	Start int
+	// End time of day in minutes. If not after start then the window ends
+	// on the next day.
+	End int
+	// Factory of pacers used during this window.
+	PacerFactory types.PacerFactory
+}
+
+type Config struct {
+	// Location of window times.
+	Location *time.Location
+	// Time windows, first matching window is used.
+	Windows []Window
+	// Factory of pacers used outside of all windows. If nil all pods are
+	// allowed.
+	DefaultPacerFactory types.PacerFactory
+}
+
+var weekdays = map[string]time.Weekday{}
+
+func init() {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		weekdays[strings.ToLower(day.String())] = day
+		weekdays[strings.ToLower(day.String()[:3])] = day
+	}
+}
+
+// Parse a day of week name such as Mon or Monday.
+func ParseWeekday(s string) (time.Weekday, error) {
+	day, ok := weekdays[strings.ToLower(s)]
+	if !ok {
+		return 0, fmt.Errorf("unknown day of week: %s", s)
+	}
+	return day, nil
+}
+
+// Parse a time of day in HH:MM format into minutes.
+func ParseTimeOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %s: expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
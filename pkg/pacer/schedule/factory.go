@@ -0,0 +1,27 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package schedule
+
+import (
+	"straggler/pkg/pacer/types"
+
+	"k8s.io/utils/clock"
+)
+
+var _ types.PacerFactory = &factory{}
+
+type factory struct {
+	config Config
+	clock  clock.PassiveClock
+}
+
+func NewFactory(config Config, clock clock.PassiveClock) *factory {
+	return &factory{
+		config: config,
+		clock:  clock,
+	}
+}
+
+func (f *factory) New(key string) types.Pacer {
+	return New(key, f.config, f.clock)
+}
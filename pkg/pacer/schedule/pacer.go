@@ -0,0 +1,180 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"straggler/pkg/pacer/types"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/clock"
+)
+
+var (
+	_ types.TimedPacer = &pacer{}
+)
+
+// Schedule pacer delegates pacing to inner pacers selected by time windows.
+// For example: exponential pacing during business hours, otherwise allow
+// all pods.
+// Schedule pacers only gate pods. When composed with other pacers, such as
+// pacers set along with a schedule in a policy, those still apply outside
+// of windows.
+// Inner pacers are created once per window and kept such that their state
+// is retained across windows.
+type pacer struct {
+	sync.Mutex
+
+	key    string
+	config Config
+	clock  clock.PassiveClock
+
+	windowPacers  map[int]types.Pacer
+	defaultPacer  types.Pacer
+	location      *time.Location
+	lastWindowIdx int
+}
+
+func New(key string, config Config, clock clock.PassiveClock) *pacer {
+	location := config.Location
+	if location == nil {
+		location = time.UTC
+	}
+	return &pacer{
+		key:           key,
+		config:        config,
+		clock:         clock,
+		windowPacers:  make(map[int]types.Pacer),
+		location:      location,
+		lastWindowIdx: -2,
+	}
+}
+
+func (p *pacer) Pace(podClassifications types.PodClassification, logger logr.Logger) ([]corev1.Pod, error) {
+	p.Lock()
+	windowIdx := p.activeWindow(p.clock.Now())
+	if windowIdx != p.lastWindowIdx {
+		logger.Info("schedule window changed", "key", p.key, "window", windowIdx)
+		p.lastWindowIdx = windowIdx
+	}
+	inner := p.getPacerLocked(windowIdx)
+	p.Unlock()
+
+	if inner == nil {
+		logger.V(1).Info("no pacer for current schedule, admitting all pending pods")
+		return podClassifications.Blocked, nil
+	}
+
+	return inner.Pace(podClassifications, logger)
+}
+
+// NextPace returns the duration until the next window boundary or when the
+// active pacer may allow more pods, whichever is earlier. Zero if neither.
+func (p *pacer) NextPace() time.Duration {
+	p.Lock()
+	now := p.clock.Now()
+	var next time.Duration
+	if boundary := p.nextBoundary(now); !boundary.IsZero() {
+		next = boundary.Sub(now)
+	}
+	inner := p.getPacerLocked(p.activeWindow(now))
+	p.Unlock()
+
+	if timed, ok := inner.(types.TimedPacer); ok {
+		if d := timed.NextPace(); d > 0 && (next == 0 || d < next) {
+			next = d
+		}
+	}
+
+	return next
+}
+
+func (p *pacer) ID() string {
+	return fmt.Sprintf("%T[%s]", p, p.key)
+}
+
+// Get the pacer for window index or the default pacer if -1.
+func (p *pacer) getPacerLocked(windowIdx int) types.Pacer {
+	if windowIdx < 0 {
+		if p.defaultPacer == nil && p.config.DefaultPacerFactory != nil {
+			p.defaultPacer = p.config.DefaultPacerFactory.New(p.key)
+		}
+		return p.defaultPacer
+	}
+
+	inner, ok := p.windowPacers[windowIdx]
+	if !ok {
+		inner = p.config.Windows[windowIdx].PacerFactory.New(p.key)
+		p.windowPacers[windowIdx] = inner
+	}
+	return inner
+}
+
+// Get the index of the first window active at t, or -1 if none.
+func (p *pacer) activeWindow(t time.Time) int {
+	t = t.In(p.location)
+	minute := t.Hour()*60 + t.Minute()
+	yesterday := (t.Weekday() + 6) % 7
+	for i, window := range p.config.Windows {
+		if window.Start < window.End {
+			if hasDay(window.Days, t.Weekday()) && minute >= window.Start && minute < window.End {
+				return i
+			}
+			continue
+		}
+		// window ends on the next day
+		if (hasDay(window.Days, t.Weekday()) && minute >= window.Start) ||
+			(hasDay(window.Days, yesterday) && minute < window.End) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Get the earliest window start or end after t at which the active window
+// changes, or zero time if it never changes.
+func (p *pacer) nextBoundary(t time.Time) time.Time {
+	t = t.In(p.location)
+	candidates := make([]time.Time, 0)
+	// a week ahead covers all boundaries.
+	for day := 0; day <= 7; day++ {
+		for _, window := range p.config.Windows {
+			for _, minute := range []int{window.Start, window.End} {
+				boundary := time.Date(t.Year(), t.Month(), t.Day()+day, minute/60, minute%60, 0, 0, p.location)
+				if boundary.After(t) {
+					candidates = append(candidates, boundary)
+				}
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Before(candidates[j])
+	})
+
+	current := p.activeWindow(t)
+	for _, boundary := range candidates {
+		if p.activeWindow(boundary) != current {
+			return boundary
+		}
+	}
+
+	return time.Time{}
+}
+
+func hasDay(days []time.Weekday, day time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
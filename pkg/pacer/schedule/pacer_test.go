@@ -0,0 +1,134 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package schedule
+
+import (
+	"straggler/pkg/pacer/mocks"
+	"straggler/pkg/pacer/types"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestSchedulePacerWindows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	location, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	businessPacer := mocks.NewMockPacer(ctrl)
+	businessFactory := mocks.NewMockPacerFactory(ctrl)
+	// inner pacers are created once and retained.
+	businessFactory.EXPECT().New("key").Return(businessPacer).Times(1)
+	nightlyPacer := mocks.NewMockTimedPacer(ctrl)
+	nightlyFactory := mocks.NewMockPacerFactory(ctrl)
+	nightlyFactory.EXPECT().New("key").Return(nightlyPacer).Times(1)
+
+	config := Config{
+		Location: location,
+		Windows: []Window{
+			{
+				Days:         []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+				Start:        9 * 60,
+				End:          17 * 60,
+				PacerFactory: businessFactory,
+			},
+			{
+				Start:        22 * 60,
+				End:          2 * 60,
+				PacerFactory: nightlyFactory,
+			},
+		},
+	}
+	// monday 8:00
+	clock := clocktesting.NewFakeClock(time.Date(2024, 6, 3, 8, 0, 0, 0, location))
+	pacer := New("key", config, clock)
+	blocked := types.PodClassification{Blocked: make([]corev1.Pod, 3)}
+
+	// no window and no default, allow all.
+	allowed, err := pacer.Pace(blocked, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 3)
+	require.Equal(t, time.Hour, pacer.NextPace())
+
+	// business hours
+	clock.SetTime(time.Date(2024, 6, 3, 9, 0, 0, 0, location))
+	businessPacer.EXPECT().Pace(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+	allowed, err = pacer.Pace(blocked, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 0)
+	require.Equal(t, 8*time.Hour, pacer.NextPace())
+	clock.SetTime(time.Date(2024, 6, 3, 16, 59, 0, 0, location))
+	_, err = pacer.Pace(blocked, logr.Discard())
+	require.NoError(t, err)
+
+	// nightly window wraps past midnight and reports inner next pace.
+	clock.SetTime(time.Date(2024, 6, 4, 1, 0, 0, 0, location))
+	nightlyPacer.EXPECT().Pace(gomock.Any(), gomock.Any()).Return(blocked.Blocked[:1], nil)
+	allowed, err = pacer.Pace(blocked, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 1)
+	nightlyPacer.EXPECT().NextPace().Return(10 * time.Minute)
+	require.Equal(t, 10*time.Minute, pacer.NextPace())
+	nightlyPacer.EXPECT().NextPace().Return(2 * time.Hour)
+	require.Equal(t, time.Hour, pacer.NextPace())
+
+	// saturday is outside business hours.
+	clock.SetTime(time.Date(2024, 6, 8, 12, 0, 0, 0, location))
+	allowed, err = pacer.Pace(blocked, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 3)
+	require.Equal(t, 10*time.Hour, pacer.NextPace())
+}
+
+func TestSchedulePacerDefault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	defaultPacer := mocks.NewMockPacer(ctrl)
+	defaultFactory := mocks.NewMockPacerFactory(ctrl)
+	defaultFactory.EXPECT().New("key").Return(defaultPacer).Times(1)
+	windowFactory := mocks.NewMockPacerFactory(ctrl)
+
+	config := Config{
+		Windows: []Window{
+			{
+				Days:         []time.Weekday{time.Sunday},
+				Start:        0,
+				End:          60,
+				PacerFactory: windowFactory,
+			},
+		},
+		DefaultPacerFactory: defaultFactory,
+	}
+	// monday 00:00 UTC
+	clock := clocktesting.NewFakeClock(time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC))
+	pacer := New("key", config, clock)
+
+	defaultPacer.EXPECT().Pace(gomock.Any(), gomock.Any()).Return(nil, nil)
+	allowed, err := pacer.Pace(types.PodClassification{Blocked: make([]corev1.Pod, 3)}, logr.Discard())
+	require.NoError(t, err)
+	require.Len(t, allowed, 0)
+	// next sunday 00:00
+	require.Equal(t, 6*24*time.Hour, pacer.NextPace())
+}
+
+func TestParse(t *testing.T) {
+	day, err := ParseWeekday("Mon")
+	require.NoError(t, err)
+	require.Equal(t, time.Monday, day)
+	day, err = ParseWeekday("saturday")
+	require.NoError(t, err)
+	require.Equal(t, time.Saturday, day)
+	_, err = ParseWeekday("someday")
+	require.Error(t, err)
+
+	minute, err := ParseTimeOfDay("09:30")
+	require.NoError(t, err)
+	require.Equal(t, 9*60+30, minute)
+	_, err = ParseTimeOfDay("25:00")
+	require.Error(t, err)
+}
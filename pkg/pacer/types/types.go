@@ -3,6 +3,8 @@
 package types
 
 import (
+	"time"
+
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -14,6 +16,23 @@ type PodClassification struct {
 	Ready    []corev1.Pod
 	Starting []corev1.Pod
 	Blocked  []corev1.Pod
+	// Pods that were released but are failing to start, such as crash
+	// looping or failed pods. Pacers that are not failure aware count them
+	// as starting.
+	Failed []corev1.Pod
+}
+
+// Failing returns failed pods that may still start, such as crash looping
+// pods. Pods in Failed phase, like Job pods that will not be restarted, are
+// terminal and do not hold back pacing.
+func (c PodClassification) Failing() []corev1.Pod {
+	var failing []corev1.Pod
+	for _, pod := range c.Failed {
+		if pod.Status.Phase != corev1.PodFailed {
+			failing = append(failing, pod)
+		}
+	}
+	return failing
 }
 
 type Pacer interface {
@@ -23,6 +42,15 @@ type Pacer interface {
 	ID() string
 }
 
+// A pacer that may allow more pods after some time passes regardless of
+// changes to pods.
+type TimedPacer interface {
+	Pacer
+	// NextPace returns the duration after which more pods may be allowed.
+	// Zero if unknown.
+	NextPace() time.Duration
+}
+
 type PacerFactory interface {
 	New(key string) Pacer
 }
@@ -0,0 +1,446 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package simulator
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"straggler/pkg/controller"
+	controllertypes "straggler/pkg/controller/types"
+	pacertypes "straggler/pkg/pacer/types"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+var (
+	DefaultStep        = 100 * time.Millisecond
+	DefaultMaxDuration = 1 * time.Hour
+)
+
+type Options struct {
+	// Virtual clock step. Pods are reconciled once every step.
+	Step time.Duration
+	// Maximum virtual time to simulate.
+	MaxDuration time.Duration
+	// Seed for random startup durations.
+	Seed int64
+}
+
+type podState int
+
+const (
+	podPending podState = iota
+	podBlocked
+	podStarting
+	podReady
+)
+
+type simPod struct {
+	pod     corev1.Pod
+	group   string
+	arrival time.Duration
+	startup time.Duration
+	state   podState
+	readyAt time.Time
+
+	groupID            string
+	maxBlockedDuration time.Duration
+}
+
+// Simulator drives a classifier and its pacers with a synthetic workload
+// using a virtual clock. It mimics the admission controller when pods are
+// created and the reconciler at every clock step.
+type Simulator struct {
+	classifier controllertypes.PodClassifier
+	options    Options
+	clock      *clocktesting.FakeClock
+	random     *rand.Rand
+	start      time.Time
+
+	pods []*simPod
+}
+
+// Create a virtual clock at simulation start time.
+func NewClock() *clocktesting.FakeClock {
+	return clocktesting.NewFakeClock(time.Unix(0, 0).UTC())
+}
+
+// Create a new simulator for classifier. clock must be the same clock used
+// by pacers of classifier.
+func New(classifier controllertypes.PodClassifier, clock *clocktesting.FakeClock, options Options) *Simulator {
+	if options.Step <= 0 {
+		options.Step = DefaultStep
+	}
+	if options.MaxDuration <= 0 {
+		options.MaxDuration = DefaultMaxDuration
+	}
+	return &Simulator{
+		classifier: classifier,
+		options:    options,
+		clock:      clock,
+		random:     rand.New(rand.NewSource(options.Seed)),
+		start:      clock.Now(),
+	}
+}
+
+func LoadWorkload(path string) (Workload, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return Workload{}, err
+	}
+
+	return LoadWorkloadFromString(string(bytes))
+}
+
+func LoadWorkloadFromString(workloadString string) (Workload, error) {
+	var workload Workload
+	if err := yaml.Unmarshal([]byte(workloadString), &workload); err != nil {
+		return Workload{}, err
+	}
+
+	return workload, nil
+}
+
+// Run the simulation of workload until all pods are ready or maximum
+// duration is reached.
+func (s *Simulator) Run(workload Workload, logger logr.Logger) (Result, error) {
+	if err := s.createPods(workload); err != nil {
+		return Result{}, err
+	}
+
+	result := Result{
+		TimeToReadyByGroup: make(map[string]time.Duration),
+	}
+	lastSamples := make(map[string]Sample)
+	// pacers log every decision which is too verbose for every virtual step.
+	stepLogger := logger.V(1)
+	for {
+		now := s.clock.Since(s.start)
+		admitted := make(map[string]int)
+
+		s.promoteReady()
+		if err := s.admit(now, admitted, stepLogger); err != nil {
+			return Result{}, err
+		}
+		if err := s.reconcile(admitted, stepLogger); err != nil {
+			return Result{}, err
+		}
+
+		allReady := true
+		for _, sample := range s.sample(now, admitted) {
+			last, ok := lastSamples[sample.Group]
+			if !ok || sample.Admitted > 0 || !sameState(last, sample) {
+				result.Timeline = append(result.Timeline, sample)
+				lastSamples[sample.Group] = sample
+			}
+			if sample.Ready == s.groupSize(sample.Group) {
+				if _, ok := result.TimeToReadyByGroup[sample.Group]; !ok {
+					result.TimeToReadyByGroup[sample.Group] = now
+				}
+			} else {
+				allReady = false
+			}
+		}
+
+		if allReady {
+			result.TimeToReady = now
+			result.Completed = true
+			break
+		}
+		if now >= s.options.MaxDuration {
+			result.TimeToReady = now
+			break
+		}
+		s.clock.Step(s.options.Step)
+	}
+
+	return result, nil
+}
+
+func (s *Simulator) createPods(workload Workload) error {
+	for _, group := range workload.Groups {
+		if len(group.Name) == 0 {
+			return fmt.Errorf("workload group name must be set")
+		}
+		if group.Pods < 0 {
+			return fmt.Errorf("invalid number of pods for %s: %d", group.Name, group.Pods)
+		}
+		namespace := group.Namespace
+		if len(namespace) == 0 {
+			namespace = "default"
+		}
+		for i := 0; i < group.Pods; i++ {
+			startup, err := s.startupDuration(group.Startup)
+			if err != nil {
+				return fmt.Errorf("invalid startup for %s: %v", group.Name, err)
+			}
+			name := fmt.Sprintf("%s-%d", group.Name, i)
+			pod := corev1.Pod{
+				ObjectMeta: *group.Template.ObjectMeta.DeepCopy(),
+				Spec:       *group.Template.Spec.DeepCopy(),
+			}
+			pod.Name = name
+			pod.Namespace = namespace
+			pod.UID = apitypes.UID(fmt.Sprintf("%s/%s", namespace, name))
+			if pod.Labels == nil {
+				pod.Labels = make(map[string]string)
+			}
+			pod.Labels[controller.DefaultEnableLabel] = "1"
+
+			s.pods = append(s.pods, &simPod{
+				pod:     pod,
+				group:   group.Name,
+				arrival: group.Arrival.Start.Duration + time.Duration(i)*group.Arrival.Interval.Duration,
+				startup: startup,
+			})
+		}
+	}
+	// pods are created in arrival order.
+	sort.SliceStable(s.pods, func(i, j int) bool {
+		return s.pods[i].arrival < s.pods[j].arrival
+	})
+
+	return nil
+}
+
+func (s *Simulator) startupDuration(startup Startup) (time.Duration, error) {
+	var d time.Duration
+	switch startup.Distribution {
+	case "", DistributionConstant:
+		d = startup.Min.Duration
+	case DistributionUniform:
+		if startup.Max.Duration < startup.Min.Duration {
+			return 0, fmt.Errorf("max must not be less than min")
+		}
+		d = startup.Min.Duration + time.Duration(s.random.Int63n(int64(startup.Max.Duration-startup.Min.Duration)+1))
+	case DistributionNormal:
+		d = startup.Mean.Duration + time.Duration(s.random.NormFloat64()*float64(startup.StdDev.Duration))
+		if d < startup.Min.Duration {
+			d = startup.Min.Duration
+		}
+		if startup.Max.Duration > 0 && d > startup.Max.Duration {
+			d = startup.Max.Duration
+		}
+	default:
+		return 0, fmt.Errorf("unknown distribution: %s", startup.Distribution)
+	}
+
+	return max(0, d), nil
+}
+
+func (s *Simulator) promoteReady() {
+	for _, pod := range s.pods {
+		if pod.state == podStarting && !s.clock.Now().Before(pod.readyAt) {
+			pod.state = podReady
+			pod.pod.Status.Conditions = append(pod.pod.Status.Conditions, corev1.PodCondition{
+				Type:               corev1.PodReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(s.clock.Now()),
+			})
+		}
+	}
+}
+
+// Admit pods that arrived by now the same way the admission controller does.
+func (s *Simulator) admit(now time.Duration, admitted map[string]int, logger logr.Logger) error {
+	for _, pod := range s.pods {
+		if pod.state != podPending || pod.arrival > now {
+			continue
+		}
+		pod.pod.CreationTimestamp = metav1.NewTime(s.clock.Now())
+
+		group, err := s.classifier.Classify(pod.pod.ObjectMeta, pod.pod.Spec, logger)
+		if err != nil {
+			return fmt.Errorf("failed to classify pod %s: %v", pod.pod.Name, err)
+		}
+		if group == nil {
+			s.startPod(pod, admitted)
+			continue
+		}
+		pod.groupID = group.ID
+		pod.maxBlockedDuration = group.GroupPolicies.MaxBlockedDuration
+
+		classification := s.classifyPodGroup(group.ID)
+		classification.Blocked = append(classification.Blocked, pod.pod)
+		allowed, err := group.Pacer.Pace(classification, logger)
+		if err != nil {
+			return fmt.Errorf("failed to pace pod %s: %v", pod.pod.Name, err)
+		}
+		pod.state = podBlocked
+		for _, allowedPod := range allowed {
+			if allowedPod.UID == pod.pod.UID {
+				s.startPod(pod, admitted)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// Release blocked pods the same way the reconciler does.
+func (s *Simulator) reconcile(admitted map[string]int, logger logr.Logger) error {
+	groupIDs := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, pod := range s.pods {
+		if pod.state == podBlocked && !seen[pod.groupID] {
+			seen[pod.groupID] = true
+			groupIDs = append(groupIDs, pod.groupID)
+		}
+	}
+
+	for _, groupID := range groupIDs {
+		group, err := s.classifier.ClassifyByGroupID(groupID, logger)
+		if err != nil {
+			return fmt.Errorf("failed to get group %s: %v", groupID, err)
+		}
+		if group == nil {
+			return fmt.Errorf("group not found: %s", groupID)
+		}
+		allowed, err := group.Pacer.Pace(s.classifyPodGroup(groupID), logger)
+		if err != nil {
+			return fmt.Errorf("failed to pace group %s: %v", groupID, err)
+		}
+		allowedUIDs := make(map[apitypes.UID]bool)
+		for _, pod := range allowed {
+			allowedUIDs[pod.UID] = true
+		}
+		for _, pod := range s.pods {
+			if pod.state != podBlocked || pod.groupID != groupID {
+				continue
+			}
+			expired := pod.maxBlockedDuration > 0 &&
+				s.clock.Since(pod.pod.CreationTimestamp.Time) >= pod.maxBlockedDuration
+			if allowedUIDs[pod.pod.UID] || expired {
+				s.startPod(pod, admitted)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Simulator) classifyPodGroup(groupID string) (classification pacertypes.PodClassification) {
+	for _, pod := range s.pods {
+		if pod.groupID != groupID {
+			continue
+		}
+		switch pod.state {
+		case podBlocked:
+			classification.Blocked = append(classification.Blocked, pod.pod)
+		case podStarting:
+			classification.Starting = append(classification.Starting, pod.pod)
+		case podReady:
+			classification.Ready = append(classification.Ready, pod.pod)
+		}
+	}
+
+	return
+}
+
+func (s *Simulator) startPod(pod *simPod, admitted map[string]int) {
+	pod.state = podStarting
+	pod.readyAt = s.clock.Now().Add(pod.startup)
+	pod.pod.Status.Conditions = []corev1.PodCondition{
+		{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(s.clock.Now())},
+	}
+	admitted[pod.group]++
+}
+
+func (s *Simulator) sample(now time.Duration, admitted map[string]int) []Sample {
+	samplesByGroup := make(map[string]*Sample)
+	groups := make([]string, 0)
+	for _, pod := range s.pods {
+		sample, ok := samplesByGroup[pod.group]
+		if !ok {
+			sample = &Sample{
+				Time:     now,
+				Group:    pod.group,
+				Admitted: admitted[pod.group],
+			}
+			samplesByGroup[pod.group] = sample
+			groups = append(groups, pod.group)
+		}
+		switch pod.state {
+		case podBlocked:
+			sample.Blocked++
+		case podStarting:
+			sample.Starting++
+		case podReady:
+			sample.Ready++
+		}
+		if pod.state != podPending {
+			sample.Created++
+		}
+	}
+
+	sort.Strings(groups)
+	samples := make([]Sample, 0, len(groups))
+	for _, group := range groups {
+		samples = append(samples, *samplesByGroup[group])
+	}
+	return samples
+}
+
+func (s *Simulator) groupSize(group string) (size int) {
+	for _, pod := range s.pods {
+		if pod.group == group {
+			size++
+		}
+	}
+	return
+}
+
+func sameState(a, b Sample) bool {
+	return a.Created == b.Created &&
+		a.Blocked == b.Blocked &&
+		a.Starting == b.Starting &&
+		a.Ready == b.Ready
+}
+
+// Print the timeline and summary of a simulation result.
+func (r Result) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tGROUP\tCREATED\tADMITTED\tBLOCKED\tSTARTING\tREADY")
+	for _, sample := range r.Timeline {
+		fmt.Fprintf(tw, "%v\t%s\t%d\t%d\t%d\t%d\t%d\n",
+			sample.Time,
+			sample.Group,
+			sample.Created,
+			sample.Admitted,
+			sample.Blocked,
+			sample.Starting,
+			sample.Ready)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	groups := make([]string, 0, len(r.TimeToReadyByGroup))
+	for group := range r.TimeToReadyByGroup {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		fmt.Fprintf(w, "group %s fully ready after %v\n", group, r.TimeToReadyByGroup[group])
+	}
+	if r.Completed {
+		fmt.Fprintf(w, "all pods ready after %v\n", r.TimeToReady)
+	} else {
+		fmt.Fprintf(w, "not all pods ready after %v\n", r.TimeToReady)
+	}
+
+	return nil
+}
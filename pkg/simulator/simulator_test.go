@@ -0,0 +1,103 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package simulator
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	configtypes "straggler/pkg/config/types"
+	"straggler/pkg/controller"
+	"straggler/pkg/pacer/linear"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSimulatorRun(t *testing.T) {
+	logger := testr.New(t)
+
+	classifier := controller.NewPodClassifier(nil)
+	err := classifier.AddConfig(configtypes.StaggerGroup{
+		Name:               "test",
+		LabelSelector:      &metav1.LabelSelector{MatchLabels: map[string]string{"stagger": "1"}},
+		GroupingExpression: ".metadata.namespace",
+		PacerFactory:       linear.NewFactory(linear.Config{MaxStagger: 100, Step: 2}),
+	}, logger)
+	require.NoError(t, err)
+
+	workload, err := LoadWorkloadFromString(`
+groups:
+- name: staggered
+  pods: 6
+  template:
+    metadata:
+      labels:
+        stagger: "1"
+  startup:
+    min: 1s
+- name: unstaggered
+  pods: 3
+  arrival:
+    start: 1s
+  startup:
+    min: 500ms
+`)
+	require.NoError(t, err)
+
+	sim := New(classifier, NewClock(), Options{Step: 500 * time.Millisecond})
+	result, err := sim.Run(workload, logger)
+	require.NoError(t, err)
+	require.True(t, result.Completed)
+	// two pods every second.
+	assert.Equal(t, 3*time.Second, result.TimeToReady)
+	assert.Equal(t, 3*time.Second, result.TimeToReadyByGroup["staggered"])
+	assert.Equal(t, 1500*time.Millisecond, result.TimeToReadyByGroup["unstaggered"])
+
+	require.NotEmpty(t, result.Timeline)
+	first := result.Timeline[0]
+	assert.Equal(t, Sample{Group: "staggered", Created: 6, Admitted: 2, Blocked: 4, Starting: 2}, first)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, result.Print(out))
+	assert.Contains(t, out.String(), "all pods ready after 3s")
+}
+
+func TestSimulatorMaxDuration(t *testing.T) {
+	logger := testr.New(t)
+
+	classifier := controller.NewPodClassifier(nil)
+	err := classifier.AddConfig(configtypes.StaggerGroup{
+		Name:               "test",
+		GroupingExpression: ".metadata.namespace",
+		PacerFactory:       linear.NewFactory(linear.Config{MaxStagger: 100, Step: 1}),
+	}, logger)
+	require.NoError(t, err)
+
+	workload := Workload{
+		Groups: []WorkloadGroup{
+			{
+				Name: "slow",
+				Pods: 10,
+				Startup: Startup{
+					Distribution: DistributionUniform,
+					Min:          metav1.Duration{Duration: time.Second},
+					Max:          metav1.Duration{Duration: 2 * time.Second},
+				},
+			},
+		},
+	}
+	sim := New(classifier, NewClock(), Options{Step: time.Second, MaxDuration: 5 * time.Second})
+	result, err := sim.Run(workload, logger)
+	require.NoError(t, err)
+	assert.False(t, result.Completed)
+	assert.Equal(t, 5*time.Second, result.TimeToReady)
+	assert.NotContains(t, result.TimeToReadyByGroup, "slow")
+
+	workload.Groups[0].Startup.Distribution = "unknown"
+	_, err = New(classifier, NewClock(), Options{}).Run(workload, logger)
+	assert.Error(t, err)
+}
@@ -0,0 +1,85 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package simulator
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// Pods take exactly min to start.
+	DistributionConstant = "constant"
+	// Pods take a uniformly distributed duration between min and max to start.
+	DistributionUniform = "uniform"
+	// Pods take a normally distributed duration with mean and stdDev to start,
+	// bounded by min and max if set.
+	DistributionNormal = "normal"
+)
+
+// Synthetic workload to simulate.
+type Workload struct {
+	// Groups of pods, each group is similar to pods of a single controller.
+	Groups []WorkloadGroup `json:"groups"`
+}
+
+type WorkloadGroup struct {
+	// Name of the group, used for reporting.
+	Name string `json:"name"`
+	// Template of pods in this group. Labels and spec are used for
+	// classification against policies. Enable label is always added.
+	Template corev1.PodTemplateSpec `json:"template"`
+	// Namespace of pods. Default "default".
+	Namespace string `json:"namespace,omitempty"`
+	// Number of pods to create.
+	Pods int `json:"pods"`
+	// When pods are created.
+	Arrival Arrival `json:"arrival,omitempty"`
+	// How long it takes pods to become ready once started.
+	Startup Startup `json:"startup,omitempty"`
+}
+
+type Arrival struct {
+	// Time of first pod creation since simulation start.
+	Start metav1.Duration `json:"start,omitempty"`
+	// Time between consecutive pod creations. Zero creates all pods at once.
+	Interval metav1.Duration `json:"interval,omitempty"`
+}
+
+type Startup struct {
+	// One of constant, uniform or normal. Default constant.
+	Distribution string          `json:"distribution,omitempty"`
+	Min          metav1.Duration `json:"min,omitempty"`
+	Max          metav1.Duration `json:"max,omitempty"`
+	Mean         metav1.Duration `json:"mean,omitempty"`
+	StdDev       metav1.Duration `json:"stdDev,omitempty"`
+}
+
+// State of a workload group at a point in time.
+type Sample struct {
+	// Time since simulation start.
+	Time time.Duration
+	// Workload group name.
+	Group string
+	// Pods created so far.
+	Created int
+	// Pods admitted or released since the previous sample.
+	Admitted int
+	Blocked  int
+	Starting int
+	Ready    int
+}
+
+type Result struct {
+	// Samples taken whenever a workload group changed.
+	Timeline []Sample
+	// Time until all pods of each workload group were ready. Missing if a
+	// group did not become fully ready within the simulation duration.
+	TimeToReadyByGroup map[string]time.Duration
+	// Time until all pods were ready.
+	TimeToReady time.Duration
+	// True if all pods became ready within the simulation duration.
+	Completed bool
+}
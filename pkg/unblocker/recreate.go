@@ -0,0 +1,158 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package unblocker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	blockertypes "straggler/pkg/blocker/types"
+	"straggler/pkg/unblocker/types"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Remove pods without owning controllers then create them again.
+	Recreate = "recreate"
+)
+
+var (
+	// Annotation set on recreated pods to the UID of the pod they replace.
+	// Admission lets pods with it through without staggering them.
+	DefaultRecreatedAnnotation = "v1.straggler.technicianted/recreatedFrom"
+	// Label of config maps that keep manifests of pods to be recreated, set
+	// to the UID of the pod they replace.
+	DefaultRecreatePodLabel = "v1.straggler.technicianted/recreatePod"
+	// Prefix of names of config maps that keep manifests of pods to be
+	// recreated.
+	DefaultRecreateConfigMapPrefix = "straggler-recreate-"
+)
+
+// Key of the pod manifest in config maps of pods to be recreated.
+const recreatePodKey = "pod"
+
+var _ types.PodUnblocker = &recreate{}
+
+type recreate struct {
+	client  client.Client
+	blocker blockertypes.PodBlocker
+	remover types.PodUnblocker
+}
+
+// Create an unblocker for pods that have no owning controller to recreate
+// them. The manifest of the pod, with its blocking removed by blocker, is
+// kept in a config map before the pod is removed using remover. Pods are
+// then created again from their config maps once removed, see
+// GetRecreatedPod.
+func NewRecreate(client client.Client, blocker blockertypes.PodBlocker, remover types.PodUnblocker) types.PodUnblocker {
+	return &recreate{
+		client:  client,
+		blocker: blocker,
+		remover: remover,
+	}
+}
+
+func (u *recreate) Unblock(ctx context.Context, pod *corev1.Pod, logger logr.Logger) error {
+	// capture the pod before it is removed so failing to restore it does
+	// not lose it.
+	recreated := newRecreatedPod(pod)
+	if err := u.blocker.Unblock(recreated, logger); err != nil {
+		return fmt.Errorf("failed to restore pod spec: %v", err)
+	}
+	markUnblocked(recreated, u.Name())
+	recreated.Annotations[DefaultRecreatedAnnotation] = string(pod.UID)
+
+	configMap, err := newRecreateConfigMap(pod, recreated)
+	if err != nil {
+		return err
+	}
+	// a previous attempt may have already saved it.
+	if err := u.client.Create(ctx, configMap); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to save pod manifest: %v", err)
+	}
+
+	logger.V(1).Info("removing pod to be created again", "pod", pod.Name, "namespace", pod.Namespace)
+	if err := u.remover.Unblock(ctx, pod, logger); err != nil {
+		// pod was not removed so there is nothing to recreate.
+		if err := u.client.Delete(ctx, configMap); client.IgnoreNotFound(err) != nil {
+			logger.Info("failed to delete pod manifest", "configMap", configMap.Name, "error", err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (u *recreate) Name() string {
+	return Recreate
+}
+
+// Get the pod to create again from a config map created by a recreate
+// unblocker.
+func GetRecreatedPod(configMap *corev1.ConfigMap) (*corev1.Pod, error) {
+	raw, ok := configMap.Data[recreatePodKey]
+	if !ok {
+		return nil, fmt.Errorf("config map has no pod manifest")
+	}
+	pod := &corev1.Pod{}
+	if err := json.Unmarshal([]byte(raw), pod); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pod manifest: %v", err)
+	}
+
+	return pod, nil
+}
+
+// Get a config map that keeps the manifest of recreated to replace pod.
+func newRecreateConfigMap(pod *corev1.Pod, recreated *corev1.Pod) (*corev1.ConfigMap, error) {
+	raw, err := json.Marshal(recreated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal pod manifest: %v", err)
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DefaultRecreateConfigMapPrefix + string(pod.UID),
+			Namespace: pod.Namespace,
+			Labels: map[string]string{
+				DefaultRecreatePodLabel: string(pod.UID),
+			},
+		},
+		Data: map[string]string{
+			recreatePodKey: string(raw),
+		},
+	}, nil
+}
+
+// Get a copy of pod that can be created again.
+func newRecreatedPod(pod *corev1.Pod) *corev1.Pod {
+	recreated := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Pod",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            pod.Name,
+			Namespace:       pod.Namespace,
+			Labels:          make(map[string]string),
+			Annotations:     make(map[string]string),
+			OwnerReferences: pod.OwnerReferences,
+		},
+		Spec: *pod.Spec.DeepCopy(),
+	}
+	for key, value := range pod.Labels {
+		recreated.Labels[key] = value
+	}
+	for key, value := range pod.Annotations {
+		recreated.Annotations[key] = value
+	}
+	// stub pods may already be scheduled.
+	recreated.Spec.NodeName = ""
+
+	return recreated
+}
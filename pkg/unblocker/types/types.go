@@ -0,0 +1,21 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package types
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+)
+
+//go:generate mockgen -package mocks -destination ../mocks/unblockers.go -source $GOFILE
+
+// PodUnblocker is an interface to define a strategy for releasing a blocked
+// pod once its pacer allows it.
+type PodUnblocker interface {
+	// Unblock releases a blocked pod such that it can start.
+	Unblock(ctx context.Context, pod *corev1.Pod, logger logr.Logger) error
+	// Name returns the name of the unblocking strategy.
+	Name() string
+}
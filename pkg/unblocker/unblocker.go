@@ -0,0 +1,194 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package unblocker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"straggler/pkg/blocker"
+	blockertypes "straggler/pkg/blocker/types"
+	"straggler/pkg/unblocker/types"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Evict pods to be recreated by their controllers.
+	Evict = "evict"
+	// Delete pods to be recreated by their controllers.
+	Delete = "delete"
+	// Remove blocking from pods in place using a patch.
+	PatchRemoveGate = "patch-remove-gate"
+	// Annotate pods as released such that other systems can act on it.
+	PatchAnnotation = "patch-annotation"
+)
+
+var (
+	DefaultReleasedAnnotation    = "v1.straggler.technicianted/released"
+	DefaultUnblockedByAnnotation = "v1.straggler.technicianted/unblockedBy"
+)
+
+var (
+	_ types.PodUnblocker = &evict{}
+	_ types.PodUnblocker = &deletePod{}
+	_ types.PodUnblocker = &patchRemoveGate{}
+	_ types.PodUnblocker = &patchAnnotation{}
+)
+
+type evict struct {
+	client client.Client
+}
+
+// Create an unblocker that evicts pods with zero grace period. Evicted
+// pods are expected to be recreated by their owning controllers.
+func NewEvict(client client.Client) types.PodUnblocker {
+	return &evict{
+		client: client,
+	}
+}
+
+func (u *evict) Unblock(ctx context.Context, pod *corev1.Pod, logger logr.Logger) error {
+	eviction := &policyv1.Eviction{
+		DeleteOptions: &metav1.DeleteOptions{GracePeriodSeconds: ptr.To(int64(0))},
+	}
+
+	return u.client.SubResource("eviction").Create(ctx, pod, eviction, &client.SubResourceCreateOptions{})
+}
+
+func (u *evict) Name() string {
+	return Evict
+}
+
+type deletePod struct {
+	client client.Client
+}
+
+// Create an unblocker that deletes pods with zero grace period. Unlike
+// eviction, deletion does not respect PodDisruptionBudgets.
+func NewDelete(client client.Client) types.PodUnblocker {
+	return &deletePod{
+		client: client,
+	}
+}
+
+func (u *deletePod) Unblock(ctx context.Context, pod *corev1.Pod, logger logr.Logger) error {
+	return u.client.Delete(ctx, pod, client.GracePeriodSeconds(0))
+}
+
+func (u *deletePod) Name() string {
+	return Delete
+}
+
+type patchRemoveGate struct {
+	client   client.Client
+	blocker  blockertypes.PodBlocker
+	fallback types.PodUnblocker
+}
+
+// Create an unblocker that removes blocking from pods in place using
+// blocker then patches them. Pods that blocker cannot unblock in place,
+// such as ones that need immutable fields changed, are released using
+// fallback instead.
+func NewPatchRemoveGate(client client.Client, blocker blockertypes.PodBlocker, fallback types.PodUnblocker) types.PodUnblocker {
+	return &patchRemoveGate{
+		client:   client,
+		blocker:  blocker,
+		fallback: fallback,
+	}
+}
+
+func (u *patchRemoveGate) Unblock(ctx context.Context, pod *corev1.Pod, logger logr.Logger) error {
+	unblockedPod := pod.DeepCopy()
+	err := u.blocker.Unblock(unblockedPod, logger)
+	if err == nil && !isPodSpecUpdatable(&pod.Spec, &unblockedPod.Spec) {
+		err = fmt.Errorf("pod spec cannot be updated in place")
+	}
+	if err != nil {
+		logger.V(1).Info("falling back to unblock pod", "pod", pod.Name, "namespace", pod.Namespace, "unblocker", u.fallback.Name(), "reason", err)
+		return u.fallback.Unblock(ctx, pod, logger)
+	}
+	markUnblocked(unblockedPod, u.Name())
+
+	return u.client.Patch(ctx, unblockedPod, client.MergeFrom(pod))
+}
+
+func (u *patchRemoveGate) Name() string {
+	return PatchRemoveGate
+}
+
+type patchAnnotation struct {
+	client client.Client
+}
+
+// Create an unblocker that annotates pods as released without changing
+// their specs. It is meant for blocking that is handled by other systems
+// that act on the annotation.
+func NewPatchAnnotation(client client.Client) types.PodUnblocker {
+	return &patchAnnotation{
+		client: client,
+	}
+}
+
+func (u *patchAnnotation) Unblock(ctx context.Context, pod *corev1.Pod, logger logr.Logger) error {
+	unblockedPod := pod.DeepCopy()
+	if unblockedPod.Annotations == nil {
+		unblockedPod.Annotations = make(map[string]string)
+	}
+	unblockedPod.Annotations[DefaultReleasedAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	markUnblocked(unblockedPod, u.Name())
+
+	return u.client.Patch(ctx, unblockedPod, client.MergeFrom(pod))
+}
+
+func (u *patchAnnotation) Name() string {
+	return PatchAnnotation
+}
+
+// Check if name is a known unblocking strategy.
+func IsKnown(name string) bool {
+	switch name {
+	case Evict, Delete, PatchRemoveGate, PatchAnnotation:
+		return true
+	default:
+		return false
+	}
+}
+
+// Check if a pod has been released by annotation.
+func IsReleased(pod *corev1.Pod) bool {
+	_, ok := pod.Annotations[DefaultReleasedAnnotation]
+	return ok
+}
+
+// Check if podSpec can be updated to newPodSpec. Only removal of scheduling
+// gates and changes of container images are allowed by pod updates.
+func isPodSpecUpdatable(podSpec *corev1.PodSpec, newPodSpec *corev1.PodSpec) bool {
+	specs := []*corev1.PodSpec{podSpec.DeepCopy(), newPodSpec.DeepCopy()}
+	for _, spec := range specs {
+		spec.SchedulingGates = nil
+		for i := range spec.InitContainers {
+			spec.InitContainers[i].Image = ""
+		}
+		for i := range spec.Containers {
+			spec.Containers[i].Image = ""
+		}
+	}
+
+	return equality.Semantic.DeepEqual(specs[0], specs[1])
+}
+
+func markUnblocked(pod *corev1.Pod, strategy string) {
+	delete(pod.Labels, blocker.DefaultStaggeredPodLabel)
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[DefaultUnblockedByAnnotation] = strategy
+}
@@ -0,0 +1,181 @@
+// Copyright (c) straggler team and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+package unblocker
+
+import (
+	"context"
+	"testing"
+
+	"straggler/pkg/blocker"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "pod",
+			Labels: map[string]string{
+				blocker.DefaultStaggeredPodLabel: "1",
+			},
+		},
+		Spec: corev1.PodSpec{
+			SchedulingGates: []corev1.PodSchedulingGate{
+				{Name: blocker.DefaultSchedulingGateName},
+			},
+		},
+	}
+}
+
+func TestEvict(t *testing.T) {
+	logger := testr.New(t)
+	pod := newTestPod()
+	cl := fake.NewClientBuilder().WithObjects(pod).Build()
+
+	unblocker := NewEvict(cl)
+	assert.Equal(t, Evict, unblocker.Name())
+	err := unblocker.Unblock(context.TODO(), pod, logger)
+	require.NoError(t, err)
+
+	err = cl.Get(context.TODO(), client.ObjectKeyFromObject(pod), &corev1.Pod{})
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+func TestDelete(t *testing.T) {
+	logger := testr.New(t)
+	pod := newTestPod()
+	cl := fake.NewClientBuilder().WithObjects(pod).Build()
+
+	unblocker := NewDelete(cl)
+	assert.Equal(t, Delete, unblocker.Name())
+	err := unblocker.Unblock(context.TODO(), pod, logger)
+	require.NoError(t, err)
+
+	err = cl.Get(context.TODO(), client.ObjectKeyFromObject(pod), &corev1.Pod{})
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+func TestPatchRemoveGate(t *testing.T) {
+	logger := testr.New(t)
+	pod := newTestPod()
+	cl := fake.NewClientBuilder().WithObjects(pod).Build()
+
+	unblocker := NewPatchRemoveGate(cl, blocker.NewSchedulingGatesPodBlocker(), NewDelete(cl))
+	assert.Equal(t, PatchRemoveGate, unblocker.Name())
+	err := unblocker.Unblock(context.TODO(), pod, logger)
+	require.NoError(t, err)
+
+	patched := &corev1.Pod{}
+	err = cl.Get(context.TODO(), client.ObjectKeyFromObject(pod), patched)
+	require.NoError(t, err)
+	assert.Empty(t, patched.Spec.SchedulingGates)
+	assert.NotContains(t, patched.Labels, blocker.DefaultStaggeredPodLabel)
+	assert.Equal(t, PatchRemoveGate, patched.Annotations[DefaultUnblockedByAnnotation])
+
+	// stub pods cannot be unblocked in place so they are released by
+	// the fallback unblocker.
+	stubPod := blocker.NewStubPod("image", nil)
+	pod = newTestPod()
+	pod.Name = "stubpod"
+	pod.Spec = corev1.PodSpec{Containers: []corev1.Container{{Name: "container", Image: "original"}}}
+	require.NoError(t, stubPod.Block(pod, logger))
+	require.NoError(t, cl.Create(context.TODO(), pod))
+	unblocker = NewPatchRemoveGate(cl, stubPod, NewDelete(cl))
+	err = unblocker.Unblock(context.TODO(), pod, logger)
+	require.NoError(t, err)
+	err = cl.Get(context.TODO(), client.ObjectKeyFromObject(pod), &corev1.Pod{})
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+func TestPatchAnnotation(t *testing.T) {
+	logger := testr.New(t)
+	pod := newTestPod()
+	cl := fake.NewClientBuilder().WithObjects(pod).Build()
+
+	unblocker := NewPatchAnnotation(cl)
+	assert.Equal(t, PatchAnnotation, unblocker.Name())
+	assert.False(t, IsReleased(pod))
+	err := unblocker.Unblock(context.TODO(), pod, logger)
+	require.NoError(t, err)
+
+	patched := &corev1.Pod{}
+	err = cl.Get(context.TODO(), client.ObjectKeyFromObject(pod), patched)
+	require.NoError(t, err)
+	// spec is left untouched
+	assert.Len(t, patched.Spec.SchedulingGates, 1)
+	assert.NotContains(t, patched.Labels, blocker.DefaultStaggeredPodLabel)
+	assert.Equal(t, PatchAnnotation, patched.Annotations[DefaultUnblockedByAnnotation])
+	assert.True(t, IsReleased(patched))
+}
+
+func TestRecreate(t *testing.T) {
+	logger := testr.New(t)
+	stubPod := blocker.NewStubPod("image", nil)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "pod",
+			UID:       "uid",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "container", Image: "original"}},
+		},
+	}
+	err := stubPod.Block(pod, logger)
+	require.NoError(t, err)
+	pod.Labels = map[string]string{blocker.DefaultStaggeredPodLabel: "1"}
+	pod.Spec.NodeName = "node"
+	cl := fake.NewClientBuilder().WithObjects(pod).Build()
+
+	unblocker := NewRecreate(cl, stubPod, NewDelete(cl))
+	assert.Equal(t, Recreate, unblocker.Name())
+	err = unblocker.Unblock(context.TODO(), pod, logger)
+	require.NoError(t, err)
+
+	// pod is removed and its manifest kept until it is created again
+	err = cl.Get(context.TODO(), client.ObjectKeyFromObject(pod), &corev1.Pod{})
+	assert.True(t, k8serrors.IsNotFound(err))
+	configMap := &corev1.ConfigMap{}
+	err = cl.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: DefaultRecreateConfigMapPrefix + "uid"}, configMap)
+	require.NoError(t, err)
+	assert.Equal(t, "uid", configMap.Labels[DefaultRecreatePodLabel])
+	recreated, err := GetRecreatedPod(configMap)
+	require.NoError(t, err)
+	assert.Equal(t, "pod", recreated.Name)
+	assert.False(t, stubPod.IsBlocked(&recreated.Spec))
+	assert.Equal(t, []corev1.Container{{Name: "container", Image: "original"}}, recreated.Spec.Containers)
+	assert.Empty(t, recreated.Spec.NodeName)
+	assert.NotContains(t, recreated.Labels, blocker.DefaultStaggeredPodLabel)
+	assert.Equal(t, "uid", recreated.Annotations[DefaultRecreatedAnnotation])
+	assert.Equal(t, Recreate, recreated.Annotations[DefaultUnblockedByAnnotation])
+
+	// pods that cannot be restored are not removed
+	pod = newTestPod()
+	pod.Name = "notrestored"
+	pod.Spec = corev1.PodSpec{InitContainers: []corev1.Container{{Name: "stagger", Image: "image"}}}
+	err = cl.Create(context.TODO(), pod)
+	require.NoError(t, err)
+	err = unblocker.Unblock(context.TODO(), pod, logger)
+	require.Error(t, err)
+	err = cl.Get(context.TODO(), client.ObjectKeyFromObject(pod), &corev1.Pod{})
+	require.NoError(t, err)
+
+	// manifests of pods that fail to be removed are not kept
+	pod = newTestPod()
+	pod.Name = "notremoved"
+	pod.UID = "notremoved"
+	err = unblocker.Unblock(context.TODO(), pod, logger)
+	require.Error(t, err)
+	configMaps := &corev1.ConfigMapList{}
+	err = cl.List(context.TODO(), configMaps)
+	require.NoError(t, err)
+	require.Len(t, configMaps.Items, 1)
+}
@@ -205,7 +205,7 @@ func getPodCounts(ctx context.Context, c client.Client, namespace string, labelS
 		client.MatchingLabels(labelSelector),
 	}
 
-	blocker, err := cmd.NewBlocker(cmd.NewOptions())
+	blocker, err := cmd.NewBlocker(cmd.NewOptions(), c)
 	if err != nil {
 		err = fmt.Errorf("failed to create blocker: %v", err)
 		return